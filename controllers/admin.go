@@ -1,13 +1,19 @@
 package controllers
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/ystv/stv-web/mail"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/ystv/stv-web/storage"
 	"github.com/ystv/stv-web/store"
@@ -132,9 +138,21 @@ func (r *AdminRepo) AddCandidate(c echo.Context) error {
 		}
 	}
 
+	form, err := c.FormParams()
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	categories := form["category"]
+	for _, category := range categories {
+		if !slices.ContainsFunc(election.GetCategories(), func(c1 *storage.Category) bool { return c1.GetName() == category }) {
+			return r.errorHandle(c, fmt.Errorf("unknown category: %s", category))
+		}
+	}
+
 	candidate := &storage.Candidate{
-		Election: id,
-		Name:     name,
+		Election:   id,
+		Name:       name,
+		Categories: categories,
 	}
 
 	_, err = r.store.AddCandidate(candidate)
@@ -167,6 +185,115 @@ func (r *AdminRepo) DeleteCandidate(c echo.Context) error {
 	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", election.GetId()))
 }
 
+// AddCategory adds a category to an election that hasn't opened, with the
+// fewest and most seats its candidates can fill and the candidates in it
+func (r *AdminRepo) AddCategory(c echo.Context) error {
+	id := c.Param("id")
+	if len(id) == 0 {
+		return r.errorHandle(c, fmt.Errorf("invalid election id"))
+	}
+
+	election, err := r.store.FindElection(id)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if election.GetOpen() || election.GetClosed() {
+		return r.errorHandle(c, fmt.Errorf("cannot add category to open or closed election"))
+	}
+	method, err := parseCountMethod(election.GetMethod())
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if election.GetBallotType() == voting.BallotTypeMotion || method != voting.CountMethodSingleTransferableVote {
+		return r.errorHandle(c, fmt.Errorf("categories can only be counted by %s", voting.CountMethodSingleTransferableVote))
+	}
+	if election.GetBatchElimination() {
+		return r.errorHandle(c, fmt.Errorf("categories cannot be used with batch elimination"))
+	}
+
+	name := c.FormValue("name")
+	if len(name) == 0 {
+		return r.errorHandle(c, fmt.Errorf("name cannot be empty"))
+	}
+	categoryMin, categoryMax, err := parseCategory(c.FormValue("min"), c.FormValue("max"), election.GetSeats())
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	form, err := c.FormParams()
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+
+	err = r.store.AddCategory(id, &storage.Category{
+		Name: name,
+		Min:  categoryMin,
+		Max:  categoryMax,
+	}, form["candidate"])
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", id))
+}
+
+// DeleteCategory removes a category from an election that hasn't opened
+func (r *AdminRepo) DeleteCategory(c echo.Context) error {
+	id := c.Param("id")
+	if len(id) == 0 {
+		return r.errorHandle(c, fmt.Errorf("invalid election id"))
+	}
+	err := r.store.DeleteCategory(id, c.FormValue("name"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", id))
+}
+
+// WithdrawCandidate withdraws a candidate from an open election, ballots already
+// cast keep them but the count skips over them to the next preference
+func (r *AdminRepo) WithdrawCandidate(c echo.Context) error {
+	id := c.Param("id")
+	if len(id) == 0 {
+		return r.errorHandle(c, fmt.Errorf("invalid candidate id"))
+	}
+	candidate, err := r.store.FindCandidate(id)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	election, err := r.store.FindElection(candidate.GetElection())
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if !election.GetOpen() || election.GetClosed() {
+		return r.errorHandle(c, fmt.Errorf("can only withdraw candidate of open election, delete them before it opens"))
+	}
+	if election.GetBallotType() == voting.BallotTypeMotion {
+		return r.errorHandle(c, fmt.Errorf("cannot withdraw candidate of motion"))
+	}
+
+	candidates, err := r.store.GetCandidatesElectionID(election.GetId())
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	standing := 0
+	for _, c1 := range candidates {
+		if !c1.GetWithdrawn() {
+			standing++
+		}
+	}
+	if election.GetRon() {
+		standing++
+	}
+	if uint64(standing-1) < election.GetSeats() {
+		return r.errorHandle(c, fmt.Errorf("cannot withdraw candidates leaving fewer candidates than seats"))
+	}
+
+	_, err = r.store.WithdrawCandidate(id)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", election.GetId()))
+}
+
 func (r *AdminRepo) Elections(c echo.Context) error {
 	stv, err := r.store.Get()
 	if err != nil {
@@ -210,6 +337,8 @@ func (r *AdminRepo) Election(c echo.Context) error {
 		return r.errorHandle(c, err)
 	}
 	if election.GetResult() != nil {
+		// winners are shown by name, the stored election keeps the candidate ids
+		election = proto.Clone(election).(*storage.Election)
 		if len(election.GetResult().GetWinners()) > 0 {
 			var winningCandidates []string
 			for _, wc := range election.GetResult().GetWinners() {
@@ -228,7 +357,7 @@ func (r *AdminRepo) Election(c echo.Context) error {
 			election.GetResult().Winners = winningCandidates
 		}
 	}
-	var noOfBallots uint64
+	var noOfBallots, abstained uint64
 	if election.GetOpen() || election.GetClosed() {
 		var ballots []*storage.Ballot
 		ballots, err = r.store.GetBallotsElectionID(election.GetId())
@@ -236,23 +365,65 @@ func (r *AdminRepo) Election(c echo.Context) error {
 			return r.errorHandle(c, err)
 		}
 		noOfBallots = uint64(len(ballots))
+		abstained = countAbstained(ballots)
 	}
 	voters, err := r.store.GetVoters()
 	if err != nil {
 		return r.errorHandle(c, err)
 	}
+	// excluded voters are kept by email, a voter deleted since is shown by email alone
+	excluded := make([]*storage.Voter, 0, len(election.GetExcludedVoters()))
+	for _, email := range election.GetExcludedVoters() {
+		voter := &storage.Voter{Email: email}
+		for _, v := range voters {
+			if v.GetEmail() == email {
+				voter = v
+				break
+			}
+		}
+		excluded = append(excluded, voter)
+	}
+	candidateNames := map[string]string{"R.O.N.": "R.O.N."}
+	for _, candidate := range candidates {
+		candidateNames[candidate.GetId()] = candidate.GetName()
+	}
+	var vacancies []string
+	var standing []*storage.Candidate
+	if method := election.GetResult().GetMethod(); election.GetClosed() && (method == "" || method == voting.CountMethodSingleTransferableVote) &&
+		election.GetBallotType() != voting.BallotTypeMotion {
+		// the stored election still has the winners by id
+		var stored *storage.Election
+		stored, err = r.store.FindElection(id)
+		if err != nil {
+			return r.errorHandle(c, err)
+		}
+		vacancies, standing, err = r.countbackCandidates(stored)
+		if err != nil {
+			return r.errorHandle(c, err)
+		}
+	}
 	data := struct {
-		Election   *storage.Election
-		Candidates []*storage.Candidate
-		Ballots    uint64
-		Error      string
-		VotersList []*storage.Voter
+		Election       *storage.Election
+		Candidates     []*storage.Candidate
+		CandidateNames map[string]string
+		Ballots        uint64
+		Abstained      uint64
+		Error          string
+		VotersList     []*storage.Voter
+		Excluded       []*storage.Voter
+		Vacancies      []string
+		Standing       []*storage.Candidate
 	}{
-		Election:   election,
-		Candidates: candidates,
-		Ballots:    noOfBallots,
-		Error:      err1,
-		VotersList: voters,
+		Election:       election,
+		Candidates:     candidates,
+		CandidateNames: candidateNames,
+		Ballots:        noOfBallots,
+		Abstained:      abstained,
+		Error:          err1,
+		VotersList:     voters,
+		Excluded:       excluded,
+		Vacancies:      vacancies,
+		Standing:       standing,
 	}
 	err = r.controller.Template.RenderTemplate(c.Response().Writer, data, templates.ElectionTemplate)
 	if err != nil {
@@ -281,11 +452,58 @@ func (r *AdminRepo) AddElection(c echo.Context) error {
 	if len(name) == 0 {
 		return r.errorHandle(c, fmt.Errorf("name and description need to be filled"))
 	}
+	ballotType, err := parseBallotType(c.FormValue("ballotType"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	method, err := ballotTypeCountMethod(ballotType, c.FormValue("method"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	var threshold string
+	var quorum uint64
+	if ballotType == voting.BallotTypeMotion {
+		// a motion decides For, Against or Abstain, so there is one outcome and no R.O.N.
+		seats = 1
+		ron = false
+		threshold, quorum, err = parseMotion(c.FormValue("threshold"), c.FormValue("quorum"))
+		if err != nil {
+			return r.errorHandle(c, err)
+		}
+	}
+	if err = validateCountMethodSeats(method, seats); err != nil {
+		return r.errorHandle(c, err)
+	}
+	arithmetic, decimalPlaces, err := parsePrecision(method, c.FormValue("arithmetic"), c.FormValue("decimalPlaces"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	quota, err := parseQuota(c.FormValue("quota"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	surplusRule, err := parseSurplusRule(c.FormValue("surplusRule"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	batchElimination, err := parseBatchElimination(method, c.FormValue("batchElimination"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
 	election := &storage.Election{
-		Name:        name,
-		Description: description,
-		Ron:         ron,
-		Seats:       seats,
+		Name:             name,
+		Description:      description,
+		Ron:              ron,
+		Seats:            seats,
+		Method:           method,
+		Arithmetic:       arithmetic,
+		DecimalPlaces:    decimalPlaces,
+		Quota:            quota,
+		SurplusRule:      surplusRule,
+		BatchElimination: batchElimination,
+		BallotType:       ballotType,
+		Threshold:        threshold,
+		Quorum:           quorum,
 	}
 
 	e1, err := r.store.AddElection(election)
@@ -320,12 +538,69 @@ func (r *AdminRepo) EditElection(c echo.Context) error {
 	if len(name) == 0 {
 		return r.errorHandle(c, fmt.Errorf("name and description need to be filled"))
 	}
+	ballotType, err := parseBallotType(c.FormValue("ballotType"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	method, err := ballotTypeCountMethod(ballotType, c.FormValue("method"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	var threshold string
+	var quorum uint64
+	if ballotType == voting.BallotTypeMotion {
+		// a motion decides For, Against or Abstain, so there is one outcome and no R.O.N.
+		seats = 1
+		ron = false
+		threshold, quorum, err = parseMotion(c.FormValue("threshold"), c.FormValue("quorum"))
+		if err != nil {
+			return r.errorHandle(c, err)
+		}
+	}
+	if err = validateCountMethodSeats(method, seats); err != nil {
+		return r.errorHandle(c, err)
+	}
+	stored, err := r.store.FindElection(id)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if len(stored.GetCategories()) > 0 && method != voting.CountMethodSingleTransferableVote {
+		return r.errorHandle(c, fmt.Errorf("categories can only be counted by %s, delete them first", voting.CountMethodSingleTransferableVote))
+	}
+	if len(stored.GetCategories()) > 0 && len(c.FormValue("batchElimination")) > 0 {
+		return r.errorHandle(c, fmt.Errorf("batch elimination cannot be used with categories, delete them first"))
+	}
+	arithmetic, decimalPlaces, err := parsePrecision(method, c.FormValue("arithmetic"), c.FormValue("decimalPlaces"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	quota, err := parseQuota(c.FormValue("quota"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	surplusRule, err := parseSurplusRule(c.FormValue("surplusRule"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	batchElimination, err := parseBatchElimination(method, c.FormValue("batchElimination"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
 	election := &storage.Election{
-		Id:          id,
-		Name:        name,
-		Description: description,
-		Ron:         ron,
-		Seats:       seats,
+		Id:               id,
+		Name:             name,
+		Description:      description,
+		Ron:              ron,
+		Seats:            seats,
+		Method:           method,
+		Arithmetic:       arithmetic,
+		DecimalPlaces:    decimalPlaces,
+		Quota:            quota,
+		SurplusRule:      surplusRule,
+		BatchElimination: batchElimination,
+		BallotType:       ballotType,
+		Threshold:        threshold,
+		Quorum:           quorum,
 	}
 
 	e1, err := r.store.EditElection(election)
@@ -336,6 +611,126 @@ func (r *AdminRepo) EditElection(c echo.Context) error {
 	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", election.GetId()))
 }
 
+// ImportBLT creates an election from an uploaded BLT file, the election is
+// opened with the ballots from the file so it can be closed and counted
+// without emailing any voters
+func (r *AdminRepo) ImportBLT(c echo.Context) error {
+	file, err := c.FormFile("blt")
+	if err != nil {
+		return r.errorHandle(c, fmt.Errorf("failed to get blt file: %w", err))
+	}
+	src, err := file.Open()
+	if err != nil {
+		return r.errorHandle(c, fmt.Errorf("failed to open blt file: %w", err))
+	}
+	defer src.Close()
+
+	blt, err := voting.ParseBLT(src)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if blt.NumberOfSeats < 1 {
+		return r.errorHandle(c, fmt.Errorf("blt must have at least 1 seat"))
+	}
+	if uint64(len(blt.Candidates)) < blt.NumberOfSeats {
+		return r.errorHandle(c, fmt.Errorf("blt has fewer candidates than seats"))
+	}
+
+	method, err := ballotTypeCountMethod(voting.BallotTypeRanked, c.FormValue("method"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if err = validateCountMethodSeats(method, blt.NumberOfSeats); err != nil {
+		return r.errorHandle(c, err)
+	}
+	arithmetic, decimalPlaces, err := parsePrecision(method, c.FormValue("arithmetic"), c.FormValue("decimalPlaces"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	quota, err := parseQuota(c.FormValue("quota"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	surplusRule, err := parseSurplusRule(c.FormValue("surplusRule"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	batchElimination, err := parseBatchElimination(method, c.FormValue("batchElimination"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+
+	name := blt.Title
+	if len(name) == 0 {
+		name = file.Filename
+	}
+	election := &storage.Election{
+		Name:             name,
+		Description:      fmt.Sprintf("Imported from %s", file.Filename),
+		Seats:            blt.NumberOfSeats,
+		Method:           method,
+		Arithmetic:       arithmetic,
+		DecimalPlaces:    decimalPlaces,
+		Quota:            quota,
+		SurplusRule:      surplusRule,
+		BatchElimination: batchElimination,
+		BallotType:       voting.BallotTypeRanked,
+	}
+
+	var candidates []*storage.Candidate
+	var ballots []*storage.Ballot
+	candidates, ballots, election.Ron = bltImport(blt)
+
+	election, err = r.store.ImportElection(election, candidates, ballots)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+
+	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", election.GetId()))
+}
+
+// ExportBLT downloads the ballots of a closed election as a BLT file
+func (r *AdminRepo) ExportBLT(c echo.Context) error {
+	id := c.Param("id")
+	if len(id) == 0 {
+		return r.errorHandle(c, fmt.Errorf("invalid election id"))
+	}
+
+	election, err := r.store.FindElection(id)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if !election.GetClosed() {
+		return r.errorHandle(c, fmt.Errorf("cannot export ballots of election that is not closed"))
+	}
+	if election.GetBallotType() == voting.BallotTypeMotion {
+		return r.errorHandle(c, fmt.Errorf("cannot export ballots of a motion as blt"))
+	}
+
+	candidatesStore, err := r.store.GetCandidatesElectionID(id)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	ballots, err := r.store.GetBallotsElectionID(id)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+
+	blt, err := bltExport(election, candidatesStore, ballots)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+
+	var buf bytes.Buffer
+	err = voting.WriteBLT(&buf, blt)
+	if err != nil {
+		return r.errorHandle(c, fmt.Errorf("failed to export blt: %w", err))
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", election.GetId()+".blt"))
+	return c.Blob(http.StatusOK, "text/plain; charset=utf-8", buf.Bytes())
+}
+
 func (r *AdminRepo) OpenElection(c echo.Context) error {
 	id := c.Param("id")
 	if len(id) == 0 {
@@ -358,13 +753,24 @@ func (r *AdminRepo) OpenElection(c echo.Context) error {
 		return r.errorHandle(c, err)
 	}
 
-	if len(candidates) == 0 {
+	if len(candidates) == 0 && election.GetBallotType() != voting.BallotTypeMotion {
 		return r.errorHandle(c, fmt.Errorf("cannot open election with no candidates"))
 	}
 
-	err = r.store.OpenElection(id)
-	if err != nil {
-		return r.errorHandle(c, err)
+	if len(election.GetCategories()) > 0 {
+		var candidatesVoting []*voting.Candidate
+		candidatesVoting, _, _, err = r.electionBallots(election)
+		if err != nil {
+			return r.errorHandle(c, err)
+		}
+		var categories []*voting.Category
+		categories, err = r.electionCategories(election, candidatesVoting)
+		if err != nil {
+			return r.errorHandle(c, err)
+		}
+		if err = voting.ValidateCategories(categories, candidatesVoting, nil, election.GetSeats()); err != nil {
+			return r.errorHandle(c, err)
+		}
 	}
 
 	voters, err := r.store.GetVoters()
@@ -372,6 +778,17 @@ func (r *AdminRepo) OpenElection(c echo.Context) error {
 		return r.errorHandle(c, fmt.Errorf("failed to get voters: %w", err))
 	}
 
+	votersParsed, err := strconv.ParseUint(strconv.Itoa(len(voters)-len(election.GetExcludedVoters())), 10, 64)
+	if err != nil {
+		return r.errorHandle(c, fmt.Errorf("failed to parse uint voters: %w", err))
+	}
+	election.Voters = votersParsed
+
+	err = r.store.OpenElection(id, votersParsed)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+
 	r.mailer, err = mail.NewMailer(r.mailConfig)
 	if err != nil {
 		log.Printf("failed to reconnect to mail server: %+v", err)
@@ -379,12 +796,6 @@ func (r *AdminRepo) OpenElection(c echo.Context) error {
 		log.Println("Reconnected to mail server")
 	}
 
-	votersParsed, err := strconv.ParseUint(strconv.Itoa(len(voters)-len(election.GetExcluded())), 10, 64)
-	if err != nil {
-		return r.errorHandle(c, fmt.Errorf("failed to parse uint voters: %w", err))
-	}
-	election.Voters = votersParsed
-
 	go r.sendEmailThread(voters, election)
 
 	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", election.GetId()))
@@ -392,14 +803,7 @@ func (r *AdminRepo) OpenElection(c echo.Context) error {
 
 func (r *AdminRepo) sendEmailThread(voters []*storage.Voter, election *storage.Election) {
 	for _, voter := range voters {
-		skip := false
-		for _, v := range election.GetExcluded() {
-			if voter.GetEmail() == v.GetEmail() {
-				skip = true
-			}
-		}
-
-		if !skip {
+		if !slices.Contains(election.GetExcludedVoters(), voter.GetEmail()) {
 			url := &storage.URL{
 				Election: election.GetId(),
 				Voter:    voter.GetEmail(),
@@ -471,100 +875,678 @@ func (r *AdminRepo) CloseElection(c echo.Context) error {
 		return r.errorHandle(c, fmt.Errorf("cannot close election that has no or negative seats: %d", election.Seats))
 	}
 
+	election.SetAsideInvalid = len(c.FormValue("setAsideInvalid")) > 0
+
+	// the ballots are read before the count so the close can check none were
+	// cast while it ran
 	ballots, err := r.store.GetBallotsElectionID(id)
 	if err != nil {
 		return r.errorHandle(c, err)
 	}
+	counted := make([]string, 0, len(ballots))
+	for _, ballot := range ballots {
+		counted = append(counted, ballot.GetId())
+	}
 
-	ron := &voting.Candidate{Name: "R.O.N."}
-
-	candidates := make([]*voting.Candidate, 0)
-	if election.GetRon() {
-		candidates = append(candidates, ron)
+	result, err := r.countElection(election, nil)
+	if err != nil {
+		var invalid *voting.InvalidBallotsError
+		if errors.As(err, &invalid) {
+			return r.errorHandle(c, fmt.Errorf("%w, close the election again setting invalid ballots aside as spoilt to count the rest", err))
+		}
+		return r.errorHandle(c, err)
 	}
 
-	candidatesStore, err := r.store.GetCandidatesElectionID(id)
+	err = r.store.CloseElection(id, result, election.GetSetAsideInvalid(), counted)
 	if err != nil {
 		return r.errorHandle(c, err)
 	}
 
-	for _, c1 := range candidatesStore {
-		candidates = append(candidates, &voting.Candidate{Name: c1.GetId()})
+	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", id))
+}
+
+// countElection counts the stored ballots of the election with the options set
+// on it, withdrawn candidate ids and the candidates withdrawn from the election
+// are excluded before the first round and skipped on every ballot
+func (r *AdminRepo) countElection(election *storage.Election, withdrawn []string) (*storage.Result, error) {
+	candidates, ballotsVoting, spoilt, err := r.countedBallots(election)
+	if err != nil {
+		return nil, err
 	}
 
-	ballotsVoting := make([]*voting.Ballot, 0, len(ballots))
+	ballots, err := r.store.GetBallotsElectionID(election.GetId())
+	if err != nil {
+		return nil, err
+	}
+	abstained := countAbstained(ballots)
+
+	if election.GetBallotType() == voting.BallotTypeMotion {
+		var motion *voting.MotionResult
+		motion, err = voting.CountMotion(ballotsVoting, voting.MotionThreshold(election.GetThreshold()), election.GetQuorum())
+		if err != nil {
+			return nil, fmt.Errorf("motion failed: %w", err)
+		}
+		return &storage.Result{
+			Method:    voting.CountMethodMotion,
+			Motion:    storeMotionResult(motion),
+			Ballots:   uint64(len(ballotsVoting)),
+			Spoilt:    storeSpoilt(spoilt),
+			Abstained: abstained,
+		}, nil
+	}
+
+	method, err := parseCountMethod(election.GetMethod())
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := electionCountOptions(election)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawn, err = r.electionWithdrawn(election, withdrawn)
+	if err != nil {
+		return nil, err
+	}
+	options.Withdrawn, err = withdrawnCandidates(election, candidates, withdrawn)
+	if err != nil {
+		return nil, err
+	}
+	options.Categories, err = r.electionCategories(election, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	electionResults, err := voting.Count(voting.CountMethod(method), candidates, ballotsVoting, election.Seats, options)
+	if err != nil {
+		return nil, fmt.Errorf("election failed: %w", err)
+	}
+
+	result := &storage.Result{
+		Method:           method,
+		Arithmetic:       string(electionResults.Precision.Arithmetic),
+		DecimalPlaces:    uint64(electionResults.Precision.DecimalPlaces),
+		TieBreakSeed:     electionResults.TieBreakSeed,
+		LotOrder:         candidateNames(electionResults.LotOrder),
+		Quota:            string(electionResults.Quota),
+		QuotaValue:       electionResults.Precision.FormatVotes(electionResults.QuotaValue),
+		SurplusRule:      string(electionResults.SurplusRule),
+		BatchElimination: electionResults.BatchElimination,
+	}
+
+	result.Rounds = uint64(len(electionResults.Rounds))
+
+	// only the transferable vote counts break exclusion ties with the compare method
+	if method == voting.CountMethodSingleTransferableVote || method == voting.CountMethodMeek {
+		result.CompareMethod = string(options.CompareMethodIfEquals)
+	}
+
+	if electionResults.Schulze != nil {
+		result.PairwiseCandidates = candidateNames(electionResults.Schulze.Candidates)
+		result.Preferences = storePairwise(electionResults.Schulze.Preferences)
+		result.StrongestPaths = storePairwise(electionResults.Schulze.StrongestPaths)
+	}
+
+	result.Round, err = storeRounds(electionResults)
+	if err != nil {
+		return nil, err
+	}
+	winners := electionResults.GetWinners()
+
+	if uint64(len(winners)) != election.Seats {
+		return nil, fmt.Errorf("invalid abount of winners")
+	}
+
+	result.Winners = candidateNames(winners)
+	result.Withdrawn = withdrawn
+	result.Ballots = uint64(electionResults.NumberOfBallots)
+	result.Spoilt = storeSpoilt(spoilt)
+	result.Abstained = abstained
+	if electionResults.WeightOfBallots.Cmp(big.NewRat(int64(electionResults.NumberOfBallots), 1)) != 0 {
+		result.WeightedBallots = electionResults.Precision.FormatVotes(electionResults.WeightOfBallots)
+	}
+
+	return result, nil
+}
+
+// countedBallots loads the candidates and ballots of the election and sets
+// aside the invalid ballots as spoilt if the election was closed that way,
+// otherwise any invalid ballot is returned in an *voting.InvalidBallotsError
+func (r *AdminRepo) countedBallots(election *storage.Election) ([]*voting.Candidate, []*voting.Ballot, []*voting.InvalidBallot, error) {
+	candidates, ballots, spoilt, err := r.electionBallots(election)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var invalid []*voting.InvalidBallot
+	if election.GetBallotType() == voting.BallotTypeMotion {
+		ballots, invalid = voting.SetAsideInvalidMotionBallots(ballots)
+	} else {
+		ballots, invalid = voting.SetAsideInvalidBallots(candidates, ballots)
+	}
+	spoilt = append(spoilt, invalid...)
+	if len(spoilt) > 0 && !election.GetSetAsideInvalid() {
+		return nil, nil, nil, &voting.InvalidBallotsError{Ballots: spoilt}
+	}
+
+	return candidates, ballots, spoilt, nil
+}
+
+// electionBallots loads the candidates and ballots of the election for the
+// voting package, candidates are named by their stored id or R.O.N. Ballots
+// whose stored weight can't be read are returned as invalid, abstentions are
+// left out and the rest are left for the count to check.
+func (r *AdminRepo) electionBallots(election *storage.Election) ([]*voting.Candidate, []*voting.Ballot, []*voting.InvalidBallot, error) {
+	id := election.GetId()
+	ballots, err := r.store.GetBallotsElectionID(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ron := &voting.Candidate{Name: "R.O.N."}
+
+	candidates := make([]*voting.Candidate, 0)
+	if election.GetRon() {
+		candidates = append(candidates, ron)
+	}
+
+	candidatesStore, err := r.store.GetCandidatesElectionID(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, c1 := range candidatesStore {
+		candidates = append(candidates, &voting.Candidate{Name: c1.GetId()})
+	}
+
+	if election.GetBallotType() == voting.BallotTypeMotion {
+		candidates = voting.MotionCandidates()
+	}
+
+	ballotsVoting := make([]*voting.Ballot, 0, len(ballots))
+	invalid := make([]*voting.InvalidBallot, 0)
 	for _, ballot := range ballots {
+		if ballot.GetAbstain() {
+			continue
+		}
 		var c2 []*voting.Candidate
 		for i := uint64(0); i < uint64(len(ballot.GetChoice())); i++ {
 			choice := ballot.GetChoice()[i]
-			for _, c3 := range candidates {
-				if c3.Name == choice {
-					c2 = append(c2, c3)
+			// unranked positions on a ranked ballot are stored empty
+			if len(choice) == 0 {
+				continue
+			}
+			index := slices.IndexFunc(candidates, func(c3 *voting.Candidate) bool { return c3.Name == choice })
+			if index < 0 {
+				c2 = append(c2, &voting.Candidate{Name: choice})
+				continue
+			}
+			c2 = append(c2, candidates[index])
+		}
+		ballotVoting := &voting.Ballot{ID: ballot.GetId(), RankedCandidates: c2}
+		ballotVoting.Weight, err = parseWeight(ballot.GetWeight())
+		if err != nil {
+			invalid = append(invalid, &voting.InvalidBallot{Ballot: ballotVoting, Reason: err.Error()})
+			continue
+		}
+		ballotsVoting = append(ballotsVoting, ballotVoting)
+	}
+
+	return candidates, ballotsVoting, invalid, nil
+}
+
+// electionCategories builds the categories of the election for the voting
+// package from the categories each stored candidate is in
+func (r *AdminRepo) electionCategories(election *storage.Election, candidates []*voting.Candidate) ([]*voting.Category, error) {
+	if len(election.GetCategories()) == 0 {
+		return nil, nil
+	}
+	candidatesStore, err := r.store.GetCandidatesElectionID(election.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]*voting.Category, 0, len(election.GetCategories()))
+	for _, c1 := range election.GetCategories() {
+		category := &voting.Category{
+			Name: c1.GetName(),
+			Min:  c1.GetMin(),
+			Max:  c1.GetMax(),
+		}
+		for _, c2 := range candidatesStore {
+			if !slices.Contains(c2.GetCategories(), c1.GetName()) {
+				continue
+			}
+			for _, candidate := range candidates {
+				if candidate.Name == c2.GetId() {
+					category.Candidates = append(category.Candidates, candidate)
 				}
 			}
 		}
-		ballotsVoting = append(ballotsVoting, voting.NewBallot(c2))
+		categories = append(categories, category)
 	}
+	return categories, nil
+}
 
-	electionResults, err := voting.SingleTransferableVote(candidates, ballotsVoting, election.Seats, voting.DefaultSingleTransferableVoteOptions())
+// electionWithdrawn adds the candidates withdrawn from the election to the
+// withdrawn candidate ids
+func (r *AdminRepo) electionWithdrawn(election *storage.Election, withdrawn []string) ([]string, error) {
+	candidatesStore, err := r.store.GetCandidatesElectionID(election.GetId())
 	if err != nil {
-		return r.errorHandle(c, fmt.Errorf("election failed: %w", err))
+		return nil, err
 	}
+	withdrawn = slices.Clone(withdrawn)
+	for _, c1 := range candidatesStore {
+		if c1.GetWithdrawn() && !slices.Contains(withdrawn, c1.GetId()) {
+			withdrawn = append(withdrawn, c1.GetId())
+		}
+	}
+	return withdrawn, nil
+}
 
-	result := &storage.Result{}
+// withdrawnCandidates returns the candidates of the count with withdrawn ids
+func withdrawnCandidates(election *storage.Election, candidates []*voting.Candidate, withdrawn []string) ([]*voting.Candidate, error) {
+	candidatesWithdrawn := make([]*voting.Candidate, 0, len(withdrawn))
+	for _, candidate := range candidates {
+		if slices.Contains(withdrawn, candidate.Name) {
+			candidatesWithdrawn = append(candidatesWithdrawn, candidate)
+		}
+	}
+	if uint64(len(candidates)-len(candidatesWithdrawn)) < election.GetSeats() {
+		return nil, fmt.Errorf("cannot withdraw candidates leaving fewer candidates than seats")
+	}
+	return candidatesWithdrawn, nil
+}
 
-	result.Rounds = uint64(len(electionResults.Rounds))
+// Recount recounts a closed election with the options from the form and shows
+// how the result differs from the published one, nothing is stored but the
+// seed committed for recounts of a result that recorded none
+func (r *AdminRepo) Recount(c echo.Context) error {
+	election, recount, err := r.recountElection(c)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
 
-	for i, round := range electionResults.Rounds {
-		rounds := &storage.Round{}
-		var roundParsed uint64
-		roundParsed, err = strconv.ParseUint(strconv.Itoa(i), 10, 64)
-		if err != nil {
-			return r.errorHandle(c, fmt.Errorf("failed to parse uint round: %w", err))
-		}
-		rounds.Round = roundParsed
-		rounds.Blanks = uint64(round.NumberOfBlankVotes)
-		for j, can := range round.CandidateResults {
-			candidateStatus := &storage.CandidateStatus{}
-			var candidateRankParsed uint64
-			candidateRankParsed, err = strconv.ParseUint(strconv.Itoa(j), 10, 64)
-			if err != nil {
-				return r.errorHandle(c, fmt.Errorf("failed to parse uint candidate rank: %w", err))
-			}
-			candidateStatus.CandidateRank = candidateRankParsed
-			candidateStatus.Id = can.Candidate.Name
-			candidateStatus.NoOfVotes = can.NumberOfVotes
-			candidateStatus.Status = string(can.Status)
-			rounds.CandidateStatus = append(rounds.GetCandidateStatus(), candidateStatus)
+	candidates, err := r.store.GetCandidatesElectionID(election.GetId())
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	candidateNames := map[string]string{"R.O.N.": "R.O.N."}
+	for _, candidate := range candidates {
+		candidateNames[candidate.GetId()] = candidate.GetName()
+	}
+
+	ids := make([]string, 0, len(candidates)+1)
+	if election.GetRon() {
+		ids = append(ids, "R.O.N.")
+	}
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.GetId())
+	}
+	published := finalRoundStatus(election.GetResult())
+	recounted := finalRoundStatus(recount.GetResult())
+	rows := make([]*recountRow, 0, len(ids))
+	for _, id := range ids {
+		row := &recountRow{
+			Candidate: candidateNames[id],
+			Published: published[id],
+			Recount:   recounted[id],
 		}
-		result.Round = append(result.GetRound(), rounds)
+		if slices.Contains(election.GetResult().GetWithdrawn(), id) {
+			row.Published = &storage.CandidateStatus{Status: "Withdrawn"}
+		}
+		if slices.Contains(recount.GetResult().GetWithdrawn(), id) {
+			row.Recount = &storage.CandidateStatus{Status: "Withdrawn"}
+		}
+		row.Changed = row.Published.GetStatus() != row.Recount.GetStatus() || row.Published.GetVotes() != row.Recount.GetVotes()
+		rows = append(rows, row)
 	}
-	winners := electionResults.GetWinners()
 
-	if uint64(len(winners)) != election.Seats {
+	data := struct {
+		Election       *storage.Election
+		Recount        *storage.Election
+		Candidates     []*storage.Candidate
+		CandidateNames map[string]string
+		Rows           []*recountRow
+		WinnersChanged bool
+	}{
+		Election:       election,
+		Recount:        recount,
+		Candidates:     candidates,
+		CandidateNames: candidateNames,
+		Rows:           rows,
+		WinnersChanged: !winnersEqual(election.GetResult().GetWinners(), recount.GetResult().GetWinners()),
+	}
+	err = r.controller.Template.RenderTemplate(c.Response().Writer, data, templates.RecountTemplate)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	return nil
+}
+
+// ConfirmRecount recounts a closed election with the options from the form
+// and replaces the published result with the recount
+func (r *AdminRepo) ConfirmRecount(c echo.Context) error {
+	election, recount, err := r.recountElection(c)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if len(election.GetCountbacks()) > 0 {
+		return r.errorHandle(c, fmt.Errorf("cannot publish a recount of an election with countbacks, they were counted from the published result"))
+	}
+
+	election, err = r.store.EditElection(recount)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+
+	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", election.GetId()))
+}
+
+// Countback fills the seat of a winner who has stood down by counting again the
+// ballots they held when elected among the candidates who agree to stand, the
+// countback is stored alongside the published result
+func (r *AdminRepo) Countback(c echo.Context) error {
+	id := c.Param("id")
+	if len(id) == 0 {
+		return r.errorHandle(c, fmt.Errorf("invalid election id"))
+	}
+
+	election, err := r.store.FindElection(id)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if !election.GetClosed() || election.GetResult() == nil {
+		return r.errorHandle(c, fmt.Errorf("cannot countback election that is not closed"))
+	}
+	method, err := parseCountMethod(election.GetResult().GetMethod())
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if election.GetBallotType() == voting.BallotTypeMotion || method != voting.CountMethodSingleTransferableVote {
+		return r.errorHandle(c, fmt.Errorf("countback is only possible for single transferable vote counts"))
+	}
+
+	vacating := c.FormValue("vacating")
+	form, err := c.FormParams()
+	if err != nil {
+		return r.errorHandle(c, fmt.Errorf("failed to get form: %w", err))
+	}
+	standing := form["standing"]
+	vacancies, eligible, err := r.countbackCandidates(election)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if !slices.Contains(vacancies, vacating) {
+		return r.errorHandle(c, fmt.Errorf("vacating candidate must be a winner whose seat hasn't been filled by countback"))
+	}
+	if len(standing) == 0 {
+		return r.errorHandle(c, fmt.Errorf("countback needs at least one standing candidate"))
+	}
+	for _, candidate := range standing {
+		if !slices.ContainsFunc(eligible, func(c1 *storage.Candidate) bool { return c1.GetId() == candidate }) {
+			return r.errorHandle(c, fmt.Errorf("candidate cannot stand in countback: %s", candidate))
+		}
+	}
+
+	candidates, ballots, _, err := r.countedBallots(election)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	var vacatingCandidate *voting.Candidate
+	standingCandidates := make([]*voting.Candidate, 0, len(standing))
+	for _, candidate := range candidates {
+		switch {
+		case candidate.Name == vacating:
+			vacatingCandidate = candidate
+		case slices.Contains(standing, candidate.Name):
+			standingCandidates = append(standingCandidates, candidate)
+		}
+	}
+	if vacatingCandidate == nil || slices.Contains(election.GetResult().GetWithdrawn(), vacating) {
+		return r.errorHandle(c, fmt.Errorf("vacating candidate was withdrawn from the count"))
+	}
+
+	// the original count is replayed, so it must be counted exactly as published
+	options, err := resultCountOptions(election.GetResult())
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	options.Withdrawn, err = withdrawnCandidates(election, candidates, election.GetResult().GetWithdrawn())
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	options.Categories, err = r.electionCategories(election, candidates)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+
+	countbackResult, err := voting.Countback(candidates, ballots, election.GetSeats(), options, vacatingCandidate, standingCandidates)
+	if err != nil {
+		return r.errorHandle(c, fmt.Errorf("countback failed: %w", err))
+	}
+
+	electionResults := countbackResult.Results
+	result := &storage.Result{
+		Method:        method,
+		Arithmetic:    string(electionResults.Precision.Arithmetic),
+		DecimalPlaces: uint64(electionResults.Precision.DecimalPlaces),
+		TieBreakSeed:  electionResults.TieBreakSeed,
+		LotOrder:      candidateNames(electionResults.LotOrder),
+		CompareMethod: string(options.CompareMethodIfEquals),
+		Rounds:        uint64(len(electionResults.Rounds)),
+		Winners:       candidateNames(electionResults.GetWinners()),
+	}
+	result.Round, err = storeRounds(electionResults)
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	if len(result.GetWinners()) != 1 {
 		return r.errorHandle(c, fmt.Errorf("invalid abount of winners"))
 	}
 
-	names := make([]string, len(winners))
-	for i, w := range winners {
-		names[i] = w.Name
+	err = r.store.AddCountback(id, &storage.Countback{
+		Vacating:  vacating,
+		Standing:  standing,
+		Winner:    result.GetWinners()[0],
+		Ballots:   uint64(countbackResult.Ballots),
+		Votes:     electionResults.Precision.FormatVotes(countbackResult.Votes),
+		Result:    result,
+		CountedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return r.errorHandle(c, err)
 	}
 
-	result.Winners = names
+	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", id))
+}
 
-	election.Result = result
+// countbackCandidates returns the winner ids whose seat can be filled by
+// countback and the candidates who can stand, those neither elected nor
+// withdrawn from the count
+func (r *AdminRepo) countbackCandidates(election *storage.Election) ([]string, []*storage.Candidate, error) {
+	candidates, err := r.store.GetCandidatesElectionID(election.GetId())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	elected := slices.Clone(election.GetResult().GetWinners())
+	vacated := make([]string, 0, len(election.GetCountbacks()))
+	for _, countback := range election.GetCountbacks() {
+		elected = append(elected, countback.GetWinner())
+		vacated = append(vacated, countback.GetVacating())
+	}
 
-	election, err = r.store.EditElection(election)
+	vacancies := make([]string, 0)
+	for _, winner := range election.GetResult().GetWinners() {
+		if winner != "R.O.N." && !slices.Contains(vacated, winner) {
+			vacancies = append(vacancies, winner)
+		}
+	}
+
+	eligible := make([]*storage.Candidate, 0)
+	for _, candidate := range candidates {
+		if !slices.Contains(elected, candidate.GetId()) && !candidate.GetWithdrawn() && !slices.Contains(election.GetResult().GetWithdrawn(), candidate.GetId()) {
+			eligible = append(eligible, candidate)
+		}
+	}
+
+	return vacancies, eligible, nil
+}
+
+// recountRow compares a candidate's last round in the published result and the recount
+type recountRow struct {
+	Candidate string
+	Published *storage.CandidateStatus
+	Recount   *storage.CandidateStatus
+	Changed   bool
+}
+
+// recountElection returns the published election and a copy of it counted
+// again with the options from the form, the published tie-break seed is kept so
+// counting with unchanged options gives the published result
+func (r *AdminRepo) recountElection(c echo.Context) (*storage.Election, *storage.Election, error) {
+	id := c.Param("id")
+	if len(id) == 0 {
+		return nil, nil, fmt.Errorf("invalid election id")
+	}
+
+	election, err := r.store.FindElection(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !election.GetClosed() {
+		return nil, nil, fmt.Errorf("cannot recount election that is not closed")
+	}
+	if election.GetBallotType() == voting.BallotTypeMotion {
+		return nil, nil, fmt.Errorf("cannot recount a motion")
+	}
+
+	method, err := ballotTypeCountMethod(election.GetBallotType(), c.FormValue("method"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = validateCountMethodSeats(method, election.GetSeats()); err != nil {
+		return nil, nil, err
+	}
+	arithmetic, decimalPlaces, err := parsePrecision(method, c.FormValue("arithmetic"), c.FormValue("decimalPlaces"))
+	if err != nil {
+		return nil, nil, err
+	}
+	quota, err := parseQuota(c.FormValue("quota"))
+	if err != nil {
+		return nil, nil, err
+	}
+	surplusRule, err := parseSurplusRule(c.FormValue("surplusRule"))
+	if err != nil {
+		return nil, nil, err
+	}
+	batchElimination, err := parseBatchElimination(method, c.FormValue("batchElimination"))
+	if err != nil {
+		return nil, nil, err
+	}
+	compareMethod, err := parseCompareMethod(c.FormValue("compareMethod"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	form, err := c.FormParams()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get form: %w", err)
+	}
+	candidates, err := r.store.GetCandidatesElectionID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	withdrawn := form["withdraw"]
+	for _, candidate := range withdrawn {
+		if candidate == "R.O.N." && election.GetRon() {
+			continue
+		}
+		if !slices.ContainsFunc(candidates, func(c1 *storage.Candidate) bool { return c1.GetId() == candidate }) {
+			return nil, nil, fmt.Errorf("cannot withdraw unknown candidate: %s", candidate)
+		}
+	}
+
+	recount := proto.Clone(election).(*storage.Election)
+	recount.Method = method
+	recount.Arithmetic = arithmetic
+	recount.DecimalPlaces = decimalPlaces
+	recount.Quota = quota
+	recount.SurplusRule = surplusRule
+	recount.CompareMethod = compareMethod
+	recount.BatchElimination = batchElimination
+	recount.TieBreakSeed = election.GetResult().GetTieBreakSeed()
+	if len(recount.GetTieBreakSeed()) == 0 {
+		// a result from before seeds were recorded has none, a seed is drawn
+		// once and committed to the election so every recount of it, shown or
+		// published, decides its lots the same way
+		recount.TieBreakSeed, err = r.store.CommitRecountSeed(id, voting.NewTieBreakSeed())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	recount.Result, err = r.countElection(recount, withdrawn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return election, recount, nil
+}
+
+// finalRoundStatus maps candidate ids to their status in the last round
+func finalRoundStatus(result *storage.Result) map[string]*storage.CandidateStatus {
+	statuses := make(map[string]*storage.CandidateStatus)
+	rounds := result.GetRound()
+	if len(rounds) == 0 {
+		return statuses
+	}
+	for _, status := range rounds[len(rounds)-1].GetCandidateStatus() {
+		statuses[status.GetId()] = status
+	}
+	return statuses
+}
+
+// winnersEqual reports whether both results elected the same candidates, in any order
+func winnersEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, winner := range a {
+		if !slices.Contains(b, winner) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *AdminRepo) SetTieBreakSeed(c echo.Context) error {
+	id := c.Param("id")
+	if len(id) == 0 {
+		return r.errorHandle(c, fmt.Errorf("invalid election id"))
+	}
+
+	election, err := r.store.FindElection(id)
 	if err != nil {
 		return r.errorHandle(c, err)
 	}
+	if election.GetOpen() || election.GetClosed() {
+		return r.errorHandle(c, fmt.Errorf("cannot set tie-break seed once the election has opened"))
+	}
+
+	seed := c.FormValue("seed")
+	if len(seed) == 0 {
+		return r.errorHandle(c, fmt.Errorf("tie-break seed cannot be empty"))
+	}
 
-	err = r.store.CloseElection(id)
+	err = r.store.SetTieBreakSeed(id, seed)
 	if err != nil {
 		return r.errorHandle(c, err)
 	}
 
-	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", election.GetId()))
+	return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", id))
 }
 
 func (r *AdminRepo) Exclude(c echo.Context) error {
@@ -585,13 +1567,11 @@ func (r *AdminRepo) Exclude(c echo.Context) error {
 		return r.errorHandle(c, err)
 	}
 
-	for _, v := range election.GetExcluded() {
-		if v.GetEmail() == voter.GetEmail() {
-			return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", election.GetId()))
-		}
+	if slices.Contains(election.GetExcludedVoters(), voter.GetEmail()) {
+		return c.Redirect(http.StatusFound, fmt.Sprintf("/admin/election/%s", election.GetId()))
 	}
 
-	election.Excluded = append(election.GetExcluded(), voter)
+	election.ExcludedVoters = append(election.GetExcludedVoters(), voter.GetEmail())
 
 	_, err = r.store.EditElection(election)
 	if err != nil {
@@ -619,17 +1599,13 @@ func (r *AdminRepo) Include(c echo.Context) error {
 		return r.errorHandle(c, err)
 	}
 
-	for index, v := range election.GetExcluded() {
-		if v.GetEmail() == voter.GetEmail() {
-			copy(election.GetExcluded()[index:], election.GetExcluded()[index+1:])     // Shift a[i+1:] left one index
-			election.Excluded[len(election.GetExcluded())-1] = nil                     // Erase last element (write zero value)
-			election.Excluded = election.GetExcluded()[:len(election.GetExcluded())-1] // Truncate slice
+	index := slices.Index(election.GetExcludedVoters(), voter.GetEmail())
+	if index >= 0 {
+		election.ExcludedVoters = slices.Delete(election.GetExcludedVoters(), index, index+1)
 
-			_, err = r.store.EditElection(election)
-			if err != nil {
-				return r.errorHandle(c, err)
-			}
-			break
+		_, err = r.store.EditElection(election)
+		if err != nil {
+			return r.errorHandle(c, err)
 		}
 	}
 
@@ -683,10 +1659,19 @@ func (r *AdminRepo) AddVoter(c echo.Context) error {
 	if len(name) == 0 || len(email) == 0 {
 		return r.errorHandle(c, fmt.Errorf("name and email need to be filled"))
 	}
-	_, err := r.store.AddVoter(&storage.Voter{
+	weight, err := parseWeight(c.FormValue("weight"))
+	if err != nil {
+		return r.errorHandle(c, err)
+	}
+	voter := &storage.Voter{
 		Email: email,
 		Name:  name,
-	})
+	}
+	// a weight of 1 is stored empty, the same as a voter who registered themselves
+	if weight != nil && weight.Cmp(big.NewRat(1, 1)) != 0 {
+		voter.Weight = weight.RatString()
+	}
+	_, err = r.store.AddVoter(voter)
 	if err != nil {
 		return r.errorHandle(c, err)
 	}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/ystv/stv-web/storage"
+	"github.com/ystv/stv-web/voting"
+)
+
+// bltImport converts the candidates and ballots of a BLT file for the store.
+// Choices refer to candidates by position until the store gives them ids, a
+// candidate called R.O.N. is taken to be the election's R.O.N.
+func bltImport(blt *voting.BLT) ([]*storage.Candidate, []*storage.Ballot, bool) {
+	ron := false
+	positions := make(map[*voting.Candidate]string, len(blt.Candidates))
+	candidates := make([]*storage.Candidate, 0, len(blt.Candidates))
+	for _, candidate := range blt.Candidates {
+		if candidate.Name == "R.O.N." {
+			ron = true
+			positions[candidate] = "R.O.N."
+			continue
+		}
+		positions[candidate] = strconv.Itoa(len(candidates))
+		candidates = append(candidates, &storage.Candidate{Name: candidate.Name})
+	}
+	// the parser has already struck withdrawn candidates off the ballots
+	for _, candidate := range blt.Withdrawn {
+		candidates = append(candidates, &storage.Candidate{Name: candidate.Name, Withdrawn: true})
+	}
+
+	ballots := make([]*storage.Ballot, 0, len(blt.Ballots))
+	for _, ballot := range blt.Ballots {
+		choice := make(map[uint64]string, len(ballot.RankedCandidates))
+		for i, candidate := range ballot.RankedCandidates {
+			choice[uint64(i)] = positions[candidate]
+		}
+		ballots = append(ballots, &storage.Ballot{Choice: choice})
+	}
+	return candidates, ballots, ron
+}
+
+// bltExport converts the ballots of a closed election to a BLT file, abstained
+// and spoilt ballots are left out
+func bltExport(election *storage.Election, candidatesStore []*storage.Candidate, ballots []*storage.Ballot) (*voting.BLT, error) {
+	blt := &voting.BLT{
+		Title:         election.GetName(),
+		NumberOfSeats: election.GetSeats(),
+	}
+	candidates := make(map[string]*voting.Candidate, len(candidatesStore)+1)
+	if election.GetRon() {
+		candidates["R.O.N."] = &voting.Candidate{Name: "R.O.N."}
+		blt.Candidates = append(blt.Candidates, candidates["R.O.N."])
+	}
+	for _, c1 := range candidatesStore {
+		candidates[c1.GetId()] = &voting.Candidate{Name: c1.GetName()}
+		if c1.GetWithdrawn() {
+			blt.Withdrawn = append(blt.Withdrawn, candidates[c1.GetId()])
+			continue
+		}
+		blt.Candidates = append(blt.Candidates, candidates[c1.GetId()])
+	}
+
+	for _, ballot := range ballots {
+		if ballot.GetAbstain() || slices.ContainsFunc(election.GetResult().GetSpoilt(), func(spoilt *storage.SpoiltBallot) bool { return spoilt.GetBallot() == ballot.GetId() }) {
+			continue
+		}
+		var ranked []*voting.Candidate
+		for i := uint64(0); i < uint64(len(ballot.GetChoice())); i++ {
+			if candidate, ok := candidates[ballot.GetChoice()[i]]; ok {
+				ranked = append(ranked, candidate)
+			}
+		}
+		bltBallot, err := voting.NewBallot(ranked)
+		if err != nil {
+			return nil, fmt.Errorf("cannot export ballot %s: %w", ballot.GetId(), err)
+		}
+		bltBallot.Weight, err = parseWeight(ballot.GetWeight())
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight on ballot %s: %w", ballot.GetId(), err)
+		}
+		blt.Ballots = append(blt.Ballots, bltBallot)
+	}
+	return blt, nil
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ystv/stv-web/storage"
+	"github.com/ystv/stv-web/store"
+	"github.com/ystv/stv-web/voting"
+)
+
+// bltRankings are the names each ballot of a BLT ranks, sorted so files can be
+// compared whatever order the ballots were stored in
+func bltRankings(blt *voting.BLT) []string {
+	rankings := make([]string, 0, len(blt.Ballots))
+	for _, ballot := range blt.Ballots {
+		names := make([]string, 0, len(ballot.RankedCandidates))
+		for _, candidate := range ballot.RankedCandidates {
+			names = append(names, candidate.Name)
+		}
+		rankings = append(rankings, strings.Join(names, ">"))
+	}
+	slices.Sort(rankings)
+	return rankings
+}
+
+func bltNames(candidates []*voting.Candidate) []string {
+	names := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		names = append(names, candidate.Name)
+	}
+	return names
+}
+
+func TestBLTImportExportRoundTrip(t *testing.T) {
+	t.Chdir(t.TempDir())
+	s, err := store.NewStore(false, store.BackendFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blt, err := voting.ParseBLT(strings.NewReader(`4 2
+-4  # Dan withdrew after the ballots were printed
+3 1 2 0
+2 2 4 1 0
+1 3 0
+0
+"R.O.N."
+"Alice"
+"Bob"
+"Dan"
+"Chair"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, ballots, ron := bltImport(blt)
+	if !ron {
+		t.Fatal("R.O.N. in the blt not taken as the election's R.O.N.")
+	}
+	for _, candidate := range candidates {
+		if candidate.GetName() == "R.O.N." {
+			t.Fatal("R.O.N. imported as a candidate")
+		}
+	}
+	if choice := ballots[0].GetChoice()[0]; choice != "R.O.N." {
+		t.Fatalf("first preference for R.O.N. imported as %s", choice)
+	}
+	election, err := s.ImportElection(&storage.Election{Name: blt.Title, Seats: blt.NumberOfSeats, Ron: ron}, candidates, ballots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, 0, len(ballots))
+	for _, ballot := range ballots {
+		ids = append(ids, ballot.GetId())
+	}
+	if err = s.CloseElection(election.GetId(), &storage.Result{}, false, ids); err != nil {
+		t.Fatal(err)
+	}
+
+	election, err = s.FindElection(election.GetId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := s.GetCandidatesElectionID(election.GetId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	storedBallots, err := s.GetBallotsElectionID(election.GetId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported, err := bltExport(election, stored, storedBallots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = voting.WriteBLT(&buf, exported); err != nil {
+		t.Fatal(err)
+	}
+	again, err := voting.ParseBLT(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again.Title != blt.Title || again.NumberOfSeats != blt.NumberOfSeats {
+		t.Fatalf("exported %q for %d seats, want %q for %d", again.Title, again.NumberOfSeats, blt.Title, blt.NumberOfSeats)
+	}
+	if names := bltNames(again.Candidates); !slices.Equal(names, bltNames(blt.Candidates)) {
+		t.Fatalf("exported candidates %v, want %v", names, bltNames(blt.Candidates))
+	}
+	if names := bltNames(again.Withdrawn); !slices.Equal(names, []string{"Dan"}) {
+		t.Fatalf("exported withdrawn %v, want Dan", names)
+	}
+	if rankings := bltRankings(again); !slices.Equal(rankings, bltRankings(blt)) {
+		t.Fatalf("exported ballots %v, want %v", rankings, bltRankings(blt))
+	}
+}
+
+func TestBLTExportWeights(t *testing.T) {
+	election := &storage.Election{
+		Name:  "Chair",
+		Seats: 1,
+		Result: &storage.Result{
+			Spoilt: []*storage.SpoiltBallot{{Ballot: "spoilt"}},
+		},
+	}
+	candidates := []*storage.Candidate{{Id: "a", Name: "Alice"}, {Id: "b", Name: "Bob"}}
+	tests := []struct {
+		name    string
+		ballots []*storage.Ballot
+		want    string
+	}{
+		{
+			// two halves make a whole line, the abstained and spoilt ballots are left out
+			name: "halves",
+			ballots: []*storage.Ballot{
+				{Id: "1", Choice: map[uint64]string{0: "a"}, Weight: "1/2"},
+				{Id: "2", Choice: map[uint64]string{0: "a"}, Weight: "1/2"},
+				{Id: "3", Choice: map[uint64]string{0: "b", 1: "a"}, Weight: "2"},
+				{Id: "abstained", Abstain: true},
+				{Id: "spoilt", Choice: map[uint64]string{0: "b"}},
+			},
+			want: "2 1\n1 1 0\n2 2 1 0\n0\n\"Alice\"\n\"Bob\"\n\"Chair\"\n",
+		},
+		{
+			name: "half left over",
+			ballots: []*storage.Ballot{
+				{Id: "1", Choice: map[uint64]string{0: "a"}, Weight: "1/2"},
+				{Id: "2", Choice: map[uint64]string{0: "a"}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blt, err := bltExport(election, candidates, test.ballots)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			err = voting.WriteBLT(&buf, blt)
+			if len(test.want) == 0 {
+				if err == nil {
+					t.Fatal("ballots with a fractional total weight exported")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.want {
+				t.Fatalf("exported\n%s\nwant\n%s", buf.String(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package controllers
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ystv/stv-web/storage"
+	"github.com/ystv/stv-web/voting"
+)
+
+// parseCountMethod validates the counting method from a form or stored election,
+// elections created before methods could be chosen are counted with SingleTransferableVote
+func parseCountMethod(method string) (string, error) {
+	switch method {
+	case "", voting.CountMethodSingleTransferableVote:
+		return voting.CountMethodSingleTransferableVote, nil
+	case voting.CountMethodMeek, voting.CountMethodSchulze, voting.CountMethodApproval, voting.CountMethodPlurality:
+		return method, nil
+	default:
+		return "", fmt.Errorf("invalid counting method: %s", method)
+	}
+}
+
+// parseBallotType validates the ballot type from a form, elections created
+// before ballot types could be chosen use ranked ballots
+func parseBallotType(ballotType string) (string, error) {
+	switch ballotType {
+	case "", voting.BallotTypeRanked:
+		return voting.BallotTypeRanked, nil
+	case voting.BallotTypeApproval, voting.BallotTypePlurality, voting.BallotTypeMotion:
+		return ballotType, nil
+	default:
+		return "", fmt.Errorf("invalid ballot type: %s", ballotType)
+	}
+}
+
+// ballotTypeCountMethod picks the counting method for the ballot type, only
+// ranked ballots have a choice of method
+func ballotTypeCountMethod(ballotType, method string) (string, error) {
+	switch ballotType {
+	case voting.BallotTypeApproval:
+		return voting.CountMethodApproval, nil
+	case voting.BallotTypePlurality:
+		return voting.CountMethodPlurality, nil
+	case voting.BallotTypeMotion:
+		return voting.CountMethodMotion, nil
+	}
+	method, err := parseCountMethod(method)
+	if err != nil {
+		return "", err
+	}
+	if method == voting.CountMethodApproval || method == voting.CountMethodPlurality {
+		return "", fmt.Errorf("ranked ballots cannot be counted with %s", method)
+	}
+	return method, nil
+}
+
+// parseMotion validates the pass threshold and quorum of a motion from a form,
+// an empty quorum means the motion has no quorum
+func parseMotion(threshold, tempQuorum string) (string, uint64, error) {
+	if len(threshold) == 0 {
+		threshold = voting.MotionThresholdSimpleMajority
+	}
+	if err := voting.MotionThreshold(threshold).Validate(); err != nil {
+		return "", 0, fmt.Errorf("invalid motion threshold: %s", threshold)
+	}
+	if len(tempQuorum) == 0 {
+		return threshold, 0, nil
+	}
+	quorum, err := strconv.ParseUint(tempQuorum, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("quorum must be a positive integer value")
+	}
+	return threshold, quorum, nil
+}
+
+// parseCategory validates the fewest and most seats a category can fill from a
+// form, an empty maximum means the category has no maximum
+func parseCategory(tempMin, tempMax string, seats uint64) (uint64, uint64, error) {
+	var categoryMin, categoryMax uint64
+	var err error
+	if len(tempMin) > 0 {
+		categoryMin, err = strconv.ParseUint(tempMin, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("category minimum must be a positive integer value")
+		}
+	}
+	if len(tempMax) > 0 {
+		categoryMax, err = strconv.ParseUint(tempMax, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("category maximum must be a positive integer value")
+		}
+	}
+	if categoryMin == 0 && categoryMax == 0 {
+		return 0, 0, fmt.Errorf("category needs a minimum or a maximum")
+	}
+	if categoryMin > seats {
+		return 0, 0, fmt.Errorf("category minimum cannot be more than the %d seats", seats)
+	}
+	if categoryMax > 0 && categoryMin > categoryMax {
+		return 0, 0, fmt.Errorf("category minimum cannot be more than its maximum")
+	}
+	return categoryMin, categoryMax, nil
+}
+
+// parseWeight validates a voter weight from a form or stored voter or ballot,
+// an empty weight is 1 and weights are kept as exact fractions
+func parseWeight(tempWeight string) (*big.Rat, error) {
+	if len(tempWeight) == 0 {
+		return nil, nil
+	}
+	weight, ok := new(big.Rat).SetString(tempWeight)
+	if !ok || weight.Sign() <= 0 {
+		return nil, fmt.Errorf("weight must be a positive number or fraction: %s", tempWeight)
+	}
+	return weight, nil
+}
+
+// countsWeights reports whether the count of the election honours voter
+// weights, motions and Schulze count every ballot once
+func countsWeights(election *storage.Election) bool {
+	return election.GetBallotType() != voting.BallotTypeMotion && election.GetMethod() != voting.CountMethodSchulze
+}
+
+// storeSpoilt converts the ballots a count set aside into their stored form
+func storeSpoilt(invalid []*voting.InvalidBallot) []*storage.SpoiltBallot {
+	spoilt := make([]*storage.SpoiltBallot, 0, len(invalid))
+	for _, ballot := range invalid {
+		spoilt = append(spoilt, &storage.SpoiltBallot{
+			Ballot: ballot.Ballot.ID,
+			Reason: ballot.Reason,
+		})
+	}
+	return spoilt
+}
+
+// countAbstained returns the number of ballots cast as abstentions
+func countAbstained(ballots []*storage.Ballot) uint64 {
+	var abstained uint64
+	for _, ballot := range ballots {
+		if ballot.GetAbstain() {
+			abstained++
+		}
+	}
+	return abstained
+}
+
+// storeMotionResult converts the result of a motion into its stored form
+func storeMotionResult(motion *voting.MotionResult) *storage.MotionResult {
+	return &storage.MotionResult{
+		VotesFor:      motion.VotesFor,
+		VotesAgainst:  motion.VotesAgainst,
+		Abstentions:   motion.Abstentions,
+		Threshold:     string(motion.Threshold),
+		Quorum:        motion.Quorum,
+		QuorumReached: motion.QuorumReached,
+		Passed:        motion.Passed,
+	}
+}
+
+// validateCountMethodSeats checks the counting method can fill the seats
+func validateCountMethodSeats(method string, seats uint64) error {
+	if method == voting.CountMethodSchulze && seats != 1 {
+		return fmt.Errorf("schulze elections can only have 1 seat")
+	}
+	return nil
+}
+
+// parseBatchElimination validates batch elimination from a form, only
+// SingleTransferableVote excludes candidates together
+func parseBatchElimination(method, tempBatchElimination string) (bool, error) {
+	if len(tempBatchElimination) == 0 {
+		return false, nil
+	}
+	if method != voting.CountMethodSingleTransferableVote {
+		return false, fmt.Errorf("batch elimination can only be used by %s", voting.CountMethodSingleTransferableVote)
+	}
+	return true, nil
+}
+
+// parseQuota validates the quota from a form or stored election, elections
+// created before quotas could be chosen are counted with the exact Droop quota
+func parseQuota(quota string) (string, error) {
+	switch quota {
+	case "", voting.QuotaExactDroop:
+		return voting.QuotaExactDroop, nil
+	case voting.QuotaDroop, voting.QuotaHare, voting.QuotaHagenbachBischoff:
+		return quota, nil
+	default:
+		return "", fmt.Errorf("invalid quota: %s", quota)
+	}
+}
+
+// parseSurplusRule validates the surplus rule from a form or stored election,
+// elections created before rules could be chosen use inclusive Gregory
+func parseSurplusRule(surplusRule string) (string, error) {
+	switch surplusRule {
+	case "", voting.SurplusRuleInclusiveGregory:
+		return voting.SurplusRuleInclusiveGregory, nil
+	case voting.SurplusRuleLastParcel, voting.SurplusRuleWeightedInclusiveGregory:
+		return surplusRule, nil
+	default:
+		return "", fmt.Errorf("invalid surplus rule: %s", surplusRule)
+	}
+}
+
+// parseCompareMethod validates how ties between candidates to exclude are
+// broken, elections created before it could be chosen look at later preferences
+func parseCompareMethod(compareMethod string) (string, error) {
+	switch compareMethod {
+	case "", voting.CompareMethodMostSecondChoice:
+		return voting.CompareMethodMostSecondChoice, nil
+	case voting.CompareMethodRandom:
+		return compareMethod, nil
+	default:
+		return "", fmt.Errorf("invalid tie method: %s", compareMethod)
+	}
+}
+
+// parsePrecision validates the arithmetic and decimal places from a form,
+// decimal places are only kept for fixed decimal counts. Meek keep values only
+// converge in the limit so it can't be counted in exact fractions.
+func parsePrecision(method, arithmetic, tempDecimalPlaces string) (string, uint64, error) {
+	switch arithmetic {
+	case "", voting.ArithmeticExact:
+		if method == voting.CountMethodMeek {
+			return "", 0, fmt.Errorf("%s needs fixed decimal arithmetic", voting.CountMethodMeek)
+		}
+		return voting.ArithmeticExact, 0, nil
+	case voting.ArithmeticFixedDecimal:
+		if len(tempDecimalPlaces) == 0 {
+			return arithmetic, voting.DefaultDecimalPlaces, nil
+		}
+		decimalPlaces, err := strconv.ParseUint(tempDecimalPlaces, 10, 64)
+		if err != nil || decimalPlaces > 9 {
+			return "", 0, fmt.Errorf("number of decimal places must be an integer value between 0 and 9")
+		}
+		return arithmetic, decimalPlaces, nil
+	default:
+		return "", 0, fmt.Errorf("invalid arithmetic: %s", arithmetic)
+	}
+}
+
+// electionCountOptions builds the count options configured on the election
+func electionCountOptions(election *storage.Election) (voting.SingleTransferableVoteOptions, error) {
+	options := voting.DefaultSingleTransferableVoteOptions()
+
+	arithmetic, decimalPlaces, err := parsePrecision(election.GetMethod(), election.GetArithmetic(), strconv.FormatUint(election.GetDecimalPlaces(), 10))
+	if err != nil {
+		return options, err
+	}
+	options.Precision = voting.Precision{
+		Arithmetic:    voting.Arithmetic(arithmetic),
+		DecimalPlaces: uint(decimalPlaces),
+	}
+	quota, err := parseQuota(election.GetQuota())
+	if err != nil {
+		return options, err
+	}
+	options.Quota = voting.Quota(quota)
+	surplusRule, err := parseSurplusRule(election.GetSurplusRule())
+	if err != nil {
+		return options, err
+	}
+	options.SurplusRule = voting.SurplusRule(surplusRule)
+	compareMethod, err := parseCompareMethod(election.GetCompareMethod())
+	if err != nil {
+		return options, err
+	}
+	options.CompareMethodIfEquals = voting.CompareMethod(compareMethod)
+	options.TieBreakSeed = election.GetTieBreakSeed()
+	options.BatchElimination = election.GetBatchElimination()
+
+	return options, nil
+}
+
+// resultCountOptions builds the count options a stored result was counted
+// with, counting again with them replays the published count whatever the
+// election is configured with now
+func resultCountOptions(result *storage.Result) (voting.SingleTransferableVoteOptions, error) {
+	return electionCountOptions(&storage.Election{
+		Method:           result.GetMethod(),
+		Arithmetic:       result.GetArithmetic(),
+		DecimalPlaces:    result.GetDecimalPlaces(),
+		Quota:            result.GetQuota(),
+		SurplusRule:      result.GetSurplusRule(),
+		CompareMethod:    result.GetCompareMethod(),
+		TieBreakSeed:     result.GetTieBreakSeed(),
+		BatchElimination: result.GetBatchElimination(),
+	})
+}
+
+// storeRounds converts the rounds of a count into their stored form
+func storeRounds(electionResults *voting.ElectionResults) ([]*storage.Round, error) {
+	stored := make([]*storage.Round, 0, len(electionResults.Rounds))
+	for i, round := range electionResults.Rounds {
+		rounds := &storage.Round{}
+		roundParsed, err := strconv.ParseUint(strconv.Itoa(i), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse uint round: %w", err)
+		}
+		rounds.Round = roundParsed
+		rounds.Blanks = wholeVotes(round.NumberOfBlankVotes)
+		rounds.BlankVotes = electionResults.Precision.FormatVotes(round.NumberOfBlankVotes)
+		rounds.LossByFractions = electionResults.Precision.FormatVotes(round.LossByFractions)
+		rounds.TieBreaks = storeTieBreaks(round.TieBreaks)
+		rounds.Events = storeRoundEvents(round.Events, electionResults.Precision)
+		rounds.Transfers = storeTransfers(round.Transfers, electionResults.Precision)
+		for j, can := range round.CandidateResults {
+			candidateStatus := &storage.CandidateStatus{}
+			candidateRankParsed, err := strconv.ParseUint(strconv.Itoa(j), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse uint candidate rank: %w", err)
+			}
+			candidateStatus.CandidateRank = candidateRankParsed
+			candidateStatus.Id = can.Candidate.Name
+			candidateStatus.NoOfVotes, _ = can.NumberOfVotes.Float64()
+			candidateStatus.Votes = electionResults.Precision.FormatVotes(can.NumberOfVotes)
+			candidateStatus.Status = string(can.Status)
+			candidateStatus.Ballots = uint64(can.NumberOfBallots)
+			rounds.CandidateStatus = append(rounds.GetCandidateStatus(), candidateStatus)
+		}
+		stored = append(stored, rounds)
+	}
+	return stored, nil
+}
+
+// storeTieBreaks converts the tie-breaks of a round into their stored form
+func storeTieBreaks(tieBreaks []*voting.TieBreak) []*storage.TieBreak {
+	stored := make([]*storage.TieBreak, 0, len(tieBreaks))
+	for _, tb := range tieBreaks {
+		stored = append(stored, &storage.TieBreak{
+			Candidates: candidateNames(tb.Candidates),
+			Method:     string(tb.Method),
+			Reason:     string(tb.Reason),
+		})
+	}
+	return stored
+}
+
+// storeRoundEvents converts the events of a round into their stored form
+func storeRoundEvents(events []*voting.RoundEvent, precision voting.Precision) []*storage.RoundEvent {
+	stored := make([]*storage.RoundEvent, 0, len(events))
+	for _, event := range events {
+		stored = append(stored, &storage.RoundEvent{
+			Kind:       string(event.Kind),
+			Candidates: candidateNames(event.Candidates),
+			Votes:      precision.FormatVotes(event.Votes),
+			Category:   event.Category,
+		})
+	}
+	return stored
+}
+
+// storeTransfers converts the transfer sheet of a round into its stored form
+func storeTransfers(transfers []*voting.Transfer, precision voting.Precision) []*storage.Transfer {
+	stored := make([]*storage.Transfer, 0, len(transfers))
+	for _, transfer := range transfers {
+		to := make([]*storage.TransferAmount, 0, len(transfer.To))
+		for _, amount := range transfer.To {
+			to = append(to, &storage.TransferAmount{
+				Candidate: amount.Candidate.Name,
+				Ballots:   uint64(amount.Ballots),
+				Votes:     precision.FormatVotes(amount.Votes),
+			})
+		}
+		storedTransfer := &storage.Transfer{
+			From:             transfer.From.Name,
+			Kind:             string(transfer.Kind),
+			Value:            precision.FormatVotes(transfer.Value),
+			To:               to,
+			ExhaustedBallots: uint64(transfer.ExhaustedBallots),
+			ExhaustedVotes:   precision.FormatVotes(transfer.ExhaustedVotes),
+			Weighted:         transfer.Weighted,
+		}
+		// only fixed decimal counts lose anything by fractions
+		if transfer.LossByFractions.Sign() != 0 {
+			storedTransfer.LossByFractions = precision.FormatVotes(transfer.LossByFractions)
+		}
+		stored = append(stored, storedTransfer)
+	}
+	return stored
+}
+
+// storePairwise converts a Schulze table into its stored form
+func storePairwise(table [][]int) []*storage.PairwiseRow {
+	stored := make([]*storage.PairwiseRow, 0, len(table))
+	for _, row := range table {
+		counts := make([]uint64, len(row))
+		for i, count := range row {
+			counts[i] = uint64(count)
+		}
+		stored = append(stored, &storage.PairwiseRow{Counts: counts})
+	}
+	return stored
+}
+
+// candidateNames returns the names of the voting candidates, these are the
+// stored candidate ids or R.O.N.
+func candidateNames(candidates []*voting.Candidate) []string {
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// wholeVotes truncates a vote value to the whole votes shown in older results
+func wholeVotes(votes *big.Rat) uint64 {
+	return new(big.Int).Quo(votes.Num(), votes.Denom()).Uint64()
+}
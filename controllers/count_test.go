@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/ystv/stv-web/storage"
+	"github.com/ystv/stv-web/voting"
+)
+
+func TestCountbackCountedAsPublished(t *testing.T) {
+	// the election was counted with an exact Droop quota, its options were
+	// changed to a Droop quota after it closed
+	election := &storage.Election{
+		Method:       voting.CountMethodSingleTransferableVote,
+		Quota:        voting.QuotaDroop,
+		SurplusRule:  voting.SurplusRuleLastParcel,
+		TieBreakSeed: "changed",
+		Result: &storage.Result{
+			Method:        voting.CountMethodSingleTransferableVote,
+			Arithmetic:    voting.ArithmeticFixedDecimal,
+			DecimalPlaces: 5,
+			Quota:         voting.QuotaExactDroop,
+			SurplusRule:   voting.SurplusRuleInclusiveGregory,
+			CompareMethod: voting.CompareMethodRandom,
+			TieBreakSeed:  "published",
+		},
+	}
+
+	options, err := resultCountOptions(election.GetResult())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Precision.Arithmetic != voting.ArithmeticFixedDecimal || options.Precision.DecimalPlaces != 5 ||
+		options.Quota != voting.QuotaExactDroop || options.SurplusRule != voting.SurplusRuleInclusiveGregory ||
+		options.CompareMethodIfEquals != voting.CompareMethodRandom || options.TieBreakSeed != "published" {
+		t.Fatalf("countback options %+v, want the options of the published result", options)
+	}
+
+	// A is elected on the quota of 6 of the 18 ballots, replayed with the
+	// changed quota of 7 A would keep more of the ballots' value
+	candidates := []*voting.Candidate{voting.NewCandidate("A"), voting.NewCandidate("B"), voting.NewCandidate("C"), voting.NewCandidate("D")}
+	var ballots []*voting.Ballot
+	for _, parcel := range []struct {
+		count   int
+		ranking []int
+	}{
+		{count: 6, ranking: []int{0, 2, 1}},
+		{count: 2, ranking: []int{0, 3}},
+		{count: 5, ranking: []int{1}},
+		{count: 3, ranking: []int{2}},
+		{count: 2, ranking: []int{3, 2}},
+	} {
+		for range parcel.count {
+			ranking := make([]*voting.Candidate, 0, len(parcel.ranking))
+			for _, i := range parcel.ranking {
+				ranking = append(ranking, candidates[i])
+			}
+			ballot, err := voting.NewBallot(ranking)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ballots = append(ballots, ballot)
+		}
+	}
+
+	countback, err := voting.Countback(candidates, ballots, 2, options, candidates[0], candidates[1:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if votes := options.Precision.FormatVotes(countback.Votes); votes != "6.00000" {
+		t.Fatalf("countback of %s votes held by A, want the published quota of 6.00000", votes)
+	}
+}
@@ -2,13 +2,18 @@ package controllers
 
 import (
 	"fmt"
+	"maps"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/ystv/stv-web/storage"
 	"github.com/ystv/stv-web/store"
 	"github.com/ystv/stv-web/templates"
+	"github.com/ystv/stv-web/voting"
 )
 
 type VoteRepo struct {
@@ -91,14 +96,27 @@ func (r *VoteRepo) Vote(c echo.Context) error {
 		return nil
 	}
 
+	// withdrawn candidates are left off the ballot, voters get a notice instead
+	standing := make([]*storage.Candidate, 0, len(c1))
+	withdrawn := make([]*storage.Candidate, 0)
+	for _, candidate := range c1 {
+		if candidate.GetWithdrawn() {
+			withdrawn = append(withdrawn, candidate)
+			continue
+		}
+		standing = append(standing, candidate)
+	}
+
 	data := struct {
 		Election   *storage.Election
 		Candidates []*storage.Candidate
+		Withdrawn  []*storage.Candidate
 		Voter      *storage.Voter
 		URL        string
 	}{
 		Election:   e1,
-		Candidates: c1,
+		Candidates: standing,
+		Withdrawn:  withdrawn,
 		Voter:      v1,
 		URL:        u1.GetUrl(),
 	}
@@ -130,29 +148,45 @@ func (r *VoteRepo) AddVote(c echo.Context) error {
 		return err
 	}
 
-	m := make(map[uint64]string)
+	e1, err := r.store.FindElection(u1.GetElection())
+	if err != nil {
+		return err
+	}
 
-	var i uint64
-	for i = 0; i < uint64(len(c.Request().Form)); i++ {
-		m[i] = c.Request().Form.Get("order~" + strconv.FormatUint(i, 10))
+	m, abstain, err := r.ballotChoices(e1, c.Request().Form)
+	if err != nil {
+		err = r.controller.Template.RenderTemplate(c.Response().Writer, struct{ Error string }{Error: err.Error()}, templates.VoteErrorTemplate)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("invalid ballot")
 	}
 
 	ballot := &storage.Ballot{
 		Election: u1.GetElection(),
 		Choice:   m,
+		Abstain:  abstain,
 	}
 
-	_, err = r.store.AddBallot(ballot)
-	if err != nil {
-		err = r.controller.Template.RenderTemplate(c.Response().Writer, struct{ Error string }{Error: err.Error()}, templates.VoteErrorTemplate)
+	// the voter's weight is carried onto the anonymous ballot, the ballot keeps
+	// no link back to the voter
+	if countsWeights(e1) {
+		var voter *storage.Voter
+		voter, err = r.store.FindVoter(u1.GetVoter())
 		if err != nil {
 			return err
 		}
-		return err
+		ballot.Weight = voter.GetWeight()
 	}
 
-	err = r.store.SetURLVoted(u1.GetUrl())
+	// the url is checked again as it is burnt, a second submission of the same
+	// link can have got past the check above
+	_, err = r.store.CastBallot(u1.GetUrl(), ballot)
 	if err != nil {
+		err = r.controller.Template.RenderTemplate(c.Response().Writer, struct{ Error string }{Error: err.Error()}, templates.VoteErrorTemplate)
+		if err != nil {
+			return err
+		}
 		return err
 	}
 
@@ -162,3 +196,126 @@ func (r *VoteRepo) AddVote(c echo.Context) error {
 	}
 	return nil
 }
+
+// ballotChoices reads the choices of a ballot from the vote form and whether
+// it abstains, a ballot can't abstain and vote
+func (r *VoteRepo) ballotChoices(election *storage.Election, form url.Values) (map[uint64]string, bool, error) {
+	abstain := len(form.Get("abstain")) > 0
+	switch {
+	case abstain && election.GetBallotType() == voting.BallotTypeMotion:
+		// a motion ballot already has abstain as one of its options
+		if len(form["choice"]) > 0 {
+			return nil, false, fmt.Errorf("you can't abstain and vote for or against the motion on the same ballot")
+		}
+		return map[uint64]string{0: voting.MotionAbstain}, false, nil
+	case abstain:
+		if len(form["choice"]) > 0 || slices.ContainsFunc(slices.Collect(maps.Keys(form)), isRankingKey) {
+			return nil, false, fmt.Errorf("you can't abstain and vote for candidates on the same ballot")
+		}
+		return nil, true, nil
+	case election.GetBallotType() == voting.BallotTypeApproval, election.GetBallotType() == voting.BallotTypePlurality, election.GetBallotType() == voting.BallotTypeMotion:
+		choices, err := r.tickedChoices(election, form["choice"])
+		return choices, false, err
+	default:
+		choices, err := r.rankedChoices(election, form)
+		return choices, false, err
+	}
+}
+
+// tickedChoices checks the candidates ticked on an approval or plurality ballot
+// and stores them in the order they were ticked, which isn't counted
+func (r *VoteRepo) tickedChoices(election *storage.Election, ticked []string) (map[uint64]string, error) {
+	if len(ticked) == 0 {
+		return nil, fmt.Errorf("you need to tick at least one candidate to vote")
+	}
+	if election.GetBallotType() != voting.BallotTypeApproval && len(ticked) > 1 {
+		return nil, fmt.Errorf("you can only tick one candidate")
+	}
+
+	valid, err := r.validChoices(election)
+	if err != nil {
+		return nil, err
+	}
+
+	choices := make(map[uint64]string, len(ticked))
+	for i, id := range ticked {
+		if !valid[id] {
+			return nil, fmt.Errorf("invalid candidate on ballot")
+		}
+		valid[id] = false
+		choices[uint64(i)] = id
+	}
+	return choices, nil
+}
+
+// rankedChoices checks the ranking on a ranked ballot, every position from the
+// first to the last ranked must hold a different candidate standing in the
+// election. Positions left empty after the last ranked are unranked.
+func (r *VoteRepo) rankedChoices(election *storage.Election, form url.Values) (map[uint64]string, error) {
+	choices := make(map[uint64]string)
+	for key, values := range form {
+		if !isRankingKey(key) {
+			continue
+		}
+		position, err := strconv.ParseUint(strings.TrimPrefix(key, rankingKeyPrefix), 10, 64)
+		if err != nil || len(values) != 1 {
+			return nil, fmt.Errorf("invalid ranking position on ballot")
+		}
+		if len(values[0]) == 0 {
+			continue
+		}
+		choices[position] = values[0]
+	}
+	if len(choices) == 0 {
+		return nil, fmt.Errorf("you need to rank at least one candidate to vote, or abstain")
+	}
+	valid, err := r.validChoices(election)
+	if err != nil {
+		return nil, err
+	}
+	ranked := make(map[string]bool, len(choices))
+	for i := uint64(0); i < uint64(len(choices)); i++ {
+		id, ok := choices[i]
+		if !ok {
+			return nil, fmt.Errorf("ranking has a gap at preference %d", i+1)
+		}
+		if ranked[id] {
+			return nil, fmt.Errorf("a candidate is ranked more than once")
+		}
+		if !valid[id] {
+			return nil, fmt.Errorf("invalid candidate on ballot")
+		}
+		ranked[id] = true
+	}
+	return choices, nil
+}
+
+// validChoices returns the ids that can be voted for in the election, withdrawn
+// candidates are false
+func (r *VoteRepo) validChoices(election *storage.Election) (map[string]bool, error) {
+	valid := make(map[string]bool)
+	if election.GetBallotType() == voting.BallotTypeMotion {
+		for _, option := range voting.MotionCandidates() {
+			valid[option.Name] = true
+		}
+		return valid, nil
+	}
+	candidates, err := r.store.GetCandidatesElectionID(election.GetId())
+	if err != nil {
+		return nil, fmt.Errorf("unable to get candidates")
+	}
+	for _, candidate := range candidates {
+		valid[candidate.GetId()] = !candidate.GetWithdrawn()
+	}
+	if election.GetRon() {
+		valid["R.O.N."] = true
+	}
+	return valid, nil
+}
+
+const rankingKeyPrefix = "order~"
+
+// isRankingKey reports whether a form field holds a position on a ranked ballot
+func isRankingKey(key string) bool {
+	return strings.HasPrefix(key, rankingKeyPrefix)
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"errors"
+	"maps"
+	"net/url"
+	"testing"
+
+	"github.com/ystv/stv-web/storage"
+	"github.com/ystv/stv-web/store"
+	"github.com/ystv/stv-web/voting"
+)
+
+// testElection adds an open election with candidates A, B and the withdrawn W
+func testElection(t *testing.T, election *storage.Election) (*store.Store, *storage.Election, map[string]string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	s, err := store.NewStore(false, store.BackendFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	election, err = s.AddElection(election)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]string)
+	for _, name := range []string{"A", "B", "W"} {
+		candidate, err := s.AddCandidate(&storage.Candidate{Election: election.GetId(), Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[name] = candidate.GetId()
+	}
+	if _, err = s.WithdrawCandidate(ids["W"]); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.OpenElection(election.GetId(), 5); err != nil {
+		t.Fatal(err)
+	}
+	election, err = s.FindElection(election.GetId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, election, ids
+}
+
+func TestBallotChoices(t *testing.T) {
+	s, ranked, ids := testElection(t, &storage.Election{Name: "Chair", Seats: 1, Ron: true})
+	motion, err := s.AddElection(&storage.Election{Name: "Motion", Seats: 1, BallotType: voting.BallotTypeMotion})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &VoteRepo{store: s}
+
+	tests := []struct {
+		name     string
+		election *storage.Election
+		form     url.Values
+		choices  map[uint64]string
+		abstain  bool
+		err      bool
+	}{
+		{
+			name:     "ranked",
+			election: ranked,
+			form:     url.Values{"order~0": {ids["B"]}, "order~1": {"R.O.N."}, "order~2": {ids["A"]}},
+			choices:  map[uint64]string{0: ids["B"], 1: "R.O.N.", 2: ids["A"]},
+		},
+		{
+			// positions left blank on the form are unranked
+			name:     "blank positions",
+			election: ranked,
+			form:     url.Values{"order~0": {ids["A"]}, "order~1": {""}},
+			choices:  map[uint64]string{0: ids["A"]},
+		},
+		{name: "gap", election: ranked, form: url.Values{"order~0": {ids["A"]}, "order~2": {ids["B"]}}, err: true},
+		{name: "duplicate", election: ranked, form: url.Values{"order~0": {ids["A"]}, "order~1": {ids["A"]}}, err: true},
+		{name: "unknown candidate", election: ranked, form: url.Values{"order~0": {"unknown"}}, err: true},
+		{name: "withdrawn candidate", election: ranked, form: url.Values{"order~0": {ids["W"]}}, err: true},
+		{name: "bad position", election: ranked, form: url.Values{"order~first": {ids["A"]}}, err: true},
+		{name: "nothing ranked", election: ranked, form: url.Values{"order~0": {""}}, err: true},
+		{name: "abstain", election: ranked, form: url.Values{"abstain": {"on"}}, abstain: true},
+		{name: "abstain and rank", election: ranked, form: url.Values{"abstain": {"on"}, "order~0": {ids["A"]}}, err: true},
+		{
+			// abstaining on a motion is one of its options rather than a blank ballot
+			name:     "motion abstain",
+			election: motion,
+			form:     url.Values{"abstain": {"on"}},
+			choices:  map[uint64]string{0: voting.MotionAbstain},
+		},
+		{name: "motion abstain and vote", election: motion, form: url.Values{"abstain": {"on"}, "choice": {voting.MotionFor}}, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			choices, abstain, err := r.ballotChoices(test.election, test.form)
+			if test.err {
+				if err == nil {
+					t.Fatalf("ballot %v accepted", test.form)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if abstain != test.abstain || !maps.Equal(choices, test.choices) {
+				t.Fatalf("ballot read as %v abstaining %t, want %v abstaining %t", choices, abstain, test.choices, test.abstain)
+			}
+		})
+	}
+}
+
+func TestCountValidAbstainedSpoilt(t *testing.T) {
+	s, election, ids := testElection(t, &storage.Election{Name: "Chair", Seats: 1})
+	for _, ballot := range []*storage.Ballot{
+		{Election: election.GetId(), Choice: map[uint64]string{0: ids["A"]}},
+		{Election: election.GetId(), Choice: map[uint64]string{0: ids["A"], 1: ids["B"]}},
+		{Election: election.GetId(), Choice: map[uint64]string{0: ids["B"]}},
+		{Election: election.GetId(), Abstain: true},
+		// a ranking that names a candidate twice can't be counted
+		{Election: election.GetId(), Choice: map[uint64]string{0: ids["B"], 1: ids["B"]}},
+	} {
+		if _, err := s.AddBallot(ballot); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := &AdminRepo{store: s}
+
+	_, err := r.countElection(election, nil)
+	var invalid *voting.InvalidBallotsError
+	if !errors.As(err, &invalid) || len(invalid.Ballots) != 1 {
+		t.Fatalf("counted with the invalid ballot, got %v", err)
+	}
+
+	election.SetAsideInvalid = true
+	result, err := r.countElection(election, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.GetBallots() != 3 || result.GetAbstained() != 1 || len(result.GetSpoilt()) != 1 {
+		t.Fatalf("%d valid, %d abstained and %d spoilt, want 3, 1 and 1", result.GetBallots(), result.GetAbstained(), len(result.GetSpoilt()))
+	}
+	if winners := result.GetWinners(); len(winners) != 1 || winners[0] != ids["A"] {
+		t.Fatalf("winners %v, want A", winners)
+	}
+}
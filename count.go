@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ystv/stv-web/voting"
+)
+
+// countUsage is printed by stv-web count -h
+const countUsage = `Usage: stv-web count [flags] <ballot file>
+
+Counts a ballot file offline, without the web server or store, and prints each
+round and the winners. The ballot file is one of:
+
+  blt   the BLT format written by the admin export, OpenSTV and OpaVote
+  csv   a ballot per row, candidate names in order of preference
+  json  {"title": "", "seats": 1, "candidates": [""], "ballots": [[""]]}
+
+Use - to read the ballots from stdin.
+
+Flags:
+`
+
+// countBallotFile is the json ballot file format, candidates may be left out
+// and are then taken from the ballots in the order they first appear
+type countBallotFile struct {
+	Title      string     `json:"title"`
+	Seats      uint64     `json:"seats"`
+	Candidates []string   `json:"candidates"`
+	Ballots    [][]string `json:"ballots"`
+}
+
+// countOutput is the json output of a count
+type countOutput struct {
+	Title        string        `json:"title"`
+	Method       string        `json:"method"`
+	Seats        uint64        `json:"seats"`
+	Ballots      int           `json:"ballots"`
+	Quota        string        `json:"quota,omitempty"`
+	QuotaValue   string        `json:"quotaValue,omitempty"`
+	SurplusRule  string        `json:"surplusRule,omitempty"`
+	Arithmetic   string        `json:"arithmetic"`
+	TieBreakSeed string        `json:"tieBreakSeed"`
+	LotOrder     []string      `json:"lotOrder"`
+	Rounds       []*countRound `json:"rounds"`
+	Winners      []string      `json:"winners"`
+}
+
+type countRound struct {
+	Round           int               `json:"round"`
+	Candidates      []*countCandidate `json:"candidates"`
+	BlankVotes      string            `json:"blankVotes"`
+	LossByFractions string            `json:"lossByFractions"`
+	Events          []*countEvent     `json:"events"`
+	TieBreaks       []*countTieBreak  `json:"tieBreaks"`
+}
+
+type countCandidate struct {
+	Name   string `json:"name"`
+	Votes  string `json:"votes"`
+	Status string `json:"status"`
+}
+
+type countEvent struct {
+	Kind       string   `json:"kind"`
+	Candidates []string `json:"candidates"`
+	Votes      string   `json:"votes"`
+}
+
+type countTieBreak struct {
+	Candidates []string `json:"candidates"`
+	Method     string   `json:"method"`
+	Reason     string   `json:"reason"`
+}
+
+// runCount is the count subcommand
+func runCount(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("count", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), countUsage)
+		flags.PrintDefaults()
+	}
+	method := flags.String("method", voting.CountMethodSingleTransferableVote, "counting method: SingleTransferableVote, Meek, Schulze, Approval or Plurality")
+	seats := flags.Uint64("seats", 0, "number of seats, required for csv and overrides the blt and json files")
+	quota := flags.String("quota", voting.QuotaExactDroop, "quota: ExactDroop, Droop, HagenbachBischoff or Hare")
+	surplusRule := flags.String("surplus-rule", voting.SurplusRuleInclusiveGregory, "surplus rule: InclusiveGregory, LastParcel or WeightedInclusiveGregory")
+	arithmetic := flags.String("arithmetic", "", "arithmetic: Exact or FixedDecimal, defaults to FixedDecimal for Meek which needs it and Exact otherwise")
+	decimalPlaces := flags.Uint("decimal-places", voting.DefaultDecimalPlaces, "decimal places for fixed decimal arithmetic")
+	seed := flags.String("seed", "", "tie-break seed, a random seed is drawn and printed if empty")
+	input := flags.String("input", "", "ballot file format: blt, csv or json, taken from the file extension if empty")
+	output := flags.String("output", "text", "output format: text, json or csv")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected a single ballot file")
+	}
+
+	path := flags.Arg(0)
+	format := *input
+	if len(format) == 0 {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	var r io.Reader = stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open ballot file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var election *voting.BLT
+	var err error
+	switch format {
+	case "blt":
+		election, err = voting.ParseBLT(r)
+	case "csv":
+		election, err = readCountCSV(r)
+	case "json":
+		election, err = readCountJSON(r)
+	default:
+		return fmt.Errorf("unknown ballot file format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	if *seats > 0 {
+		election.NumberOfSeats = *seats
+	}
+	if election.NumberOfSeats < 1 {
+		return fmt.Errorf("number of seats must be set with -seats")
+	}
+
+	options := voting.DefaultSingleTransferableVoteOptions()
+	options.Quota = voting.Quota(*quota)
+	options.SurplusRule = voting.SurplusRule(*surplusRule)
+	if len(*arithmetic) == 0 {
+		*arithmetic = voting.ArithmeticExact
+		if *method == voting.CountMethodMeek {
+			*arithmetic = voting.ArithmeticFixedDecimal
+		}
+	}
+	options.Precision = voting.Precision{Arithmetic: voting.Arithmetic(*arithmetic)}
+	if options.Precision.IsFixedDecimal() {
+		if *decimalPlaces > 9 {
+			return fmt.Errorf("number of decimal places must be an integer value between 0 and 9")
+		}
+		options.Precision.DecimalPlaces = *decimalPlaces
+	}
+	options.TieBreakSeed = *seed
+
+	results, err := voting.Count(voting.CountMethod(*method), election.Candidates, election.Ballots, election.NumberOfSeats, options)
+	if err != nil {
+		return fmt.Errorf("count failed: %w", err)
+	}
+
+	out := newCountOutput(election, *method, results)
+	switch *output {
+	case "text":
+		return writeCountText(stdout, out, results)
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	case "csv":
+		return writeCountCSV(stdout, out)
+	default:
+		return fmt.Errorf("unknown output format: %s", *output)
+	}
+}
+
+// readCountCSV reads a ballot per row, empty cells are skipped so spreadsheets
+// with ragged rows can be counted as they are
+func readCountCSV(r io.Reader) (*voting.BLT, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+	return countBallots("", 0, nil, records)
+}
+
+func readCountJSON(r io.Reader) (*voting.BLT, error) {
+	var file countBallotFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to read json: %w", err)
+	}
+	return countBallots(file.Title, file.Seats, file.Candidates, file.Ballots)
+}
+
+// countBallots builds the election from ballots of candidate names, when no
+// candidates are listed they are taken from the ballots
+func countBallots(title string, seats uint64, names []string, rankings [][]string) (*voting.BLT, error) {
+	election := &voting.BLT{Title: title, NumberOfSeats: seats}
+	candidates := make(map[string]*voting.Candidate)
+	addCandidate := func(name string) *voting.Candidate {
+		candidate := voting.NewCandidate(name)
+		candidates[name] = candidate
+		election.Candidates = append(election.Candidates, candidate)
+		return candidate
+	}
+	for _, name := range names {
+		if _, ok := candidates[name]; ok {
+			return nil, fmt.Errorf("duplicate candidate: %s", name)
+		}
+		addCandidate(name)
+	}
+
+	for i, ranking := range rankings {
+		ranked := make([]*voting.Candidate, 0, len(ranking))
+		seen := make(map[string]bool, len(ranking))
+		for _, name := range ranking {
+			name = strings.TrimSpace(name)
+			if len(name) == 0 {
+				continue
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("ballot %d ranks %s more than once", i+1, name)
+			}
+			seen[name] = true
+			candidate, ok := candidates[name]
+			if !ok {
+				if len(names) > 0 {
+					return nil, fmt.Errorf("ballot %d has an unknown candidate: %s", i+1, name)
+				}
+				candidate = addCandidate(name)
+			}
+			ranked = append(ranked, candidate)
+		}
+		ballot, err := voting.NewBallot(ranked)
+		if err != nil {
+			return nil, fmt.Errorf("ballot %d: %w", i+1, err)
+		}
+		election.Ballots = append(election.Ballots, ballot)
+	}
+
+	if len(election.Candidates) == 0 {
+		return nil, fmt.Errorf("ballot file has no candidates")
+	}
+	return election, nil
+}
+
+func newCountOutput(election *voting.BLT, method string, results *voting.ElectionResults) *countOutput {
+	precision := results.Precision
+	out := &countOutput{
+		Title:        election.Title,
+		Method:       method,
+		Seats:        election.NumberOfSeats,
+		Ballots:      len(election.Ballots),
+		Quota:        string(results.Quota),
+		SurplusRule:  string(results.SurplusRule),
+		Arithmetic:   string(precision.Arithmetic),
+		TieBreakSeed: results.TieBreakSeed,
+		LotOrder:     countNames(results.LotOrder),
+		Rounds:       make([]*countRound, 0, len(results.Rounds)),
+		Winners:      countNames(results.GetWinners()),
+	}
+	if len(out.Quota) > 0 {
+		out.QuotaValue = precision.FormatVotes(results.QuotaValue)
+	}
+	for i, round := range results.Rounds {
+		r := &countRound{
+			Round:           i + 1,
+			Candidates:      make([]*countCandidate, 0, len(round.CandidateResults)),
+			BlankVotes:      precision.FormatVotes(round.NumberOfBlankVotes),
+			LossByFractions: precision.FormatVotes(round.LossByFractions),
+			Events:          make([]*countEvent, 0, len(round.Events)),
+			TieBreaks:       make([]*countTieBreak, 0, len(round.TieBreaks)),
+		}
+		for _, result := range round.CandidateResults {
+			r.Candidates = append(r.Candidates, &countCandidate{
+				Name:   result.Candidate.Name,
+				Votes:  precision.FormatVotes(result.NumberOfVotes),
+				Status: string(result.Status),
+			})
+		}
+		for _, event := range round.Events {
+			r.Events = append(r.Events, &countEvent{
+				Kind:       string(event.Kind),
+				Candidates: countNames(event.Candidates),
+				Votes:      precision.FormatVotes(event.Votes),
+			})
+		}
+		for _, tb := range round.TieBreaks {
+			r.TieBreaks = append(r.TieBreaks, &countTieBreak{
+				Candidates: countNames(tb.Candidates),
+				Method:     string(tb.Method),
+				Reason:     string(tb.Reason),
+			})
+		}
+		out.Rounds = append(out.Rounds, r)
+	}
+	return out
+}
+
+func writeCountText(w io.Writer, out *countOutput, results *voting.ElectionResults) error {
+	if len(out.Title) > 0 {
+		fmt.Fprintln(w, out.Title)
+	}
+	fmt.Fprintf(w, "Method: %s, seats: %d, ballots: %d\n", out.Method, out.Seats, out.Ballots)
+	if len(out.Quota) > 0 {
+		fmt.Fprintf(w, "Quota: %s (%s)\n", out.QuotaValue, out.Quota)
+	}
+	if len(out.SurplusRule) > 0 {
+		fmt.Fprintf(w, "Surplus rule: %s\n", out.SurplusRule)
+	}
+	fmt.Fprintf(w, "Arithmetic: %s\n", out.Arithmetic)
+	fmt.Fprintf(w, "Tie-break seed: %s\n", out.TieBreakSeed)
+
+	for i, round := range results.Rounds {
+		fmt.Fprintf(w, "\nRound %d\n%s\n", i+1, round.String())
+		for _, event := range out.Rounds[i].Events {
+			fmt.Fprintf(w, "  %s: %s (%s votes)\n", event.Kind, strings.Join(event.Candidates, " > "), event.Votes)
+		}
+		for _, tb := range out.Rounds[i].TieBreaks {
+			fmt.Fprintf(w, "  tie-break by %s (%s): %s\n", tb.Method, tb.Reason, strings.Join(tb.Candidates, " > "))
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nWinners: %s\n", strings.Join(out.Winners, ", "))
+	return err
+}
+
+// writeCountCSV writes a row for each candidate in each round
+func writeCountCSV(w io.Writer, out *countOutput) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"round", "candidate", "votes", "status"}); err != nil {
+		return err
+	}
+	for _, round := range out.Rounds {
+		for _, candidate := range round.Candidates {
+			if err := writer.Write([]string{strconv.Itoa(round.Round), candidate.Name, candidate.Votes, candidate.Status}); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func countNames(candidates []*voting.Candidate) []string {
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		names[i] = c.Name
+	}
+	return names
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunCount(t *testing.T) {
+	// B and C tie on 2 first preferences, B has the 3 second preferences to
+	// stay in and takes C's ballots past A
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "blt", args: []string{"testdata/count.blt"}, want: []string{"Chair", "Arithmetic: Exact", "Winners: B"}},
+		{name: "csv", args: []string{"-seats", "1", "testdata/count.csv"}, want: []string{"seats: 1", "Winners: B"}},
+		{name: "json", args: []string{"testdata/count.json"}, want: []string{"Chair", "Winners: B"}},
+		// Meek counts in fixed decimal without the arithmetic being given
+		{name: "meek", args: []string{"-method", "Meek", "testdata/count.json"}, want: []string{"Arithmetic: FixedDecimal", "Winners: B"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := runCount(test.args, nil, &out); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("count printed\n%s\nwithout %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunCountRefuses(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "csv without seats", args: []string{"testdata/count.csv"}},
+		{name: "exact Meek", args: []string{"-method", "Meek", "-arithmetic", "Exact", "testdata/count.json"}},
+		{name: "unknown format", args: []string{"-input", "xml", "testdata/count.json"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := runCount(test.args, nil, &out); err == nil {
+				t.Fatalf("counted %v", test.args)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -22,6 +24,17 @@ var (
 )
 
 func main() {
+	// stv-web count verifies a result offline, it needs none of the config below
+	if len(os.Args) > 1 && os.Args[1] == "count" {
+		if err := runCount(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			log.Fatal(err)
+		}
+		return
+	}
+
 	var tomlUsed, local, global bool
 	var err error
 
@@ -96,9 +109,10 @@ func main() {
 		if !tomlUsed {
 			config = structs.Config{
 				Server: structs.Server{
-					Debug:      debug,
-					Address:    os.Getenv("STV_ADDRESS"),
-					DomainName: os.Getenv("STV_DOMAIN_NAME"),
+					Debug:        debug,
+					Address:      os.Getenv("STV_ADDRESS"),
+					DomainName:   os.Getenv("STV_DOMAIN_NAME"),
+					StoreBackend: os.Getenv("STV_STORE_BACKEND"),
 				},
 				AD: structs.AD{
 					BypassUsername: os.Getenv("STV_AD_BYPASS_USERNAME"),
@@ -163,7 +177,7 @@ func main() {
 		log.Println("Debug Mode - Disabled auth - do not run in production!")
 	}
 
-	newStore, err := store.NewStore(root)
+	newStore, err := store.NewStore(root, config.Server.StoreBackend)
 	if err != nil {
 		log.Fatal("Failed to create store: ", err)
 	}
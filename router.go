@@ -85,11 +85,23 @@ func (r *Router) loadRoutes() {
 			election.POST("/include/:id/:email", r.repos.Admin.Include)
 			election.POST("/open/:id", r.repos.Admin.OpenElection)
 			election.POST("/close/:id", r.repos.Admin.CloseElection)
+			election.POST("/seed/:id", r.repos.Admin.SetTieBreakSeed)
+			election.POST("/blt", r.repos.Admin.ImportBLT)
+			election.GET("/blt/:id", r.repos.Admin.ExportBLT)
+			election.POST("/recount/:id", r.repos.Admin.Recount)
+			election.POST("/recount/confirm/:id", r.repos.Admin.ConfirmRecount)
+			election.POST("/countback/:id", r.repos.Admin.Countback)
 			election.POST("/delete/:id", r.repos.Admin.DeleteElection)
+			categories := election.Group("/category")
+			{
+				categories.POST("/:id", r.repos.Admin.AddCategory)
+				categories.POST("/delete/:id", r.repos.Admin.DeleteCategory)
+			}
 			candidates := election.Group("/candidate")
 			{
 				candidates.POST("/:id", r.repos.Admin.AddCandidate)
 				candidates.POST("/delete/:id", r.repos.Admin.DeleteCandidate)
+				candidates.POST("/withdraw/:id", r.repos.Admin.WithdrawCandidate)
 			}
 		}
 		voters := admin.Group("/voters")
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.1
-// 	protoc        v3.20.3
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
 // source: storage.proto
 
 package storage
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -21,25 +22,23 @@ const (
 )
 
 type STV struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Ballots           []*Ballot    `protobuf:"bytes,1,rep,name=ballots,proto3" json:"ballots,omitempty"`
-	Candidates        []*Candidate `protobuf:"bytes,2,rep,name=candidates,proto3" json:"candidates,omitempty"`
-	Elections         []*Election  `protobuf:"bytes,3,rep,name=elections,proto3" json:"elections,omitempty"`
-	Urls              []*URL       `protobuf:"bytes,4,rep,name=urls,proto3" json:"urls,omitempty"`
-	Voters            []*Voter     `protobuf:"bytes,5,rep,name=voters,proto3" json:"voters,omitempty"`
-	AllowRegistration bool         `protobuf:"varint,6,opt,name=allowRegistration,proto3" json:"allowRegistration,omitempty"`
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Ballots           []*Ballot              `protobuf:"bytes,1,rep,name=ballots,proto3" json:"ballots,omitempty"`
+	Candidates        []*Candidate           `protobuf:"bytes,2,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	Elections         []*Election            `protobuf:"bytes,3,rep,name=elections,proto3" json:"elections,omitempty"`
+	Urls              []*URL                 `protobuf:"bytes,4,rep,name=urls,proto3" json:"urls,omitempty"`
+	Voters            []*Voter               `protobuf:"bytes,5,rep,name=voters,proto3" json:"voters,omitempty"`
+	AllowRegistration bool                   `protobuf:"varint,6,opt,name=allowRegistration,proto3" json:"allowRegistration,omitempty"`
+	SchemaVersion     uint64                 `protobuf:"varint,7,opt,name=schemaVersion,proto3" json:"schemaVersion,omitempty"` // version of the schema the state is in, see store.migrations
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *STV) Reset() {
 	*x = STV{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_storage_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_storage_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *STV) String() string {
@@ -50,7 +49,7 @@ func (*STV) ProtoMessage() {}
 
 func (x *STV) ProtoReflect() protoreflect.Message {
 	mi := &file_storage_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -107,34 +106,1368 @@ func (x *STV) GetAllowRegistration() bool {
 	return false
 }
 
-type Ballot struct {
-	state         protoimpl.MessageState
+func (x *STV) GetSchemaVersion() uint64 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+// Snapshot is the state the event log store had after the entry numbered seq
+type Snapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	At            int64                  `protobuf:"varint,2,opt,name=at,proto3" json:"at,omitempty"`               // unix seconds
+	State         *STV                   `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`          // without the ballots in the ballot box
+	BallotBox     uint64                 `protobuf:"varint,4,opt,name=ballotBox,proto3" json:"ballotBox,omitempty"` // generation of the ballot box at the snapshot
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_storage_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Snapshot) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Snapshot) GetAt() int64 {
+	if x != nil {
+		return x.At
+	}
+	return 0
+}
+
+func (x *Snapshot) GetState() *STV {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+func (x *Snapshot) GetBallotBox() uint64 {
+	if x != nil {
+		return x.BallotBox
+	}
+	return 0
+}
+
+// BallotBox is a record of the ballot box of the event log store, the ballots
+// of its open elections. They are kept out of the log until their election
+// closes so the order and time they were written can't match them to the URLs
+// they were cast with. Ballots cast are appended to the box as records of their
+// own, the box is compacted into one record when the log is snapshotted and
+// when ballots leave it.
+type BallotBox struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generation    uint64                 `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"` // the log entry that wrote the record names its generation
+	Ballots       []*Ballot              `protobuf:"bytes,2,rep,name=ballots,proto3" json:"ballots,omitempty"`        // sorted by id
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BallotBox) Reset() {
+	*x = BallotBox{}
+	mi := &file_storage_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BallotBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BallotBox) ProtoMessage() {}
+
+func (x *BallotBox) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BallotBox.ProtoReflect.Descriptor instead.
+func (*BallotBox) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BallotBox) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *BallotBox) GetBallots() []*Ballot {
+	if x != nil {
+		return x.Ballots
+	}
+	return nil
+}
+
+// LogEntry is the events of one change to the event log store, an entry is
+// replayed whole or not at all
+type LogEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	At            int64                  `protobuf:"varint,2,opt,name=at,proto3" json:"at,omitempty"` // unix seconds
+	Events        []*Event               `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	mi := &file_storage_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEntry) ProtoMessage() {}
+
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LogEntry) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *LogEntry) GetAt() int64 {
+	if x != nil {
+		return x.At
+	}
+	return 0
+}
+
+func (x *LogEntry) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // one of the store.EventKind values
+	// Types that are valid to be assigned to Record:
+	//
+	//	*Event_Ballot
+	//	*Event_Candidate
+	//	*Event_Election
+	//	*Event_Url
+	//	*Event_Voter
+	//	*Event_AllowRegistration
+	//	*Event_SchemaVersion
+	//	*Event_BallotBox
+	Record        isEvent_Record `protobuf_oneof:"record"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_storage_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Event) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Event) GetRecord() isEvent_Record {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+func (x *Event) GetBallot() *Ballot {
+	if x != nil {
+		if x, ok := x.Record.(*Event_Ballot); ok {
+			return x.Ballot
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetCandidate() *Candidate {
+	if x != nil {
+		if x, ok := x.Record.(*Event_Candidate); ok {
+			return x.Candidate
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetElection() *Election {
+	if x != nil {
+		if x, ok := x.Record.(*Event_Election); ok {
+			return x.Election
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetUrl() *URL {
+	if x != nil {
+		if x, ok := x.Record.(*Event_Url); ok {
+			return x.Url
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetVoter() *Voter {
+	if x != nil {
+		if x, ok := x.Record.(*Event_Voter); ok {
+			return x.Voter
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAllowRegistration() bool {
+	if x != nil {
+		if x, ok := x.Record.(*Event_AllowRegistration); ok {
+			return x.AllowRegistration
+		}
+	}
+	return false
+}
+
+func (x *Event) GetSchemaVersion() uint64 {
+	if x != nil {
+		if x, ok := x.Record.(*Event_SchemaVersion); ok {
+			return x.SchemaVersion
+		}
+	}
+	return 0
+}
+
+func (x *Event) GetBallotBox() uint64 {
+	if x != nil {
+		if x, ok := x.Record.(*Event_BallotBox); ok {
+			return x.BallotBox
+		}
+	}
+	return 0
+}
+
+type isEvent_Record interface {
+	isEvent_Record()
+}
+
+type Event_Ballot struct {
+	Ballot *Ballot `protobuf:"bytes,2,opt,name=ballot,proto3,oneof"`
+}
+
+type Event_Candidate struct {
+	Candidate *Candidate `protobuf:"bytes,3,opt,name=candidate,proto3,oneof"`
+}
+
+type Event_Election struct {
+	Election *Election `protobuf:"bytes,4,opt,name=election,proto3,oneof"`
+}
+
+type Event_Url struct {
+	Url *URL `protobuf:"bytes,5,opt,name=url,proto3,oneof"`
+}
 
-	Id       string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Election string            `protobuf:"bytes,2,opt,name=election,proto3" json:"election,omitempty"`
-	Choice   map[uint64]string `protobuf:"bytes,3,rep,name=choice,proto3" json:"choice,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"` // map[order, candidate id]
+type Event_Voter struct {
+	Voter *Voter `protobuf:"bytes,6,opt,name=voter,proto3,oneof"`
+}
+
+type Event_AllowRegistration struct {
+	AllowRegistration bool `protobuf:"varint,7,opt,name=allowRegistration,proto3,oneof"`
+}
+
+type Event_SchemaVersion struct {
+	SchemaVersion uint64 `protobuf:"varint,8,opt,name=schemaVersion,proto3,oneof"`
+}
+
+type Event_BallotBox struct {
+	BallotBox uint64 `protobuf:"varint,9,opt,name=ballotBox,proto3,oneof"` // generation of the ballot box written with the entry
+}
+
+func (*Event_Ballot) isEvent_Record() {}
+
+func (*Event_Candidate) isEvent_Record() {}
+
+func (*Event_Election) isEvent_Record() {}
+
+func (*Event_Url) isEvent_Record() {}
+
+func (*Event_Voter) isEvent_Record() {}
+
+func (*Event_AllowRegistration) isEvent_Record() {}
+
+func (*Event_SchemaVersion) isEvent_Record() {}
+
+func (*Event_BallotBox) isEvent_Record() {}
+
+type Ballot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Election      string                 `protobuf:"bytes,2,opt,name=election,proto3" json:"election,omitempty"`
+	Choice        map[uint64]string      `protobuf:"bytes,3,rep,name=choice,proto3" json:"choice,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // map[order, candidate id]
+	Weight        string                 `protobuf:"bytes,4,opt,name=weight,proto3" json:"weight,omitempty"`                                                                            // weight of the voter who cast it, an exact fraction, empty is 1
+	Abstain       bool                   `protobuf:"varint,5,opt,name=abstain,proto3" json:"abstain,omitempty"`                                                                         // the voter chose to abstain, choice is empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Ballot) Reset() {
 	*x = Ballot{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_storage_proto_msgTypes[1]
+	mi := &file_storage_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ballot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ballot) ProtoMessage() {}
+
+func (x *Ballot) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[5]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ballot.ProtoReflect.Descriptor instead.
+func (*Ballot) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Ballot) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Ballot) GetElection() string {
+	if x != nil {
+		return x.Election
+	}
+	return ""
+}
+
+func (x *Ballot) GetChoice() map[uint64]string {
+	if x != nil {
+		return x.Choice
+	}
+	return nil
+}
+
+func (x *Ballot) GetWeight() string {
+	if x != nil {
+		return x.Weight
+	}
+	return ""
+}
+
+func (x *Ballot) GetAbstain() bool {
+	if x != nil {
+		return x.Abstain
+	}
+	return false
+}
+
+type Candidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Election      string                 `protobuf:"bytes,2,opt,name=election,proto3" json:"election,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Withdrawn     bool                   `protobuf:"varint,4,opt,name=withdrawn,proto3" json:"withdrawn,omitempty"`  // stood down after voting opened, kept on ballots but excluded from the count
+	Categories    []string               `protobuf:"bytes,5,rep,name=categories,proto3" json:"categories,omitempty"` // names of the election categories the candidate is in
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candidate) Reset() {
+	*x = Candidate{}
+	mi := &file_storage_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candidate) ProtoMessage() {}
+
+func (x *Candidate) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candidate.ProtoReflect.Descriptor instead.
+func (*Candidate) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Candidate) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Candidate) GetElection() string {
+	if x != nil {
+		return x.Election
+	}
+	return ""
+}
+
+func (x *Candidate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Candidate) GetWithdrawn() bool {
+	if x != nil {
+		return x.Withdrawn
+	}
+	return false
+}
+
+func (x *Candidate) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+type Election struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Ron         bool                   `protobuf:"varint,4,opt,name=ron,proto3" json:"ron,omitempty"`
+	Seats       uint64                 `protobuf:"varint,5,opt,name=seats,proto3" json:"seats,omitempty"`
+	Open        bool                   `protobuf:"varint,6,opt,name=open,proto3" json:"open,omitempty"`
+	Closed      bool                   `protobuf:"varint,7,opt,name=closed,proto3" json:"closed,omitempty"`
+	Result      *Result                `protobuf:"bytes,8,opt,name=result,proto3" json:"result,omitempty"`
+	// Deprecated: Marked as deprecated in storage.proto.
+	Excluded         []*Voter     `protobuf:"bytes,9,rep,name=excluded,proto3" json:"excluded,omitempty"` // copies of the excluded voters before schema version 1, moved to excludedVoters
+	Voters           uint64       `protobuf:"varint,10,opt,name=voters,proto3" json:"voters,omitempty"`
+	Method           string       `protobuf:"bytes,11,opt,name=method,proto3" json:"method,omitempty"`                      // counting method, one of the voting.CountMethod values
+	Arithmetic       string       `protobuf:"bytes,12,opt,name=arithmetic,proto3" json:"arithmetic,omitempty"`              // one of the voting.Arithmetic values
+	DecimalPlaces    uint64       `protobuf:"varint,13,opt,name=decimalPlaces,proto3" json:"decimalPlaces,omitempty"`       // only used by fixed decimal arithmetic
+	TieBreakSeed     string       `protobuf:"bytes,14,opt,name=tieBreakSeed,proto3" json:"tieBreakSeed,omitempty"`          // committed to by the returning officer before close
+	Quota            string       `protobuf:"bytes,15,opt,name=quota,proto3" json:"quota,omitempty"`                        // one of the voting.Quota values
+	SurplusRule      string       `protobuf:"bytes,16,opt,name=surplusRule,proto3" json:"surplusRule,omitempty"`            // one of the voting.SurplusRule values
+	BallotType       string       `protobuf:"bytes,17,opt,name=ballotType,proto3" json:"ballotType,omitempty"`              // one of the voting.BallotType values, empty is ranked
+	Threshold        string       `protobuf:"bytes,18,opt,name=threshold,proto3" json:"threshold,omitempty"`                // one of the voting.MotionThreshold values, only for motions
+	Quorum           uint64       `protobuf:"varint,19,opt,name=quorum,proto3" json:"quorum,omitempty"`                     // ballots needed for a motion to be decided, 0 for no quorum
+	Imported         bool         `protobuf:"varint,20,opt,name=imported,proto3" json:"imported,omitempty"`                 // ballots were imported from a BLT file rather than cast by voters
+	CompareMethod    string       `protobuf:"bytes,21,opt,name=compareMethod,proto3" json:"compareMethod,omitempty"`        // one of the voting.CompareMethod values, decides ties between candidates to exclude
+	Countbacks       []*Countback `protobuf:"bytes,22,rep,name=countbacks,proto3" json:"countbacks,omitempty"`              // casual vacancies filled after the result was published
+	Categories       []*Category  `protobuf:"bytes,23,rep,name=categories,proto3" json:"categories,omitempty"`              // fewest and most seats groups of candidates can fill
+	BatchElimination bool         `protobuf:"varint,24,opt,name=batchElimination,proto3" json:"batchElimination,omitempty"` // exclude every candidate who can't catch the one above together, only SingleTransferableVote
+	SetAsideInvalid  bool         `protobuf:"varint,25,opt,name=setAsideInvalid,proto3" json:"setAsideInvalid,omitempty"`   // count the valid ballots and report the rest as spoilt rather than failing to close
+	ExcludedVoters   []string     `protobuf:"bytes,26,rep,name=excludedVoters,proto3" json:"excludedVoters,omitempty"`      // emails of the voters who can't vote in this election
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Election) Reset() {
+	*x = Election{}
+	mi := &file_storage_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Election) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Election) ProtoMessage() {}
+
+func (x *Election) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Election.ProtoReflect.Descriptor instead.
+func (*Election) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Election) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Election) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Election) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Election) GetRon() bool {
+	if x != nil {
+		return x.Ron
+	}
+	return false
+}
+
+func (x *Election) GetSeats() uint64 {
+	if x != nil {
+		return x.Seats
+	}
+	return 0
+}
+
+func (x *Election) GetOpen() bool {
+	if x != nil {
+		return x.Open
+	}
+	return false
+}
+
+func (x *Election) GetClosed() bool {
+	if x != nil {
+		return x.Closed
+	}
+	return false
+}
+
+func (x *Election) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// Deprecated: Marked as deprecated in storage.proto.
+func (x *Election) GetExcluded() []*Voter {
+	if x != nil {
+		return x.Excluded
+	}
+	return nil
+}
+
+func (x *Election) GetVoters() uint64 {
+	if x != nil {
+		return x.Voters
+	}
+	return 0
+}
+
+func (x *Election) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *Election) GetArithmetic() string {
+	if x != nil {
+		return x.Arithmetic
+	}
+	return ""
+}
+
+func (x *Election) GetDecimalPlaces() uint64 {
+	if x != nil {
+		return x.DecimalPlaces
+	}
+	return 0
+}
+
+func (x *Election) GetTieBreakSeed() string {
+	if x != nil {
+		return x.TieBreakSeed
+	}
+	return ""
+}
+
+func (x *Election) GetQuota() string {
+	if x != nil {
+		return x.Quota
+	}
+	return ""
+}
+
+func (x *Election) GetSurplusRule() string {
+	if x != nil {
+		return x.SurplusRule
+	}
+	return ""
+}
+
+func (x *Election) GetBallotType() string {
+	if x != nil {
+		return x.BallotType
+	}
+	return ""
+}
+
+func (x *Election) GetThreshold() string {
+	if x != nil {
+		return x.Threshold
+	}
+	return ""
+}
+
+func (x *Election) GetQuorum() uint64 {
+	if x != nil {
+		return x.Quorum
+	}
+	return 0
+}
+
+func (x *Election) GetImported() bool {
+	if x != nil {
+		return x.Imported
+	}
+	return false
+}
+
+func (x *Election) GetCompareMethod() string {
+	if x != nil {
+		return x.CompareMethod
+	}
+	return ""
+}
+
+func (x *Election) GetCountbacks() []*Countback {
+	if x != nil {
+		return x.Countbacks
+	}
+	return nil
+}
+
+func (x *Election) GetCategories() []*Category {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+func (x *Election) GetBatchElimination() bool {
+	if x != nil {
+		return x.BatchElimination
+	}
+	return false
+}
+
+func (x *Election) GetSetAsideInvalid() bool {
+	if x != nil {
+		return x.SetAsideInvalid
+	}
+	return false
+}
+
+func (x *Election) GetExcludedVoters() []string {
+	if x != nil {
+		return x.ExcludedVoters
+	}
+	return nil
+}
+
+type Category struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Min           uint64                 `protobuf:"varint,2,opt,name=min,proto3" json:"min,omitempty"`
+	Max           uint64                 `protobuf:"varint,3,opt,name=max,proto3" json:"max,omitempty"` // 0 for no maximum
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Category) Reset() {
+	*x = Category{}
+	mi := &file_storage_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Category) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Category) ProtoMessage() {}
+
+func (x *Category) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Category.ProtoReflect.Descriptor instead.
+func (*Category) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Category) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Category) GetMin() uint64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *Category) GetMax() uint64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+type Countback struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vacating      string                 `protobuf:"bytes,1,opt,name=vacating,proto3" json:"vacating,omitempty"` // candidate id of the winner whose seat was filled
+	Standing      []string               `protobuf:"bytes,2,rep,name=standing,proto3" json:"standing,omitempty"` // candidate ids who agreed to stand
+	Winner        string                 `protobuf:"bytes,3,opt,name=winner,proto3" json:"winner,omitempty"`
+	Ballots       uint64                 `protobuf:"varint,4,opt,name=ballots,proto3" json:"ballots,omitempty"`     // ballots the vacating winner held when elected
+	Votes         string                 `protobuf:"bytes,5,opt,name=votes,proto3" json:"votes,omitempty"`          // the value of those ballots, each is counted again at its value
+	Result        *Result                `protobuf:"bytes,6,opt,name=result,proto3" json:"result,omitempty"`        // rounds of the countback
+	CountedAt     int64                  `protobuf:"varint,7,opt,name=countedAt,proto3" json:"countedAt,omitempty"` // unix seconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Countback) Reset() {
+	*x = Countback{}
+	mi := &file_storage_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Countback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Countback) ProtoMessage() {}
+
+func (x *Countback) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Countback.ProtoReflect.Descriptor instead.
+func (*Countback) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Countback) GetVacating() string {
+	if x != nil {
+		return x.Vacating
+	}
+	return ""
+}
+
+func (x *Countback) GetStanding() []string {
+	if x != nil {
+		return x.Standing
+	}
+	return nil
+}
+
+func (x *Countback) GetWinner() string {
+	if x != nil {
+		return x.Winner
+	}
+	return ""
+}
+
+func (x *Countback) GetBallots() uint64 {
+	if x != nil {
+		return x.Ballots
+	}
+	return 0
+}
+
+func (x *Countback) GetVotes() string {
+	if x != nil {
+		return x.Votes
+	}
+	return ""
+}
+
+func (x *Countback) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *Countback) GetCountedAt() int64 {
+	if x != nil {
+		return x.CountedAt
+	}
+	return 0
+}
+
+type Result struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Rounds             uint64                 `protobuf:"varint,1,opt,name=rounds,proto3" json:"rounds,omitempty"`
+	Winners            []string               `protobuf:"bytes,2,rep,name=winners,proto3" json:"winners,omitempty"`
+	Round              []*Round               `protobuf:"bytes,3,rep,name=round,proto3" json:"round,omitempty"`
+	Method             string                 `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	Arithmetic         string                 `protobuf:"bytes,5,opt,name=arithmetic,proto3" json:"arithmetic,omitempty"`
+	DecimalPlaces      uint64                 `protobuf:"varint,6,opt,name=decimalPlaces,proto3" json:"decimalPlaces,omitempty"`
+	TieBreakSeed       string                 `protobuf:"bytes,7,opt,name=tieBreakSeed,proto3" json:"tieBreakSeed,omitempty"` // seed every random decision was drawn from
+	LotOrder           []string               `protobuf:"bytes,8,rep,name=lotOrder,proto3" json:"lotOrder,omitempty"`         // candidate ids in the order ties by lot are decided
+	Quota              string                 `protobuf:"bytes,9,opt,name=quota,proto3" json:"quota,omitempty"`
+	QuotaValue         string                 `protobuf:"bytes,10,opt,name=quotaValue,proto3" json:"quotaValue,omitempty"`                 // votes needed to be elected, in Meek the quota of the last round
+	SurplusRule        string                 `protobuf:"bytes,11,opt,name=surplusRule,proto3" json:"surplusRule,omitempty"`               // empty for Meek
+	PairwiseCandidates []string               `protobuf:"bytes,12,rep,name=pairwiseCandidates,proto3" json:"pairwiseCandidates,omitempty"` // candidate ids of the Schulze table rows and columns
+	Preferences        []*PairwiseRow         `protobuf:"bytes,13,rep,name=preferences,proto3" json:"preferences,omitempty"`               // voters ranking the row candidate above the column candidate
+	StrongestPaths     []*PairwiseRow         `protobuf:"bytes,14,rep,name=strongestPaths,proto3" json:"strongestPaths,omitempty"`
+	Motion             *MotionResult          `protobuf:"bytes,15,opt,name=motion,proto3" json:"motion,omitempty"` // set instead of rounds and winners for motions
+	CompareMethod      string                 `protobuf:"bytes,16,opt,name=compareMethod,proto3" json:"compareMethod,omitempty"`
+	Withdrawn          []string               `protobuf:"bytes,17,rep,name=withdrawn,proto3" json:"withdrawn,omitempty"`             // candidate ids left out of a recount
+	Ballots            uint64                 `protobuf:"varint,18,opt,name=ballots,proto3" json:"ballots,omitempty"`                // ballots counted
+	WeightedBallots    string                 `protobuf:"bytes,19,opt,name=weightedBallots,proto3" json:"weightedBallots,omitempty"` // value the ballots were counted at, empty unless voters are weighted
+	BatchElimination   bool                   `protobuf:"varint,20,opt,name=batchElimination,proto3" json:"batchElimination,omitempty"`
+	Spoilt             []*SpoiltBallot        `protobuf:"bytes,21,rep,name=spoilt,proto3" json:"spoilt,omitempty"`        // invalid ballots set aside and not counted
+	Abstained          uint64                 `protobuf:"varint,22,opt,name=abstained,proto3" json:"abstained,omitempty"` // ballots cast as abstentions, not counted
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_storage_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Result) GetRounds() uint64 {
+	if x != nil {
+		return x.Rounds
+	}
+	return 0
+}
+
+func (x *Result) GetWinners() []string {
+	if x != nil {
+		return x.Winners
+	}
+	return nil
+}
+
+func (x *Result) GetRound() []*Round {
+	if x != nil {
+		return x.Round
+	}
+	return nil
+}
+
+func (x *Result) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *Result) GetArithmetic() string {
+	if x != nil {
+		return x.Arithmetic
+	}
+	return ""
+}
+
+func (x *Result) GetDecimalPlaces() uint64 {
+	if x != nil {
+		return x.DecimalPlaces
+	}
+	return 0
+}
+
+func (x *Result) GetTieBreakSeed() string {
+	if x != nil {
+		return x.TieBreakSeed
+	}
+	return ""
+}
+
+func (x *Result) GetLotOrder() []string {
+	if x != nil {
+		return x.LotOrder
+	}
+	return nil
+}
+
+func (x *Result) GetQuota() string {
+	if x != nil {
+		return x.Quota
+	}
+	return ""
+}
+
+func (x *Result) GetQuotaValue() string {
+	if x != nil {
+		return x.QuotaValue
+	}
+	return ""
+}
+
+func (x *Result) GetSurplusRule() string {
+	if x != nil {
+		return x.SurplusRule
+	}
+	return ""
+}
+
+func (x *Result) GetPairwiseCandidates() []string {
+	if x != nil {
+		return x.PairwiseCandidates
+	}
+	return nil
+}
+
+func (x *Result) GetPreferences() []*PairwiseRow {
+	if x != nil {
+		return x.Preferences
+	}
+	return nil
+}
+
+func (x *Result) GetStrongestPaths() []*PairwiseRow {
+	if x != nil {
+		return x.StrongestPaths
+	}
+	return nil
+}
+
+func (x *Result) GetMotion() *MotionResult {
+	if x != nil {
+		return x.Motion
+	}
+	return nil
+}
+
+func (x *Result) GetCompareMethod() string {
+	if x != nil {
+		return x.CompareMethod
+	}
+	return ""
+}
+
+func (x *Result) GetWithdrawn() []string {
+	if x != nil {
+		return x.Withdrawn
+	}
+	return nil
+}
+
+func (x *Result) GetBallots() uint64 {
+	if x != nil {
+		return x.Ballots
+	}
+	return 0
+}
+
+func (x *Result) GetWeightedBallots() string {
+	if x != nil {
+		return x.WeightedBallots
+	}
+	return ""
+}
+
+func (x *Result) GetBatchElimination() bool {
+	if x != nil {
+		return x.BatchElimination
+	}
+	return false
+}
+
+func (x *Result) GetSpoilt() []*SpoiltBallot {
+	if x != nil {
+		return x.Spoilt
+	}
+	return nil
+}
+
+func (x *Result) GetAbstained() uint64 {
+	if x != nil {
+		return x.Abstained
+	}
+	return 0
+}
+
+type SpoiltBallot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ballot        string                 `protobuf:"bytes,1,opt,name=ballot,proto3" json:"ballot,omitempty"` // ballot id
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpoiltBallot) Reset() {
+	*x = SpoiltBallot{}
+	mi := &file_storage_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpoiltBallot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpoiltBallot) ProtoMessage() {}
+
+func (x *SpoiltBallot) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpoiltBallot.ProtoReflect.Descriptor instead.
+func (*SpoiltBallot) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SpoiltBallot) GetBallot() string {
+	if x != nil {
+		return x.Ballot
+	}
+	return ""
+}
+
+func (x *SpoiltBallot) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type MotionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VotesFor      uint64                 `protobuf:"varint,1,opt,name=votesFor,proto3" json:"votesFor,omitempty"`
+	VotesAgainst  uint64                 `protobuf:"varint,2,opt,name=votesAgainst,proto3" json:"votesAgainst,omitempty"`
+	Abstentions   uint64                 `protobuf:"varint,3,opt,name=abstentions,proto3" json:"abstentions,omitempty"`
+	Threshold     string                 `protobuf:"bytes,4,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Quorum        uint64                 `protobuf:"varint,5,opt,name=quorum,proto3" json:"quorum,omitempty"`
+	QuorumReached bool                   `protobuf:"varint,6,opt,name=quorumReached,proto3" json:"quorumReached,omitempty"`
+	Passed        bool                   `protobuf:"varint,7,opt,name=passed,proto3" json:"passed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MotionResult) Reset() {
+	*x = MotionResult{}
+	mi := &file_storage_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MotionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MotionResult) ProtoMessage() {}
+
+func (x *MotionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MotionResult.ProtoReflect.Descriptor instead.
+func (*MotionResult) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MotionResult) GetVotesFor() uint64 {
+	if x != nil {
+		return x.VotesFor
+	}
+	return 0
+}
+
+func (x *MotionResult) GetVotesAgainst() uint64 {
+	if x != nil {
+		return x.VotesAgainst
+	}
+	return 0
+}
+
+func (x *MotionResult) GetAbstentions() uint64 {
+	if x != nil {
+		return x.Abstentions
+	}
+	return 0
+}
+
+func (x *MotionResult) GetThreshold() string {
+	if x != nil {
+		return x.Threshold
+	}
+	return ""
+}
+
+func (x *MotionResult) GetQuorum() uint64 {
+	if x != nil {
+		return x.Quorum
+	}
+	return 0
+}
+
+func (x *MotionResult) GetQuorumReached() bool {
+	if x != nil {
+		return x.QuorumReached
+	}
+	return false
+}
+
+func (x *MotionResult) GetPassed() bool {
+	if x != nil {
+		return x.Passed
+	}
+	return false
+}
+
+type PairwiseRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Counts        []uint64               `protobuf:"varint,1,rep,packed,name=counts,proto3" json:"counts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PairwiseRow) Reset() {
+	*x = PairwiseRow{}
+	mi := &file_storage_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PairwiseRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PairwiseRow) ProtoMessage() {}
+
+func (x *PairwiseRow) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PairwiseRow.ProtoReflect.Descriptor instead.
+func (*PairwiseRow) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PairwiseRow) GetCounts() []uint64 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+type Round struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Round           uint64                 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Blanks          uint64                 `protobuf:"varint,2,opt,name=blanks,proto3" json:"blanks,omitempty"`
+	CandidateStatus []*CandidateStatus     `protobuf:"bytes,3,rep,name=candidateStatus,proto3" json:"candidateStatus,omitempty"`
+	BlankVotes      string                 `protobuf:"bytes,4,opt,name=blankVotes,proto3" json:"blankVotes,omitempty"` // lossless form of blanks
+	LossByFractions string                 `protobuf:"bytes,5,opt,name=lossByFractions,proto3" json:"lossByFractions,omitempty"`
+	TieBreaks       []*TieBreak            `protobuf:"bytes,6,rep,name=tieBreaks,proto3" json:"tieBreaks,omitempty"`
+	Events          []*RoundEvent          `protobuf:"bytes,7,rep,name=events,proto3" json:"events,omitempty"`       // why candidates were elected or excluded this round
+	Transfers       []*Transfer            `protobuf:"bytes,8,rep,name=transfers,proto3" json:"transfers,omitempty"` // transfers made since the previous round
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Round) Reset() {
+	*x = Round{}
+	mi := &file_storage_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Ballot) String() string {
+func (x *Round) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Ballot) ProtoMessage() {}
+func (*Round) ProtoMessage() {}
 
-func (x *Ballot) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *Round) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[14]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -144,130 +1477,97 @@ func (x *Ballot) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Ballot.ProtoReflect.Descriptor instead.
-func (*Ballot) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use Round.ProtoReflect.Descriptor instead.
+func (*Round) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *Ballot) GetId() string {
+func (x *Round) GetRound() uint64 {
 	if x != nil {
-		return x.Id
+		return x.Round
 	}
-	return ""
+	return 0
 }
 
-func (x *Ballot) GetElection() string {
+func (x *Round) GetBlanks() uint64 {
 	if x != nil {
-		return x.Election
+		return x.Blanks
 	}
-	return ""
+	return 0
 }
 
-func (x *Ballot) GetChoice() map[uint64]string {
+func (x *Round) GetCandidateStatus() []*CandidateStatus {
 	if x != nil {
-		return x.Choice
+		return x.CandidateStatus
 	}
 	return nil
 }
 
-type Candidate struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Id       string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Election string `protobuf:"bytes,2,opt,name=election,proto3" json:"election,omitempty"`
-	Name     string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
-}
-
-func (x *Candidate) Reset() {
-	*x = Candidate{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_storage_proto_msgTypes[2]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *Round) GetBlankVotes() string {
+	if x != nil {
+		return x.BlankVotes
 	}
+	return ""
 }
 
-func (x *Candidate) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*Candidate) ProtoMessage() {}
-
-func (x *Candidate) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *Round) GetLossByFractions() string {
+	if x != nil {
+		return x.LossByFractions
 	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use Candidate.ProtoReflect.Descriptor instead.
-func (*Candidate) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{2}
+	return ""
 }
 
-func (x *Candidate) GetId() string {
+func (x *Round) GetTieBreaks() []*TieBreak {
 	if x != nil {
-		return x.Id
+		return x.TieBreaks
 	}
-	return ""
+	return nil
 }
 
-func (x *Candidate) GetElection() string {
+func (x *Round) GetEvents() []*RoundEvent {
 	if x != nil {
-		return x.Election
+		return x.Events
 	}
-	return ""
+	return nil
 }
 
-func (x *Candidate) GetName() string {
+func (x *Round) GetTransfers() []*Transfer {
 	if x != nil {
-		return x.Name
+		return x.Transfers
 	}
-	return ""
+	return nil
 }
 
-type Election struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Id          string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name        string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description string   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Ron         bool     `protobuf:"varint,4,opt,name=ron,proto3" json:"ron,omitempty"`
-	Seats       uint64   `protobuf:"varint,5,opt,name=seats,proto3" json:"seats,omitempty"`
-	Open        bool     `protobuf:"varint,6,opt,name=open,proto3" json:"open,omitempty"`
-	Closed      bool     `protobuf:"varint,7,opt,name=closed,proto3" json:"closed,omitempty"`
-	Result      *Result  `protobuf:"bytes,8,opt,name=result,proto3" json:"result,omitempty"`
-	Excluded    []*Voter `protobuf:"bytes,9,rep,name=excluded,proto3" json:"excluded,omitempty"`
-	Voters      uint64   `protobuf:"varint,10,opt,name=voters,proto3" json:"voters,omitempty"`
+type Transfer struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	From             string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`   // candidate id
+	Kind             string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`   // one of the voting.TransferKind values
+	Value            string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"` // value carried on by each ballot
+	To               []*TransferAmount      `protobuf:"bytes,4,rep,name=to,proto3" json:"to,omitempty"`
+	ExhaustedBallots uint64                 `protobuf:"varint,5,opt,name=exhaustedBallots,proto3" json:"exhaustedBallots,omitempty"`
+	ExhaustedVotes   string                 `protobuf:"bytes,6,opt,name=exhaustedVotes,proto3" json:"exhaustedVotes,omitempty"`
+	LossByFractions  string                 `protobuf:"bytes,7,opt,name=lossByFractions,proto3" json:"lossByFractions,omitempty"`
+	Weighted         bool                   `protobuf:"varint,8,opt,name=weighted,proto3" json:"weighted,omitempty"` // value is the fraction of each ballot's value carried on
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *Election) Reset() {
-	*x = Election{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_storage_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *Transfer) Reset() {
+	*x = Transfer{}
+	mi := &file_storage_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Election) String() string {
+func (x *Transfer) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Election) ProtoMessage() {}
+func (*Transfer) ProtoMessage() {}
 
-func (x *Election) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *Transfer) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[15]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -277,109 +1577,153 @@ func (x *Election) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Election.ProtoReflect.Descriptor instead.
-func (*Election) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use Transfer.ProtoReflect.Descriptor instead.
+func (*Transfer) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *Election) GetId() string {
+func (x *Transfer) GetFrom() string {
 	if x != nil {
-		return x.Id
+		return x.From
 	}
 	return ""
 }
 
-func (x *Election) GetName() string {
+func (x *Transfer) GetKind() string {
 	if x != nil {
-		return x.Name
+		return x.Kind
 	}
 	return ""
 }
 
-func (x *Election) GetDescription() string {
+func (x *Transfer) GetValue() string {
 	if x != nil {
-		return x.Description
+		return x.Value
 	}
 	return ""
 }
 
-func (x *Election) GetRon() bool {
+func (x *Transfer) GetTo() []*TransferAmount {
 	if x != nil {
-		return x.Ron
+		return x.To
 	}
-	return false
+	return nil
 }
 
-func (x *Election) GetSeats() uint64 {
+func (x *Transfer) GetExhaustedBallots() uint64 {
 	if x != nil {
-		return x.Seats
+		return x.ExhaustedBallots
 	}
 	return 0
 }
 
-func (x *Election) GetOpen() bool {
+func (x *Transfer) GetExhaustedVotes() string {
 	if x != nil {
-		return x.Open
+		return x.ExhaustedVotes
 	}
-	return false
+	return ""
 }
 
-func (x *Election) GetClosed() bool {
+func (x *Transfer) GetLossByFractions() string {
 	if x != nil {
-		return x.Closed
+		return x.LossByFractions
+	}
+	return ""
+}
+
+func (x *Transfer) GetWeighted() bool {
+	if x != nil {
+		return x.Weighted
 	}
 	return false
 }
 
-func (x *Election) GetResult() *Result {
+type TransferAmount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Candidate     string                 `protobuf:"bytes,1,opt,name=candidate,proto3" json:"candidate,omitempty"` // candidate id
+	Ballots       uint64                 `protobuf:"varint,2,opt,name=ballots,proto3" json:"ballots,omitempty"`
+	Votes         string                 `protobuf:"bytes,3,opt,name=votes,proto3" json:"votes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferAmount) Reset() {
+	*x = TransferAmount{}
+	mi := &file_storage_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferAmount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferAmount) ProtoMessage() {}
+
+func (x *TransferAmount) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[16]
 	if x != nil {
-		return x.Result
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *Election) GetExcluded() []*Voter {
+// Deprecated: Use TransferAmount.ProtoReflect.Descriptor instead.
+func (*TransferAmount) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TransferAmount) GetCandidate() string {
 	if x != nil {
-		return x.Excluded
+		return x.Candidate
 	}
-	return nil
+	return ""
 }
 
-func (x *Election) GetVoters() uint64 {
+func (x *TransferAmount) GetBallots() uint64 {
 	if x != nil {
-		return x.Voters
+		return x.Ballots
 	}
 	return 0
 }
 
-type Result struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *TransferAmount) GetVotes() string {
+	if x != nil {
+		return x.Votes
+	}
+	return ""
+}
 
-	Rounds  uint64   `protobuf:"varint,1,opt,name=rounds,proto3" json:"rounds,omitempty"`
-	Winners []string `protobuf:"bytes,2,rep,name=winners,proto3" json:"winners,omitempty"`
-	Round   []*Round `protobuf:"bytes,3,rep,name=round,proto3" json:"round,omitempty"`
+type RoundEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`             // one of the voting.RoundEventKind values
+	Candidates    []string               `protobuf:"bytes,2,rep,name=candidates,proto3" json:"candidates,omitempty"` // candidate ids, tied candidates in the order decided
+	Votes         string                 `protobuf:"bytes,3,opt,name=votes,proto3" json:"votes,omitempty"`
+	Category      string                 `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"` // name of the category whose minimum or maximum caused the event
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Result) Reset() {
-	*x = Result{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_storage_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *RoundEvent) Reset() {
+	*x = RoundEvent{}
+	mi := &file_storage_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Result) String() string {
+func (x *RoundEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Result) ProtoMessage() {}
+func (*RoundEvent) ProtoMessage() {}
 
-func (x *Result) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *RoundEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[17]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -389,60 +1733,64 @@ func (x *Result) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Result.ProtoReflect.Descriptor instead.
-func (*Result) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use RoundEvent.ProtoReflect.Descriptor instead.
+func (*RoundEvent) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *Result) GetRounds() uint64 {
+func (x *RoundEvent) GetKind() string {
 	if x != nil {
-		return x.Rounds
+		return x.Kind
 	}
-	return 0
+	return ""
 }
 
-func (x *Result) GetWinners() []string {
+func (x *RoundEvent) GetCandidates() []string {
 	if x != nil {
-		return x.Winners
+		return x.Candidates
 	}
 	return nil
 }
 
-func (x *Result) GetRound() []*Round {
+func (x *RoundEvent) GetVotes() string {
 	if x != nil {
-		return x.Round
+		return x.Votes
 	}
-	return nil
+	return ""
 }
 
-type Round struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *RoundEvent) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
 
-	Round           uint64             `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
-	Blanks          uint64             `protobuf:"varint,2,opt,name=blanks,proto3" json:"blanks,omitempty"`
-	CandidateStatus []*CandidateStatus `protobuf:"bytes,3,rep,name=candidateStatus,proto3" json:"candidateStatus,omitempty"`
+type TieBreak struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Candidates    []string               `protobuf:"bytes,1,rep,name=candidates,proto3" json:"candidates,omitempty"` // candidate ids in the order decided
+	Method        string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Round) Reset() {
-	*x = Round{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_storage_proto_msgTypes[5]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+func (x *TieBreak) Reset() {
+	*x = TieBreak{}
+	mi := &file_storage_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Round) String() string {
+func (x *TieBreak) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Round) ProtoMessage() {}
+func (*TieBreak) ProtoMessage() {}
 
-func (x *Round) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *TieBreak) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[18]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -452,50 +1800,49 @@ func (x *Round) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Round.ProtoReflect.Descriptor instead.
-func (*Round) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use TieBreak.ProtoReflect.Descriptor instead.
+func (*TieBreak) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *Round) GetRound() uint64 {
+func (x *TieBreak) GetCandidates() []string {
 	if x != nil {
-		return x.Round
+		return x.Candidates
 	}
-	return 0
+	return nil
 }
 
-func (x *Round) GetBlanks() uint64 {
+func (x *TieBreak) GetMethod() string {
 	if x != nil {
-		return x.Blanks
+		return x.Method
 	}
-	return 0
+	return ""
 }
 
-func (x *Round) GetCandidateStatus() []*CandidateStatus {
+func (x *TieBreak) GetReason() string {
 	if x != nil {
-		return x.CandidateStatus
+		return x.Reason
 	}
-	return nil
+	return ""
 }
 
 type CandidateStatus struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CandidateRank uint64                 `protobuf:"varint,1,opt,name=candidateRank,proto3" json:"candidateRank,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	NoOfVotes     float64                `protobuf:"fixed64,3,opt,name=noOfVotes,proto3" json:"noOfVotes,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Votes         string                 `protobuf:"bytes,5,opt,name=votes,proto3" json:"votes,omitempty"`      // lossless form of noOfVotes, an exact fraction or fixed decimal
+	Ballots       uint64                 `protobuf:"varint,6,opt,name=ballots,proto3" json:"ballots,omitempty"` // ballots making up the votes
 	unknownFields protoimpl.UnknownFields
-
-	CandidateRank uint64  `protobuf:"varint,1,opt,name=candidateRank,proto3" json:"candidateRank,omitempty"`
-	Id            string  `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
-	NoOfVotes     float64 `protobuf:"fixed64,3,opt,name=noOfVotes,proto3" json:"noOfVotes,omitempty"`
-	Status        string  `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CandidateStatus) Reset() {
 	*x = CandidateStatus{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_storage_proto_msgTypes[6]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_storage_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *CandidateStatus) String() string {
@@ -505,8 +1852,8 @@ func (x *CandidateStatus) String() string {
 func (*CandidateStatus) ProtoMessage() {}
 
 func (x *CandidateStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[6]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_storage_proto_msgTypes[19]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -518,7 +1865,7 @@ func (x *CandidateStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CandidateStatus.ProtoReflect.Descriptor instead.
 func (*CandidateStatus) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{6}
+	return file_storage_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *CandidateStatus) GetCandidateRank() uint64 {
@@ -549,24 +1896,35 @@ func (x *CandidateStatus) GetStatus() string {
 	return ""
 }
 
+func (x *CandidateStatus) GetVotes() string {
+	if x != nil {
+		return x.Votes
+	}
+	return ""
+}
+
+func (x *CandidateStatus) GetBallots() uint64 {
+	if x != nil {
+		return x.Ballots
+	}
+	return 0
+}
+
 type URL struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Election      string                 `protobuf:"bytes,2,opt,name=election,proto3" json:"election,omitempty"`
+	Voter         string                 `protobuf:"bytes,3,opt,name=voter,proto3" json:"voter,omitempty"`
+	Voted         bool                   `protobuf:"varint,4,opt,name=voted,proto3" json:"voted,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Url      string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
-	Election string `protobuf:"bytes,2,opt,name=election,proto3" json:"election,omitempty"`
-	Voter    string `protobuf:"bytes,3,opt,name=voter,proto3" json:"voter,omitempty"`
-	Voted    bool   `protobuf:"varint,4,opt,name=voted,proto3" json:"voted,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *URL) Reset() {
 	*x = URL{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_storage_proto_msgTypes[7]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_storage_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *URL) String() string {
@@ -576,8 +1934,8 @@ func (x *URL) String() string {
 func (*URL) ProtoMessage() {}
 
 func (x *URL) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[7]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_storage_proto_msgTypes[20]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -589,7 +1947,7 @@ func (x *URL) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use URL.ProtoReflect.Descriptor instead.
 func (*URL) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{7}
+	return file_storage_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *URL) GetUrl() string {
@@ -621,21 +1979,19 @@ func (x *URL) GetVoted() bool {
 }
 
 type Voter struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Weight        string                 `protobuf:"bytes,3,opt,name=weight,proto3" json:"weight,omitempty"` // value of the voter's ballots in the count, an exact fraction, empty is 1
 	unknownFields protoimpl.UnknownFields
-
-	Email string `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
-	Name  string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Voter) Reset() {
 	*x = Voter{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_storage_proto_msgTypes[8]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_storage_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *Voter) String() string {
@@ -645,8 +2001,8 @@ func (x *Voter) String() string {
 func (*Voter) ProtoMessage() {}
 
 func (x *Voter) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[8]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_storage_proto_msgTypes[21]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -658,7 +2014,7 @@ func (x *Voter) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Voter.ProtoReflect.Descriptor instead.
 func (*Voter) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{8}
+	return file_storage_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *Voter) GetEmail() string {
@@ -675,137 +2031,287 @@ func (x *Voter) GetName() string {
 	return ""
 }
 
+func (x *Voter) GetWeight() string {
+	if x != nil {
+		return x.Weight
+	}
+	return ""
+}
+
 var File_storage_proto protoreflect.FileDescriptor
 
-var file_storage_proto_rawDesc = []byte{
-	0x0a, 0x0d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x22, 0x8d, 0x02, 0x0a, 0x03, 0x53, 0x54, 0x56,
-	0x12, 0x29, 0x0a, 0x07, 0x62, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x42, 0x61, 0x6c, 0x6c,
-	0x6f, 0x74, 0x52, 0x07, 0x62, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x73, 0x12, 0x32, 0x0a, 0x0a, 0x63,
-	0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x12, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64,
-	0x61, 0x74, 0x65, 0x52, 0x0a, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x12,
-	0x2f, 0x0a, 0x09, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x45, 0x6c, 0x65,
-	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x12, 0x20, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c,
-	0x2e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x55, 0x52, 0x4c, 0x52, 0x04, 0x75, 0x72,
-	0x6c, 0x73, 0x12, 0x26, 0x0a, 0x06, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x56, 0x6f, 0x74,
-	0x65, 0x72, 0x52, 0x06, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x12, 0x2c, 0x0a, 0x11, 0x61, 0x6c,
-	0x6c, 0x6f, 0x77, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
-	0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x67, 0x69,
-	0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xa4, 0x01, 0x0a, 0x06, 0x42, 0x61, 0x6c,
-	0x6c, 0x6f, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
-	0x33, 0x0a, 0x06, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x1b, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x74,
-	0x2e, 0x43, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x63, 0x68,
-	0x6f, 0x69, 0x63, 0x65, 0x1a, 0x39, 0x0a, 0x0b, 0x43, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
-	0x4b, 0x0a, 0x09, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x12, 0x0e, 0x0a, 0x02,
-	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08,
-	0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x91, 0x02, 0x0a,
-	0x08, 0x45, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a,
-	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12,
-	0x10, 0x0a, 0x03, 0x72, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x72, 0x6f,
-	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x65, 0x61, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x05, 0x73, 0x65, 0x61, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x18,
-	0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x63,
-	0x6c, 0x6f, 0x73, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x63, 0x6c, 0x6f,
-	0x73, 0x65, 0x64, 0x12, 0x27, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x08, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x52, 0x65,
-	0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x2a, 0x0a, 0x08,
-	0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e,
-	0x2e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x52, 0x08,
-	0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x74, 0x65,
-	0x72, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73,
-	0x22, 0x60, 0x0a, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x6f,
-	0x75, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x72, 0x6f, 0x75, 0x6e,
-	0x64, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x07, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x73, 0x12, 0x24, 0x0a, 0x05,
-	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x74,
-	0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x52, 0x05, 0x72, 0x6f, 0x75,
-	0x6e, 0x64, 0x22, 0x79, 0x0a, 0x05, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x72,
-	0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e,
-	0x64, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x6c, 0x61, 0x6e, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x06, 0x62, 0x6c, 0x61, 0x6e, 0x6b, 0x73, 0x12, 0x42, 0x0a, 0x0f, 0x63, 0x61, 0x6e,
-	0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x43, 0x61, 0x6e,
-	0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x0f, 0x63, 0x61,
-	0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x7d, 0x0a,
-	0x0f, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x12, 0x24, 0x0a, 0x0d, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x52, 0x61, 0x6e,
-	0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61,
-	0x74, 0x65, 0x52, 0x61, 0x6e, 0x6b, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x6f, 0x4f, 0x66, 0x56, 0x6f,
-	0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6e, 0x6f, 0x4f, 0x66, 0x56,
-	0x6f, 0x74, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x5f, 0x0a, 0x03,
-	0x55, 0x52, 0x4c, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f,
-	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f,
-	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x64,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x64, 0x22, 0x31, 0x0a,
-	0x05, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x12, 0x0a, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
-	0x42, 0x21, 0x5a, 0x1f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x79,
-	0x73, 0x74, 0x76, 0x2f, 0x73, 0x74, 0x76, 0x2d, 0x77, 0x65, 0x62, 0x2f, 0x73, 0x74, 0x6f, 0x72,
-	0x61, 0x67, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
+const file_storage_proto_rawDesc = "" +
+	"\n" +
+	"\rstorage.proto\x12\astorage\"\xb3\x02\n" +
+	"\x03STV\x12)\n" +
+	"\aballots\x18\x01 \x03(\v2\x0f.storage.BallotR\aballots\x122\n" +
+	"\n" +
+	"candidates\x18\x02 \x03(\v2\x12.storage.CandidateR\n" +
+	"candidates\x12/\n" +
+	"\telections\x18\x03 \x03(\v2\x11.storage.ElectionR\telections\x12 \n" +
+	"\x04urls\x18\x04 \x03(\v2\f.storage.URLR\x04urls\x12&\n" +
+	"\x06voters\x18\x05 \x03(\v2\x0e.storage.VoterR\x06voters\x12,\n" +
+	"\x11allowRegistration\x18\x06 \x01(\bR\x11allowRegistration\x12$\n" +
+	"\rschemaVersion\x18\a \x01(\x04R\rschemaVersion\"n\n" +
+	"\bSnapshot\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x0e\n" +
+	"\x02at\x18\x02 \x01(\x03R\x02at\x12\"\n" +
+	"\x05state\x18\x03 \x01(\v2\f.storage.STVR\x05state\x12\x1c\n" +
+	"\tballotBox\x18\x04 \x01(\x04R\tballotBox\"V\n" +
+	"\tBallotBox\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x04R\n" +
+	"generation\x12)\n" +
+	"\aballots\x18\x02 \x03(\v2\x0f.storage.BallotR\aballots\"T\n" +
+	"\bLogEntry\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x0e\n" +
+	"\x02at\x18\x02 \x01(\x03R\x02at\x12&\n" +
+	"\x06events\x18\x03 \x03(\v2\x0e.storage.EventR\x06events\"\xf7\x02\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12)\n" +
+	"\x06ballot\x18\x02 \x01(\v2\x0f.storage.BallotH\x00R\x06ballot\x122\n" +
+	"\tcandidate\x18\x03 \x01(\v2\x12.storage.CandidateH\x00R\tcandidate\x12/\n" +
+	"\belection\x18\x04 \x01(\v2\x11.storage.ElectionH\x00R\belection\x12 \n" +
+	"\x03url\x18\x05 \x01(\v2\f.storage.URLH\x00R\x03url\x12&\n" +
+	"\x05voter\x18\x06 \x01(\v2\x0e.storage.VoterH\x00R\x05voter\x12.\n" +
+	"\x11allowRegistration\x18\a \x01(\bH\x00R\x11allowRegistration\x12&\n" +
+	"\rschemaVersion\x18\b \x01(\x04H\x00R\rschemaVersion\x12\x1e\n" +
+	"\tballotBox\x18\t \x01(\x04H\x00R\tballotBoxB\b\n" +
+	"\x06record\"\xd6\x01\n" +
+	"\x06Ballot\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\belection\x18\x02 \x01(\tR\belection\x123\n" +
+	"\x06choice\x18\x03 \x03(\v2\x1b.storage.Ballot.ChoiceEntryR\x06choice\x12\x16\n" +
+	"\x06weight\x18\x04 \x01(\tR\x06weight\x12\x18\n" +
+	"\aabstain\x18\x05 \x01(\bR\aabstain\x1a9\n" +
+	"\vChoiceEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x04R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x89\x01\n" +
+	"\tCandidate\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\belection\x18\x02 \x01(\tR\belection\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1c\n" +
+	"\twithdrawn\x18\x04 \x01(\bR\twithdrawn\x12\x1e\n" +
+	"\n" +
+	"categories\x18\x05 \x03(\tR\n" +
+	"categories\"\xcc\x06\n" +
+	"\bElection\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x10\n" +
+	"\x03ron\x18\x04 \x01(\bR\x03ron\x12\x14\n" +
+	"\x05seats\x18\x05 \x01(\x04R\x05seats\x12\x12\n" +
+	"\x04open\x18\x06 \x01(\bR\x04open\x12\x16\n" +
+	"\x06closed\x18\a \x01(\bR\x06closed\x12'\n" +
+	"\x06result\x18\b \x01(\v2\x0f.storage.ResultR\x06result\x12.\n" +
+	"\bexcluded\x18\t \x03(\v2\x0e.storage.VoterB\x02\x18\x01R\bexcluded\x12\x16\n" +
+	"\x06voters\x18\n" +
+	" \x01(\x04R\x06voters\x12\x16\n" +
+	"\x06method\x18\v \x01(\tR\x06method\x12\x1e\n" +
+	"\n" +
+	"arithmetic\x18\f \x01(\tR\n" +
+	"arithmetic\x12$\n" +
+	"\rdecimalPlaces\x18\r \x01(\x04R\rdecimalPlaces\x12\"\n" +
+	"\ftieBreakSeed\x18\x0e \x01(\tR\ftieBreakSeed\x12\x14\n" +
+	"\x05quota\x18\x0f \x01(\tR\x05quota\x12 \n" +
+	"\vsurplusRule\x18\x10 \x01(\tR\vsurplusRule\x12\x1e\n" +
+	"\n" +
+	"ballotType\x18\x11 \x01(\tR\n" +
+	"ballotType\x12\x1c\n" +
+	"\tthreshold\x18\x12 \x01(\tR\tthreshold\x12\x16\n" +
+	"\x06quorum\x18\x13 \x01(\x04R\x06quorum\x12\x1a\n" +
+	"\bimported\x18\x14 \x01(\bR\bimported\x12$\n" +
+	"\rcompareMethod\x18\x15 \x01(\tR\rcompareMethod\x122\n" +
+	"\n" +
+	"countbacks\x18\x16 \x03(\v2\x12.storage.CountbackR\n" +
+	"countbacks\x121\n" +
+	"\n" +
+	"categories\x18\x17 \x03(\v2\x11.storage.CategoryR\n" +
+	"categories\x12*\n" +
+	"\x10batchElimination\x18\x18 \x01(\bR\x10batchElimination\x12(\n" +
+	"\x0fsetAsideInvalid\x18\x19 \x01(\bR\x0fsetAsideInvalid\x12&\n" +
+	"\x0eexcludedVoters\x18\x1a \x03(\tR\x0eexcludedVoters\"B\n" +
+	"\bCategory\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03min\x18\x02 \x01(\x04R\x03min\x12\x10\n" +
+	"\x03max\x18\x03 \x01(\x04R\x03max\"\xd2\x01\n" +
+	"\tCountback\x12\x1a\n" +
+	"\bvacating\x18\x01 \x01(\tR\bvacating\x12\x1a\n" +
+	"\bstanding\x18\x02 \x03(\tR\bstanding\x12\x16\n" +
+	"\x06winner\x18\x03 \x01(\tR\x06winner\x12\x18\n" +
+	"\aballots\x18\x04 \x01(\x04R\aballots\x12\x14\n" +
+	"\x05votes\x18\x05 \x01(\tR\x05votes\x12'\n" +
+	"\x06result\x18\x06 \x01(\v2\x0f.storage.ResultR\x06result\x12\x1c\n" +
+	"\tcountedAt\x18\a \x01(\x03R\tcountedAt\"\xac\x06\n" +
+	"\x06Result\x12\x16\n" +
+	"\x06rounds\x18\x01 \x01(\x04R\x06rounds\x12\x18\n" +
+	"\awinners\x18\x02 \x03(\tR\awinners\x12$\n" +
+	"\x05round\x18\x03 \x03(\v2\x0e.storage.RoundR\x05round\x12\x16\n" +
+	"\x06method\x18\x04 \x01(\tR\x06method\x12\x1e\n" +
+	"\n" +
+	"arithmetic\x18\x05 \x01(\tR\n" +
+	"arithmetic\x12$\n" +
+	"\rdecimalPlaces\x18\x06 \x01(\x04R\rdecimalPlaces\x12\"\n" +
+	"\ftieBreakSeed\x18\a \x01(\tR\ftieBreakSeed\x12\x1a\n" +
+	"\blotOrder\x18\b \x03(\tR\blotOrder\x12\x14\n" +
+	"\x05quota\x18\t \x01(\tR\x05quota\x12\x1e\n" +
+	"\n" +
+	"quotaValue\x18\n" +
+	" \x01(\tR\n" +
+	"quotaValue\x12 \n" +
+	"\vsurplusRule\x18\v \x01(\tR\vsurplusRule\x12.\n" +
+	"\x12pairwiseCandidates\x18\f \x03(\tR\x12pairwiseCandidates\x126\n" +
+	"\vpreferences\x18\r \x03(\v2\x14.storage.PairwiseRowR\vpreferences\x12<\n" +
+	"\x0estrongestPaths\x18\x0e \x03(\v2\x14.storage.PairwiseRowR\x0estrongestPaths\x12-\n" +
+	"\x06motion\x18\x0f \x01(\v2\x15.storage.MotionResultR\x06motion\x12$\n" +
+	"\rcompareMethod\x18\x10 \x01(\tR\rcompareMethod\x12\x1c\n" +
+	"\twithdrawn\x18\x11 \x03(\tR\twithdrawn\x12\x18\n" +
+	"\aballots\x18\x12 \x01(\x04R\aballots\x12(\n" +
+	"\x0fweightedBallots\x18\x13 \x01(\tR\x0fweightedBallots\x12*\n" +
+	"\x10batchElimination\x18\x14 \x01(\bR\x10batchElimination\x12-\n" +
+	"\x06spoilt\x18\x15 \x03(\v2\x15.storage.SpoiltBallotR\x06spoilt\x12\x1c\n" +
+	"\tabstained\x18\x16 \x01(\x04R\tabstained\">\n" +
+	"\fSpoiltBallot\x12\x16\n" +
+	"\x06ballot\x18\x01 \x01(\tR\x06ballot\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\xe4\x01\n" +
+	"\fMotionResult\x12\x1a\n" +
+	"\bvotesFor\x18\x01 \x01(\x04R\bvotesFor\x12\"\n" +
+	"\fvotesAgainst\x18\x02 \x01(\x04R\fvotesAgainst\x12 \n" +
+	"\vabstentions\x18\x03 \x01(\x04R\vabstentions\x12\x1c\n" +
+	"\tthreshold\x18\x04 \x01(\tR\tthreshold\x12\x16\n" +
+	"\x06quorum\x18\x05 \x01(\x04R\x06quorum\x12$\n" +
+	"\rquorumReached\x18\x06 \x01(\bR\rquorumReached\x12\x16\n" +
+	"\x06passed\x18\a \x01(\bR\x06passed\"%\n" +
+	"\vPairwiseRow\x12\x16\n" +
+	"\x06counts\x18\x01 \x03(\x04R\x06counts\"\xd2\x02\n" +
+	"\x05Round\x12\x14\n" +
+	"\x05round\x18\x01 \x01(\x04R\x05round\x12\x16\n" +
+	"\x06blanks\x18\x02 \x01(\x04R\x06blanks\x12B\n" +
+	"\x0fcandidateStatus\x18\x03 \x03(\v2\x18.storage.CandidateStatusR\x0fcandidateStatus\x12\x1e\n" +
+	"\n" +
+	"blankVotes\x18\x04 \x01(\tR\n" +
+	"blankVotes\x12(\n" +
+	"\x0flossByFractions\x18\x05 \x01(\tR\x0flossByFractions\x12/\n" +
+	"\ttieBreaks\x18\x06 \x03(\v2\x11.storage.TieBreakR\ttieBreaks\x12+\n" +
+	"\x06events\x18\a \x03(\v2\x13.storage.RoundEventR\x06events\x12/\n" +
+	"\ttransfers\x18\b \x03(\v2\x11.storage.TransferR\ttransfers\"\x8b\x02\n" +
+	"\bTransfer\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\x12'\n" +
+	"\x02to\x18\x04 \x03(\v2\x17.storage.TransferAmountR\x02to\x12*\n" +
+	"\x10exhaustedBallots\x18\x05 \x01(\x04R\x10exhaustedBallots\x12&\n" +
+	"\x0eexhaustedVotes\x18\x06 \x01(\tR\x0eexhaustedVotes\x12(\n" +
+	"\x0flossByFractions\x18\a \x01(\tR\x0flossByFractions\x12\x1a\n" +
+	"\bweighted\x18\b \x01(\bR\bweighted\"^\n" +
+	"\x0eTransferAmount\x12\x1c\n" +
+	"\tcandidate\x18\x01 \x01(\tR\tcandidate\x12\x18\n" +
+	"\aballots\x18\x02 \x01(\x04R\aballots\x12\x14\n" +
+	"\x05votes\x18\x03 \x01(\tR\x05votes\"r\n" +
+	"\n" +
+	"RoundEvent\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1e\n" +
+	"\n" +
+	"candidates\x18\x02 \x03(\tR\n" +
+	"candidates\x12\x14\n" +
+	"\x05votes\x18\x03 \x01(\tR\x05votes\x12\x1a\n" +
+	"\bcategory\x18\x04 \x01(\tR\bcategory\"Z\n" +
+	"\bTieBreak\x12\x1e\n" +
+	"\n" +
+	"candidates\x18\x01 \x03(\tR\n" +
+	"candidates\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\xad\x01\n" +
+	"\x0fCandidateStatus\x12$\n" +
+	"\rcandidateRank\x18\x01 \x01(\x04R\rcandidateRank\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x1c\n" +
+	"\tnoOfVotes\x18\x03 \x01(\x01R\tnoOfVotes\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x14\n" +
+	"\x05votes\x18\x05 \x01(\tR\x05votes\x12\x18\n" +
+	"\aballots\x18\x06 \x01(\x04R\aballots\"_\n" +
+	"\x03URL\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1a\n" +
+	"\belection\x18\x02 \x01(\tR\belection\x12\x14\n" +
+	"\x05voter\x18\x03 \x01(\tR\x05voter\x12\x14\n" +
+	"\x05voted\x18\x04 \x01(\bR\x05voted\"I\n" +
+	"\x05Voter\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\tR\x06weightB!Z\x1fgithub.com/ystv/stv-web/storageb\x06proto3"
 
 var (
 	file_storage_proto_rawDescOnce sync.Once
-	file_storage_proto_rawDescData = file_storage_proto_rawDesc
+	file_storage_proto_rawDescData []byte
 )
 
 func file_storage_proto_rawDescGZIP() []byte {
 	file_storage_proto_rawDescOnce.Do(func() {
-		file_storage_proto_rawDescData = protoimpl.X.CompressGZIP(file_storage_proto_rawDescData)
+		file_storage_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_storage_proto_rawDesc), len(file_storage_proto_rawDesc)))
 	})
 	return file_storage_proto_rawDescData
 }
 
-var file_storage_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
-var file_storage_proto_goTypes = []interface{}{
+var file_storage_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_storage_proto_goTypes = []any{
 	(*STV)(nil),             // 0: storage.STV
-	(*Ballot)(nil),          // 1: storage.Ballot
-	(*Candidate)(nil),       // 2: storage.Candidate
-	(*Election)(nil),        // 3: storage.Election
-	(*Result)(nil),          // 4: storage.Result
-	(*Round)(nil),           // 5: storage.Round
-	(*CandidateStatus)(nil), // 6: storage.CandidateStatus
-	(*URL)(nil),             // 7: storage.URL
-	(*Voter)(nil),           // 8: storage.Voter
-	nil,                     // 9: storage.Ballot.ChoiceEntry
+	(*Snapshot)(nil),        // 1: storage.Snapshot
+	(*BallotBox)(nil),       // 2: storage.BallotBox
+	(*LogEntry)(nil),        // 3: storage.LogEntry
+	(*Event)(nil),           // 4: storage.Event
+	(*Ballot)(nil),          // 5: storage.Ballot
+	(*Candidate)(nil),       // 6: storage.Candidate
+	(*Election)(nil),        // 7: storage.Election
+	(*Category)(nil),        // 8: storage.Category
+	(*Countback)(nil),       // 9: storage.Countback
+	(*Result)(nil),          // 10: storage.Result
+	(*SpoiltBallot)(nil),    // 11: storage.SpoiltBallot
+	(*MotionResult)(nil),    // 12: storage.MotionResult
+	(*PairwiseRow)(nil),     // 13: storage.PairwiseRow
+	(*Round)(nil),           // 14: storage.Round
+	(*Transfer)(nil),        // 15: storage.Transfer
+	(*TransferAmount)(nil),  // 16: storage.TransferAmount
+	(*RoundEvent)(nil),      // 17: storage.RoundEvent
+	(*TieBreak)(nil),        // 18: storage.TieBreak
+	(*CandidateStatus)(nil), // 19: storage.CandidateStatus
+	(*URL)(nil),             // 20: storage.URL
+	(*Voter)(nil),           // 21: storage.Voter
+	nil,                     // 22: storage.Ballot.ChoiceEntry
 }
 var file_storage_proto_depIdxs = []int32{
-	1,  // 0: storage.STV.ballots:type_name -> storage.Ballot
-	2,  // 1: storage.STV.candidates:type_name -> storage.Candidate
-	3,  // 2: storage.STV.elections:type_name -> storage.Election
-	7,  // 3: storage.STV.urls:type_name -> storage.URL
-	8,  // 4: storage.STV.voters:type_name -> storage.Voter
-	9,  // 5: storage.Ballot.choice:type_name -> storage.Ballot.ChoiceEntry
-	4,  // 6: storage.Election.result:type_name -> storage.Result
-	8,  // 7: storage.Election.excluded:type_name -> storage.Voter
-	5,  // 8: storage.Result.round:type_name -> storage.Round
-	6,  // 9: storage.Round.candidateStatus:type_name -> storage.CandidateStatus
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	5,  // 0: storage.STV.ballots:type_name -> storage.Ballot
+	6,  // 1: storage.STV.candidates:type_name -> storage.Candidate
+	7,  // 2: storage.STV.elections:type_name -> storage.Election
+	20, // 3: storage.STV.urls:type_name -> storage.URL
+	21, // 4: storage.STV.voters:type_name -> storage.Voter
+	0,  // 5: storage.Snapshot.state:type_name -> storage.STV
+	5,  // 6: storage.BallotBox.ballots:type_name -> storage.Ballot
+	4,  // 7: storage.LogEntry.events:type_name -> storage.Event
+	5,  // 8: storage.Event.ballot:type_name -> storage.Ballot
+	6,  // 9: storage.Event.candidate:type_name -> storage.Candidate
+	7,  // 10: storage.Event.election:type_name -> storage.Election
+	20, // 11: storage.Event.url:type_name -> storage.URL
+	21, // 12: storage.Event.voter:type_name -> storage.Voter
+	22, // 13: storage.Ballot.choice:type_name -> storage.Ballot.ChoiceEntry
+	10, // 14: storage.Election.result:type_name -> storage.Result
+	21, // 15: storage.Election.excluded:type_name -> storage.Voter
+	9,  // 16: storage.Election.countbacks:type_name -> storage.Countback
+	8,  // 17: storage.Election.categories:type_name -> storage.Category
+	10, // 18: storage.Countback.result:type_name -> storage.Result
+	14, // 19: storage.Result.round:type_name -> storage.Round
+	13, // 20: storage.Result.preferences:type_name -> storage.PairwiseRow
+	13, // 21: storage.Result.strongestPaths:type_name -> storage.PairwiseRow
+	12, // 22: storage.Result.motion:type_name -> storage.MotionResult
+	11, // 23: storage.Result.spoilt:type_name -> storage.SpoiltBallot
+	19, // 24: storage.Round.candidateStatus:type_name -> storage.CandidateStatus
+	18, // 25: storage.Round.tieBreaks:type_name -> storage.TieBreak
+	17, // 26: storage.Round.events:type_name -> storage.RoundEvent
+	15, // 27: storage.Round.transfers:type_name -> storage.Transfer
+	16, // 28: storage.Transfer.to:type_name -> storage.TransferAmount
+	29, // [29:29] is the sub-list for method output_type
+	29, // [29:29] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
 }
 
 func init() { file_storage_proto_init() }
@@ -813,123 +2319,23 @@ func file_storage_proto_init() {
 	if File_storage_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_storage_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*STV); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_storage_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Ballot); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_storage_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Candidate); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_storage_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Election); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_storage_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Result); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_storage_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Round); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_storage_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CandidateStatus); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_storage_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*URL); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_storage_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Voter); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
+	file_storage_proto_msgTypes[4].OneofWrappers = []any{
+		(*Event_Ballot)(nil),
+		(*Event_Candidate)(nil),
+		(*Event_Election)(nil),
+		(*Event_Url)(nil),
+		(*Event_Voter)(nil),
+		(*Event_AllowRegistration)(nil),
+		(*Event_SchemaVersion)(nil),
+		(*Event_BallotBox)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_storage_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_storage_proto_rawDesc), len(file_storage_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   23,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -938,7 +2344,6 @@ func file_storage_proto_init() {
 		MessageInfos:      file_storage_proto_msgTypes,
 	}.Build()
 	File_storage_proto = out.File
-	file_storage_proto_rawDesc = nil
 	file_storage_proto_goTypes = nil
 	file_storage_proto_depIdxs = nil
 }
@@ -1,10 +1,35 @@
 package store
 
 import (
+	"slices"
+
 	"github.com/ystv/stv-web/storage"
 )
 
 type Backend interface {
+	// Read returns the current state, it is shared between readers and must
+	// not be changed
 	Read() (*storage.STV, error)
-	Write(state *storage.STV) error
+	// Update calls fn with a copy of the state and saves the copy as the new
+	// state if fn returns nil. Updates are made one at a time, readers see the
+	// previous state until the new one has been saved.
+	//
+	// The copy has lists of its own but shares their records with readers, fn
+	// replaces a record with a changed copy rather than changing it in place.
+	// A backend can tell the records an update changed by the ones replaced.
+	Update(fn func(state *storage.STV) error) error
+}
+
+// copyState is the copy of the state an update changes, with lists of its
+// own holding the same records
+func copyState(stv *storage.STV) *storage.STV {
+	return &storage.STV{
+		Ballots:           slices.Clone(stv.GetBallots()),
+		Candidates:        slices.Clone(stv.GetCandidates()),
+		Elections:         slices.Clone(stv.GetElections()),
+		Urls:              slices.Clone(stv.GetUrls()),
+		Voters:            slices.Clone(stv.GetVoters()),
+		AllowRegistration: stv.GetAllowRegistration(),
+		SchemaVersion:     stv.GetSchemaVersion(),
+	}
 }
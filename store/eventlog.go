@@ -0,0 +1,952 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ystv/stv-web/storage"
+)
+
+// EventKind is the type of change an event in the event log records
+type EventKind string
+
+const (
+	EventElectionAdded       EventKind = "ElectionAdded"
+	EventElectionChanged     EventKind = "ElectionChanged"
+	EventElectionOpened      EventKind = "ElectionOpened"
+	EventElectionClosed      EventKind = "ElectionClosed"
+	EventElectionRemoved     EventKind = "ElectionRemoved"
+	EventCandidateAdded      EventKind = "CandidateAdded"
+	EventCandidateChanged    EventKind = "CandidateChanged"
+	EventCandidateWithdrawn  EventKind = "CandidateWithdrawn"
+	EventCandidateRemoved    EventKind = "CandidateRemoved"
+	EventVoterAdded          EventKind = "VoterAdded"
+	EventVoterChanged        EventKind = "VoterChanged"
+	EventVoterRemoved        EventKind = "VoterRemoved"
+	EventURLAdded            EventKind = "URLAdded"
+	EventURLChanged          EventKind = "URLChanged"
+	EventURLVoted            EventKind = "URLVoted"
+	EventURLRemoved          EventKind = "URLRemoved"
+	EventBallotCast          EventKind = "BallotCast"
+	EventBallotChanged       EventKind = "BallotChanged"
+	EventBallotRemoved       EventKind = "BallotRemoved"
+	EventRegistrationChanged EventKind = "RegistrationChanged"
+	EventSchemaMigrated      EventKind = "SchemaMigrated"
+	EventBallotBoxed         EventKind = "BallotBoxed"
+)
+
+// snapshotEvery is the number of log entries after which the state is
+// written as a snapshot and a new log is started
+const snapshotEvery = 1000
+
+// EventLogBackend appends every change to a log of events and rebuilds the
+// state by replaying the log on top of the last snapshot when it starts.
+// Each update is one entry in the log, an entry cut short by a crash is
+// dropped when the log is replayed. Logs covered by a snapshot are kept as
+// store.log.<seq> for the history but aren't replayed.
+//
+// Ballots of open elections are kept in a ballot box, store.ballots, without a
+// time rather than in the log. The entry that marks a URL as voted only names
+// the generation of the box its ballot was appended to, and the ballots are
+// logged together when their election closes. Ballots are appended to the box
+// in the order they were cast, the box is compacted into one record sorted by
+// id when the log is snapshotted and when ballots leave it, so once their
+// election closes neither the log nor the box ties a ballot to the URL it was
+// cast with.
+type EventLogBackend struct {
+	folder string
+	log    *os.File
+	// size is the length of the log up to the last whole entry
+	size int64
+	// seq is the number of the last entry, sinceSnapshot is how many of them
+	// are in the current log
+	seq           uint64
+	sinceSnapshot uint64
+	// logged is the state the snapshot and log hold and box the ballots in the
+	// ballot box, the cache is logged with the box added
+	logged        *storage.STV
+	box           []*storage.Ballot
+	boxGeneration uint64
+	// boxFile is the ballot box open for appending, nil when ballots can't be
+	// appended until it is compacted. boxSize is its length up to the last
+	// whole record and boxAppended the records appended since it was compacted.
+	boxFile     *os.File
+	boxSize     int64
+	boxAppended int
+	cache       *storage.STV
+	mutex       sync.RWMutex
+	// update is held for the whole of an update so they are made one at a time
+	update sync.Mutex
+}
+
+func NewEventLogBackend(root bool) (Backend, error) {
+	folder := "./db"
+	if root {
+		folder = "/db"
+	}
+	err := os.MkdirAll(folder, 0777)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make folder %s: %w", folder, err)
+	}
+
+	eb := &EventLogBackend{folder: folder}
+	snapshotPath := filepath.Join(folder, "store.snapshot")
+	logPath := filepath.Join(folder, "store.log")
+	filePath := filepath.Join(folder, "store.db")
+
+	_, snapshotErr := os.Stat(snapshotPath)
+	_, logErr := os.Stat(logPath)
+	migrating := os.IsNotExist(snapshotErr) && os.IsNotExist(logErr)
+	if migrating {
+		// a new log takes over from the file store if there is one
+		var state *storage.STV
+		state, err = readFileStore(filePath)
+		if err == nil {
+			err = eb.takeOver(state)
+		}
+	} else {
+		eb.logged, err = eb.readSnapshot(snapshotPath)
+		if err == nil {
+			err = eb.replay(logPath)
+		}
+		if err == nil {
+			err = eb.readBallotBox()
+		}
+	}
+	if err != nil {
+		_ = eb.boxFile.Close()
+		return nil, err
+	}
+
+	eb.log, err = os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", logPath, err)
+	}
+	if eb.boxFile == nil {
+		if err = eb.openBallotBox(); err != nil {
+			_ = eb.log.Close()
+			return nil, err
+		}
+	}
+
+	if migrating {
+		err = retireFileStore(filePath)
+	} else {
+		// ballots of open elections logged before they were kept in the
+		// ballot box are moved into it
+		err = eb.Update(func(*storage.STV) error { return nil })
+	}
+	if err != nil {
+		_ = eb.log.Close()
+		_ = eb.boxFile.Close()
+		return nil, err
+	}
+
+	log.Printf("db event log from: %s", logPath)
+	return eb, nil
+}
+
+// readSnapshot reads the last snapshot, no snapshot is an empty state before
+// the first entry
+func (eb *EventLogBackend) readSnapshot(path string) (*storage.STV, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &storage.STV{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var snapshot storage.Snapshot
+	if err = proto.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	eb.seq = snapshot.GetSeq()
+	eb.boxGeneration = snapshot.GetBallotBox()
+	state := snapshot.GetState()
+	if state == nil {
+		state = &storage.STV{}
+	}
+	return state, nil
+}
+
+// replay applies the entries of the log after the snapshot, an entry cut short
+// at the end of the log is truncated away
+func (eb *EventLogBackend) replay(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	reader := &countingReader{reader: bufio.NewReader(file)}
+	for {
+		var entry storage.LogEntry
+		err = protodelim.UnmarshalOptions{MaxSize: -1}.UnmarshalFrom(reader, &entry)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			log.Printf("dropping the last entry of %s, it was cut short after %d", path, eb.seq)
+			if err = file.Truncate(eb.size); err != nil {
+				return fmt.Errorf("failed to truncate %s: %w", path, err)
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s after entry %d: %w", path, eb.seq, err)
+		}
+
+		// entries already in the snapshot are left in the log if it failed to
+		// start a new log after the snapshot
+		if entry.GetSeq() > eb.seq {
+			if entry.GetSeq() != eb.seq+1 {
+				return fmt.Errorf("missing entries in %s, entry %d follows %d", path, entry.GetSeq(), eb.seq)
+			}
+			for _, event := range entry.GetEvents() {
+				if box, ok := event.GetRecord().(*storage.Event_BallotBox); ok {
+					eb.boxGeneration = box.BallotBox
+					continue
+				}
+				if err = applyEvent(eb.logged, event); err != nil {
+					return fmt.Errorf("failed to replay entry %d: %w", entry.GetSeq(), err)
+				}
+			}
+			eb.seq = entry.GetSeq()
+			eb.sinceSnapshot++
+		}
+		eb.size = reader.read
+	}
+	return nil
+}
+
+type countingReader struct {
+	reader *bufio.Reader
+	read   int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.read += int64(n)
+	return n, err
+}
+
+func (cr *countingReader) ReadByte() (byte, error) {
+	b, err := cr.reader.ReadByte()
+	if err == nil {
+		cr.read++
+	}
+	return b, err
+}
+
+// snapshot writes the state as the snapshot and starts a new log, the old log
+// is kept for its history. The ballot box is compacted first so the ballots
+// appended since the last snapshot are no longer in the order they were cast.
+func (eb *EventLogBackend) snapshot() error {
+	if err := eb.compactBallotBox(); err != nil {
+		return err
+	}
+	out, err := proto.Marshal(&storage.Snapshot{
+		Seq:       eb.seq,
+		At:        time.Now().Unix(),
+		State:     eb.logged,
+		BallotBox: eb.boxGeneration,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	path := filepath.Join(eb.folder, "store.snapshot")
+	tmp := path + ".tmp"
+	if err = writeSynced(tmp, out); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to move snapshot: %w", err)
+	}
+
+	if eb.log == nil {
+		return nil
+	}
+	// the open log follows the rename, entries can't be written to it after
+	// the snapshot as updates wait for this one
+	logPath := filepath.Join(eb.folder, "store.log")
+	archive := fmt.Sprintf("%s.%d", logPath, eb.seq)
+	if err = os.Rename(logPath, archive); err != nil {
+		return fmt.Errorf("failed to keep %s: %w", logPath, err)
+	}
+	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		if renameErr := os.Rename(archive, logPath); renameErr != nil {
+			log.Printf("failed to move %s back: %+v", archive, renameErr)
+		}
+		return fmt.Errorf("failed to open %s: %w", logPath, err)
+	}
+	_ = eb.log.Close()
+	eb.log = file
+	eb.size = 0
+	eb.sinceSnapshot = 0
+	return nil
+}
+
+func (eb *EventLogBackend) Read() (*storage.STV, error) {
+	eb.mutex.RLock()
+	defer eb.mutex.RUnlock()
+	return eb.cache, nil
+}
+
+func (eb *EventLogBackend) Update(fn func(state *storage.STV) error) error {
+	eb.update.Lock()
+	defer eb.update.Unlock()
+	state := copyState(eb.cache)
+	if err := fn(state); err != nil {
+		return err
+	}
+
+	logged, box := splitBallots(state)
+	var events []*storage.Event
+	for _, records := range eventRecordTypes {
+		events = append(events, records.events(eb.logged, logged)...)
+	}
+	var pending string
+	var appended int64
+	generation := eb.boxGeneration
+	if !slices.EqualFunc(eb.box, box, func(a, b *storage.Ballot) bool { return proto.Equal(a, b) }) {
+		generation++
+		var err error
+		// ballots only cast are appended, any other change rewrites the box
+		if cast, ok := castBallots(eb.box, box); ok && eb.boxFile != nil {
+			appended, err = eb.appendBallotBox(generation, cast)
+		} else {
+			pending, err = eb.writeBallotBox(generation, box)
+		}
+		if err != nil {
+			return err
+		}
+		events = append(events, &storage.Event{
+			Kind:   string(EventBallotBoxed),
+			Record: &storage.Event_BallotBox{BallotBox: generation},
+		})
+	}
+	if len(events) > 0 {
+		var buf bytes.Buffer
+		_, err := protodelim.MarshalTo(&buf, &storage.LogEntry{
+			Seq:    eb.seq + 1,
+			At:     time.Now().Unix(),
+			Events: events,
+		})
+		if err == nil {
+			err = eb.append(buf.Bytes())
+		}
+		if err != nil {
+			if pending != "" {
+				_ = os.Remove(pending)
+			}
+			if appended > 0 {
+				eb.truncateBallotBox()
+			}
+			return err
+		}
+		eb.seq++
+		eb.sinceSnapshot++
+	}
+	if pending != "" {
+		// the entry names the new box, a box left under its generation is
+		// moved into place when the log is next replayed
+		if err := eb.moveBallotBox(pending); err != nil {
+			log.Printf("failed to move ballot box %d into place: %+v", generation, err)
+		}
+	}
+	if appended > 0 {
+		eb.boxSize += appended
+		eb.boxAppended++
+	}
+	eb.boxGeneration = generation
+	eb.logged = logged
+	eb.box = box
+
+	eb.mutex.Lock()
+	eb.cache = state
+	eb.mutex.Unlock()
+
+	if eb.sinceSnapshot >= snapshotEvery {
+		// the update is already in the log, a failed snapshot is tried again
+		// after the next update
+		if err := eb.snapshot(); err != nil {
+			log.Printf("failed to snapshot event log: %+v", err)
+		}
+	}
+	return nil
+}
+
+// append writes a whole entry to the log, a failed write is cut off so the
+// next entry follows the last whole one
+func (eb *EventLogBackend) append(entry []byte) error {
+	_, err := eb.log.Write(entry)
+	if err == nil {
+		err = eb.log.Sync()
+	}
+	if err != nil {
+		if truncateErr := eb.log.Truncate(eb.size); truncateErr != nil {
+			log.Printf("failed to truncate event log after failed write: %+v", truncateErr)
+		}
+		return fmt.Errorf("failed to write log entry: %w", err)
+	}
+	eb.size += int64(len(entry))
+	return nil
+}
+
+// takeOver starts the log from the state of another store, the ballots of
+// open elections are put in the ballot box before the first snapshot
+func (eb *EventLogBackend) takeOver(state *storage.STV) error {
+	eb.cache = state
+	eb.logged, eb.box = splitBallots(state)
+	if len(eb.box) > 0 {
+		pending, err := eb.writeBallotBox(eb.boxGeneration+1, eb.box)
+		if err != nil {
+			return err
+		}
+		if err = eb.moveBallotBox(pending); err != nil {
+			return err
+		}
+		eb.boxGeneration++
+	}
+	return eb.snapshot()
+}
+
+func (eb *EventLogBackend) ballotBoxPath() string {
+	return filepath.Join(eb.folder, "store.ballots")
+}
+
+// writeBallotBox writes a generation of the ballot box as one record beside
+// the box and returns where, it is moved into place once the entry naming it
+// is logged
+func (eb *EventLogBackend) writeBallotBox(generation uint64, ballots []*storage.Ballot) (string, error) {
+	var buf bytes.Buffer
+	_, err := protodelim.MarshalTo(&buf, &storage.BallotBox{Generation: generation, Ballots: ballots})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ballot box: %w", err)
+	}
+	path := fmt.Sprintf("%s.%d", eb.ballotBoxPath(), generation)
+	if err = writeSynced(path, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write ballot box: %w", err)
+	}
+	return path, nil
+}
+
+// moveBallotBox moves a box written by writeBallotBox into place and opens it
+// for ballots to be appended to it
+func (eb *EventLogBackend) moveBallotBox(pending string) error {
+	// nothing more is appended to the box it replaces, if the box can't be
+	// moved the next change rewrites it again
+	if eb.boxFile != nil {
+		_ = eb.boxFile.Close()
+		eb.boxFile = nil
+	}
+	if err := os.Rename(pending, eb.ballotBoxPath()); err != nil {
+		return fmt.Errorf("failed to move ballot box: %w", err)
+	}
+	eb.boxAppended = 0
+	return eb.openBallotBox()
+}
+
+// openBallotBox opens the ballot box for ballots to be appended to it
+func (eb *EventLogBackend) openBallotBox() error {
+	path := eb.ballotBoxPath()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	eb.boxFile = file
+	eb.boxSize = info.Size()
+	return nil
+}
+
+// appendBallotBox appends the ballots cast in an update to the ballot box as a
+// record of the next generation and returns its length, the box is cut back to
+// the last whole record if the entry naming it isn't logged
+func (eb *EventLogBackend) appendBallotBox(generation uint64, ballots []*storage.Ballot) (int64, error) {
+	var buf bytes.Buffer
+	_, err := protodelim.MarshalTo(&buf, &storage.BallotBox{Generation: generation, Ballots: ballots})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode ballot box: %w", err)
+	}
+	_, err = eb.boxFile.Write(buf.Bytes())
+	if err == nil {
+		err = eb.boxFile.Sync()
+	}
+	if err != nil {
+		eb.truncateBallotBox()
+		return 0, fmt.Errorf("failed to append to ballot box: %w", err)
+	}
+	return int64(buf.Len()), nil
+}
+
+// truncateBallotBox cuts off a record appended to the ballot box by an update
+// that failed, the box is rewritten by the next change if it can't be
+func (eb *EventLogBackend) truncateBallotBox() {
+	if err := eb.boxFile.Truncate(eb.boxSize); err != nil {
+		log.Printf("failed to truncate ballot box after failed update: %+v", err)
+		_ = eb.boxFile.Close()
+		eb.boxFile = nil
+	}
+}
+
+// compactBallotBox rewrites the ballots appended to the ballot box as one
+// record sorted by id, under the generation it already has
+func (eb *EventLogBackend) compactBallotBox() error {
+	if eb.boxAppended == 0 && eb.boxFile != nil {
+		return nil
+	}
+	pending, err := eb.writeBallotBox(eb.boxGeneration, eb.box)
+	if err != nil {
+		return err
+	}
+	if err = eb.moveBallotBox(pending); err != nil {
+		_ = os.Remove(pending)
+		return err
+	}
+	return nil
+}
+
+// castBallots are the ballots added from the old box to the new one, false if
+// the change isn't only ballots being cast. Both boxes are sorted by id.
+func castBallots(old, new []*storage.Ballot) ([]*storage.Ballot, bool) {
+	var cast []*storage.Ballot
+	i := 0
+	for _, ballot := range new {
+		if i < len(old) && old[i].GetId() == ballot.GetId() {
+			if !proto.Equal(old[i], ballot) {
+				return nil, false
+			}
+			i++
+			continue
+		}
+		cast = append(cast, ballot)
+	}
+	return cast, i == len(old)
+}
+
+// readBallotBox adds the ballots in the generation of the box the log names to
+// the replayed state
+func (eb *EventLogBackend) readBallotBox() error {
+	path := eb.ballotBoxPath()
+	records, err := eb.readBallotBoxFile(path)
+	if err != nil {
+		return err
+	}
+	if ballotBoxGeneration(records) != eb.boxGeneration {
+		// the entry naming the box was logged but the box wasn't moved into place
+		pending := fmt.Sprintf("%s.%d", path, eb.boxGeneration)
+		records, err = eb.readBallotBoxFile(pending)
+		if err != nil {
+			return err
+		}
+		if ballotBoxGeneration(records) != eb.boxGeneration {
+			return fmt.Errorf("missing ballot box %d named by the log", eb.boxGeneration)
+		}
+		if err = os.Rename(pending, path); err != nil {
+			return fmt.Errorf("failed to move ballot box: %w", err)
+		}
+	}
+
+	// boxes written by updates that failed to log
+	stale, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return fmt.Errorf("failed to find old ballot boxes: %w", err)
+	}
+	for _, file := range stale {
+		if err = os.Remove(file); err != nil {
+			return fmt.Errorf("failed to remove old ballot box: %w", err)
+		}
+	}
+
+	eb.box = nil
+	for _, record := range records {
+		eb.box = append(eb.box, record.GetBallots()...)
+	}
+	slices.SortFunc(eb.box, func(a, b *storage.Ballot) int { return strings.Compare(a.GetId(), b.GetId()) })
+	eb.boxAppended = max(len(records)-1, 0)
+	eb.cache = withBallots(eb.logged, append(slices.Clone(eb.logged.GetBallots()), eb.box...))
+	return nil
+}
+
+// readBallotBoxFile reads the records of a ballot box up to the generation the
+// log names, no box is an empty one. Records after the generation were
+// appended by updates that failed to log and are truncated away with a record
+// cut short.
+func (eb *EventLogBackend) readBallotBoxFile(path string) ([]*storage.BallotBox, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ballot box: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	reader := &countingReader{reader: bufio.NewReader(file)}
+	var records []*storage.BallotBox
+	var whole int64
+	for {
+		var record storage.BallotBox
+		err = protodelim.UnmarshalOptions{MaxSize: -1}.UnmarshalFrom(reader, &record)
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("failed to parse ballot box: %w", err)
+		}
+		if err != nil || record.GetGeneration() > eb.boxGeneration {
+			log.Printf("dropping the ballots appended to %s after generation %d", path, eb.boxGeneration)
+			if err = file.Truncate(whole); err != nil {
+				return nil, fmt.Errorf("failed to truncate %s: %w", path, err)
+			}
+			return records, nil
+		}
+		records = append(records, &record)
+		whole = reader.read
+	}
+}
+
+// ballotBoxGeneration is the generation of the last record of a ballot box
+func ballotBoxGeneration(records []*storage.BallotBox) uint64 {
+	if len(records) == 0 {
+		return 0
+	}
+	return records[len(records)-1].GetGeneration()
+}
+
+// writeSynced writes a file and waits for it to reach the disk
+func writeSynced(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// splitBallots divides the state into what is logged and the ballots of open
+// elections kept in the ballot box. Both are sorted by id, ballots are logged
+// together when their election closes and the order they were cast in would
+// match them to the URLs marked as voted.
+func splitBallots(stv *storage.STV) (*storage.STV, []*storage.Ballot) {
+	open := make(map[string]bool)
+	for _, election := range stv.GetElections() {
+		if election.GetOpen() && !election.GetClosed() {
+			open[election.GetId()] = true
+		}
+	}
+	var logged, box []*storage.Ballot
+	for _, ballot := range stv.GetBallots() {
+		if open[ballot.GetElection()] {
+			box = append(box, ballot)
+		} else {
+			logged = append(logged, ballot)
+		}
+	}
+	byID := func(a, b *storage.Ballot) int { return strings.Compare(a.GetId(), b.GetId()) }
+	slices.SortFunc(logged, byID)
+	slices.SortFunc(box, byID)
+	return withBallots(stv, logged), box
+}
+
+// withBallots is a copy of the state sharing its records but with other ballots
+func withBallots(stv *storage.STV, ballots []*storage.Ballot) *storage.STV {
+	return &storage.STV{
+		Ballots:           ballots,
+		Candidates:        stv.GetCandidates(),
+		Elections:         stv.GetElections(),
+		Urls:              stv.GetUrls(),
+		Voters:            stv.GetVoters(),
+		AllowRegistration: stv.GetAllowRegistration(),
+		SchemaVersion:     stv.GetSchemaVersion(),
+	}
+}
+
+// eventRecords is how one type of record in storage.STV is written to and
+// replayed from events
+type eventRecords interface {
+	// events are the changes from old to new
+	events(old, new *storage.STV) []*storage.Event
+	// apply replays the event if it is about this type of record
+	apply(stv *storage.STV, event *storage.Event) (bool, error)
+}
+
+// eventRecordTypes are in the order their events are written in an entry
+var eventRecordTypes = []eventRecords{
+	recordEvents[*storage.Election]{
+		list:    func(stv *storage.STV) *[]*storage.Election { return &stv.Elections },
+		key:     (*storage.Election).GetId,
+		keyOnly: func(key string) *storage.Election { return &storage.Election{Id: key} },
+		record:  (*storage.Event).GetElection,
+		wrap: func(record *storage.Election) *storage.Event {
+			return &storage.Event{Record: &storage.Event_Election{Election: record}}
+		},
+		added:   EventElectionAdded,
+		changed: EventElectionChanged,
+		removed: EventElectionRemoved,
+		changedKind: func(old, new *storage.Election) EventKind {
+			switch {
+			case !old.GetOpen() && new.GetOpen():
+				return EventElectionOpened
+			case !old.GetClosed() && new.GetClosed():
+				return EventElectionClosed
+			}
+			return ""
+		},
+	},
+	recordEvents[*storage.Candidate]{
+		list:    func(stv *storage.STV) *[]*storage.Candidate { return &stv.Candidates },
+		key:     (*storage.Candidate).GetId,
+		keyOnly: func(key string) *storage.Candidate { return &storage.Candidate{Id: key} },
+		record:  (*storage.Event).GetCandidate,
+		wrap: func(record *storage.Candidate) *storage.Event {
+			return &storage.Event{Record: &storage.Event_Candidate{Candidate: record}}
+		},
+		added:   EventCandidateAdded,
+		changed: EventCandidateChanged,
+		removed: EventCandidateRemoved,
+		changedKind: func(old, new *storage.Candidate) EventKind {
+			if !old.GetWithdrawn() && new.GetWithdrawn() {
+				return EventCandidateWithdrawn
+			}
+			return ""
+		},
+	},
+	recordEvents[*storage.Voter]{
+		list:    func(stv *storage.STV) *[]*storage.Voter { return &stv.Voters },
+		key:     (*storage.Voter).GetEmail,
+		keyOnly: func(key string) *storage.Voter { return &storage.Voter{Email: key} },
+		record:  (*storage.Event).GetVoter,
+		wrap: func(record *storage.Voter) *storage.Event {
+			return &storage.Event{Record: &storage.Event_Voter{Voter: record}}
+		},
+		added:   EventVoterAdded,
+		changed: EventVoterChanged,
+		removed: EventVoterRemoved,
+	},
+	recordEvents[*storage.URL]{
+		list:    func(stv *storage.STV) *[]*storage.URL { return &stv.Urls },
+		key:     (*storage.URL).GetUrl,
+		keyOnly: func(key string) *storage.URL { return &storage.URL{Url: key} },
+		record:  (*storage.Event).GetUrl,
+		wrap: func(record *storage.URL) *storage.Event {
+			return &storage.Event{Record: &storage.Event_Url{Url: record}}
+		},
+		added:   EventURLAdded,
+		changed: EventURLChanged,
+		removed: EventURLRemoved,
+		changedKind: func(old, new *storage.URL) EventKind {
+			voted := proto.Clone(old).(*storage.URL)
+			voted.Voted = true
+			if !old.GetVoted() && proto.Equal(voted, new) {
+				return EventURLVoted
+			}
+			return ""
+		},
+		// the voter is left out so a URLVoted tells nothing of who voted
+		partial: map[EventKind]partialRecord[*storage.URL]{
+			EventURLVoted: {
+				write: func(record *storage.URL) *storage.URL {
+					return &storage.URL{Url: record.GetUrl(), Election: record.GetElection(), Voted: true}
+				},
+				replay: func(existing, _ *storage.URL) *storage.URL {
+					voted := proto.Clone(existing).(*storage.URL)
+					voted.Voted = true
+					return voted
+				},
+			},
+		},
+	},
+	recordEvents[*storage.Ballot]{
+		list:    func(stv *storage.STV) *[]*storage.Ballot { return &stv.Ballots },
+		key:     (*storage.Ballot).GetId,
+		keyOnly: func(key string) *storage.Ballot { return &storage.Ballot{Id: key} },
+		record:  (*storage.Event).GetBallot,
+		wrap: func(record *storage.Ballot) *storage.Event {
+			return &storage.Event{Record: &storage.Event_Ballot{Ballot: record}}
+		},
+		added:   EventBallotCast,
+		changed: EventBallotChanged,
+		removed: EventBallotRemoved,
+	},
+	registrationEvents{},
+	schemaVersionEvents{},
+}
+
+type recordEvents[T proto.Message] struct {
+	list    func(stv *storage.STV) *[]T
+	key     func(record T) string
+	keyOnly func(key string) T
+	record  func(event *storage.Event) T
+	wrap    func(record T) *storage.Event
+	added   EventKind
+	changed EventKind
+	removed EventKind
+	// changedKind names a change more exactly, empty leaves it as changed
+	changedKind func(old, new T) EventKind
+	// partial are the kinds of change written with only part of the record
+	partial map[EventKind]partialRecord[T]
+}
+
+// partialRecord is how a change is written with only part of the record, and
+// replayed onto the whole record already in the state
+type partialRecord[T proto.Message] struct {
+	write  func(record T) T
+	replay func(existing, record T) T
+}
+
+func (re recordEvents[T]) event(kind EventKind, record T) *storage.Event {
+	event := re.wrap(record)
+	event.Kind = string(kind)
+	return event
+}
+
+func (re recordEvents[T]) events(old, new *storage.STV) []*storage.Event {
+	previous := make(map[string]T, len(*re.list(old)))
+	for _, record := range *re.list(old) {
+		previous[re.key(record)] = record
+	}
+
+	var events []*storage.Event
+	current := make(map[string]bool, len(*re.list(new)))
+	for _, record := range *re.list(new) {
+		key := re.key(record)
+		current[key] = true
+		before, ok := previous[key]
+		switch {
+		case !ok:
+			events = append(events, re.event(re.added, record))
+		case !proto.Equal(before, record):
+			kind := re.changed
+			if re.changedKind != nil {
+				if exact := re.changedKind(before, record); exact != "" {
+					kind = exact
+				}
+			}
+			if partial, ok := re.partial[kind]; ok {
+				record = partial.write(record)
+			}
+			events = append(events, re.event(kind, record))
+		}
+	}
+	for _, record := range *re.list(old) {
+		if key := re.key(record); !current[key] {
+			events = append(events, re.event(re.removed, re.keyOnly(key)))
+		}
+	}
+	return events
+}
+
+func (re recordEvents[T]) apply(stv *storage.STV, event *storage.Event) (bool, error) {
+	record := re.record(event)
+	if !record.ProtoReflect().IsValid() {
+		return false, nil
+	}
+	list := re.list(stv)
+
+	if EventKind(event.GetKind()) == re.added {
+		*list = append(*list, record)
+		return true, nil
+	}
+
+	key := re.key(record)
+	index := slices.IndexFunc(*list, func(existing T) bool { return re.key(existing) == key })
+	if index < 0 {
+		return true, fmt.Errorf("%s of unknown record %s", event.GetKind(), key)
+	}
+	kind := EventKind(event.GetKind())
+	switch partial, ok := re.partial[kind]; {
+	case kind == re.removed:
+		*list = slices.Delete(*list, index, index+1)
+	case ok:
+		(*list)[index] = partial.replay((*list)[index], record)
+	default:
+		(*list)[index] = record
+	}
+	return true, nil
+}
+
+type registrationEvents struct{}
+
+func (registrationEvents) events(old, new *storage.STV) []*storage.Event {
+	if old.GetAllowRegistration() == new.GetAllowRegistration() {
+		return nil
+	}
+	return []*storage.Event{{
+		Kind:   string(EventRegistrationChanged),
+		Record: &storage.Event_AllowRegistration{AllowRegistration: new.GetAllowRegistration()},
+	}}
+}
+
+func (registrationEvents) apply(stv *storage.STV, event *storage.Event) (bool, error) {
+	if _, ok := event.GetRecord().(*storage.Event_AllowRegistration); !ok {
+		return false, nil
+	}
+	stv.AllowRegistration = event.GetAllowRegistration()
+	return true, nil
+}
+
+// schemaVersionEvents records a migration, the changes it made to records are
+// events of their own in the same entry
+type schemaVersionEvents struct{}
+
+func (schemaVersionEvents) events(old, new *storage.STV) []*storage.Event {
+	if old.GetSchemaVersion() == new.GetSchemaVersion() {
+		return nil
+	}
+	return []*storage.Event{{
+		Kind:   string(EventSchemaMigrated),
+		Record: &storage.Event_SchemaVersion{SchemaVersion: new.GetSchemaVersion()},
+	}}
+}
+
+func (schemaVersionEvents) apply(stv *storage.STV, event *storage.Event) (bool, error) {
+	if _, ok := event.GetRecord().(*storage.Event_SchemaVersion); !ok {
+		return false, nil
+	}
+	stv.SchemaVersion = event.GetSchemaVersion()
+	return true, nil
+}
+
+// applyEvent replays an event onto the state
+func applyEvent(stv *storage.STV, event *storage.Event) error {
+	for _, records := range eventRecordTypes {
+		applied, err := records.apply(stv, event)
+		if applied || err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("%s event has no record", event.GetKind())
+}
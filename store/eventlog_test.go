@@ -0,0 +1,332 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+
+	"github.com/ystv/stv-web/storage"
+)
+
+func readLogEntries(t *testing.T) []*storage.LogEntry {
+	t.Helper()
+	file, err := os.Open(filepath.Join("db", "store.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	reader := bufio.NewReader(file)
+	var entries []*storage.LogEntry
+	for {
+		var entry storage.LogEntry
+		err = protodelim.UnmarshalFrom(reader, &entry)
+		if errors.Is(err, io.EOF) {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, &entry)
+	}
+}
+
+func readBallotBox(t *testing.T) []*storage.BallotBox {
+	t.Helper()
+	file, err := os.Open(filepath.Join("db", "store.ballots"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	reader := bufio.NewReader(file)
+	var records []*storage.BallotBox
+	for {
+		var record storage.BallotBox
+		err = protodelim.UnmarshalFrom(reader, &record)
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, &record)
+	}
+}
+
+func ballotIDs(t *testing.T, store *Store, election string) []string {
+	t.Helper()
+	ballots, err := store.GetBallotsElectionID(election)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, 0, len(ballots))
+	for _, ballot := range ballots {
+		ids = append(ids, ballot.GetId())
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestEventLogKeepsBallotsApartFromVoters(t *testing.T) {
+	t.Chdir(t.TempDir())
+	store, err := NewStore(false, BackendEventLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	election, err := store.AddElection(&storage.Election{Name: "Chair", Seats: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidate, err := store.AddCandidate(&storage.Candidate{Election: election.GetId(), Name: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []*storage.URL
+	for _, voter := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		url, err := store.AddURL(&storage.URL{Election: election.GetId(), Voter: voter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		urls = append(urls, url)
+	}
+	if err = store.OpenElection(election.GetId(), 3); err != nil {
+		t.Fatal(err)
+	}
+	for _, url := range urls {
+		_, err = store.CastBallot(url.GetUrl(), &storage.Ballot{Choice: map[uint64]string{0: candidate.GetId()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cast := ballotIDs(t, store, election.GetId())
+
+	for _, entry := range readLogEntries(t) {
+		for _, event := range entry.GetEvents() {
+			if event.GetBallot() != nil {
+				t.Fatalf("%s logged while the election is open", event.GetKind())
+			}
+			if event.GetKind() == string(EventURLVoted) && event.GetUrl().GetVoter() != "" {
+				t.Fatal("URLVoted logged with its voter")
+			}
+		}
+	}
+	// each ballot is appended to the empty box the store started with rather
+	// than the box being rewritten
+	records := readBallotBox(t)
+	if len(records) != len(cast)+1 || len(records[0].GetBallots()) != 0 {
+		t.Fatalf("%d records in the box, want the empty box and a record for each of the %d ballots", len(records), len(cast))
+	}
+	var boxed []string
+	for _, record := range records[1:] {
+		if len(record.GetBallots()) != 1 {
+			t.Fatalf("%d ballots appended together, want one", len(record.GetBallots()))
+		}
+		boxed = append(boxed, record.GetBallots()[0].GetId())
+	}
+	slices.Sort(boxed)
+	if !slices.Equal(boxed, cast) {
+		t.Fatalf("ballots %v in the box, want %v", boxed, cast)
+	}
+
+	reopened, err := NewStore(false, BackendEventLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := ballotIDs(t, reopened, election.GetId()); !slices.Equal(ids, cast) {
+		t.Fatalf("ballots %v after replay while open, want %v", ids, cast)
+	}
+	url, err := reopened.FindURL(urls[0].GetUrl())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !url.GetVoted() || url.GetVoter() != urls[0].GetVoter() {
+		t.Fatal("url not replayed as voted with its voter")
+	}
+
+	if err = store.CloseElection(election.GetId(), &storage.Result{}, false, cast); err != nil {
+		t.Fatal(err)
+	}
+	entries := readLogEntries(t)
+	closing := entries[len(entries)-1]
+	var logged []string
+	for _, event := range closing.GetEvents() {
+		if event.GetUrl() != nil {
+			t.Fatal("ballots logged in the same entry as a url")
+		}
+		if event.GetKind() == string(EventBallotCast) {
+			logged = append(logged, event.GetBallot().GetId())
+		}
+	}
+	if !slices.Equal(logged, cast) {
+		t.Fatalf("ballots %v logged at close, want %v in id order", logged, cast)
+	}
+
+	closed, err := NewStore(false, BackendEventLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := ballotIDs(t, closed, election.GetId()); !slices.Equal(ids, cast) {
+		t.Fatalf("ballots %v after replay once closed, want %v", ids, cast)
+	}
+	records = readBallotBox(t)
+	if len(records) != 1 || len(records[0].GetBallots()) != 0 {
+		t.Fatal("ballots left in the box after the election closed")
+	}
+}
+
+func TestEventLogFindsBallotBoxNotMovedIntoPlace(t *testing.T) {
+	t.Chdir(t.TempDir())
+	store, err := NewStore(false, BackendEventLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	election, err := store.AddElection(&storage.Election{Name: "Chair", Seats: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	url, err := store.AddURL(&storage.URL{Election: election.GetId(), Voter: "a@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.OpenElection(election.GetId(), 1); err != nil {
+		t.Fatal(err)
+	}
+	ballot, err := store.CastBallot(url.GetUrl(), &storage.Ballot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// as if it stopped after logging the entry but before the rename
+	path := filepath.Join("db", "store.ballots")
+	records := readBallotBox(t)
+	generation := records[len(records)-1].GetGeneration()
+	if err = os.Rename(path, fmt.Sprintf("%s.%d", path, generation)); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewStore(false, BackendEventLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := ballotIDs(t, reopened, election.GetId()); !slices.Equal(ids, []string{ballot.GetId()}) {
+		t.Fatalf("ballots %v after replay, want the cast ballot", ids)
+	}
+	if _, err = os.Stat(path); err != nil {
+		t.Fatal("ballot box not moved into place")
+	}
+}
+
+func TestEventLogCompactsBallotBox(t *testing.T) {
+	t.Chdir(t.TempDir())
+	store, err := NewStore(false, BackendEventLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	election, err := store.AddElection(&storage.Election{Name: "Chair", Seats: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []*storage.URL
+	for _, voter := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		url, err := store.AddURL(&storage.URL{Election: election.GetId(), Voter: voter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		urls = append(urls, url)
+	}
+	if err = store.OpenElection(election.GetId(), 3); err != nil {
+		t.Fatal(err)
+	}
+	for _, url := range urls {
+		if _, err = store.CastBallot(url.GetUrl(), &storage.Ballot{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cast := ballotIDs(t, store, election.GetId())
+	records := readBallotBox(t)
+	generation := records[len(records)-1].GetGeneration()
+
+	// as if it stopped after appending a ballot but before logging the entry,
+	// and again part way through appending one
+	var buf bytes.Buffer
+	stray := &storage.BallotBox{Generation: generation + 1, Ballots: []*storage.Ballot{{Id: "stray", Election: election.GetId()}}}
+	if _, err = protodelim.MarshalTo(&buf, stray); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = protodelim.MarshalTo(&buf, stray); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join("db", "store.ballots")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.Write(buf.Bytes()[:buf.Len()-1])
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewStore(false, BackendEventLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := ballotIDs(t, reopened, election.GetId()); !slices.Equal(ids, cast) {
+		t.Fatalf("ballots %v after replay, want %v without the ballots never logged", ids, cast)
+	}
+	if len(readBallotBox(t)) != len(records) {
+		t.Fatal("ballots never logged left in the box")
+	}
+
+	// the snapshot leaves the box as one record in id order
+	backend := reopened.backend.(*EventLogBackend)
+	backend.update.Lock()
+	err = backend.snapshot()
+	backend.update.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	records = readBallotBox(t)
+	if len(records) != 1 || records[0].GetGeneration() != generation {
+		t.Fatalf("%d records in the box after the snapshot, want one of generation %d", len(records), generation)
+	}
+	var boxed []string
+	for _, ballot := range records[0].GetBallots() {
+		boxed = append(boxed, ballot.GetId())
+	}
+	if !slices.Equal(boxed, cast) {
+		t.Fatalf("ballots %v in the compacted box, want %v in id order", boxed, cast)
+	}
+
+	// ballots cast after the snapshot are appended to the compacted box
+	url, err := reopened.AddURL(&storage.URL{Election: election.GetId(), Voter: "d@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = reopened.CastBallot(url.GetUrl(), &storage.Ballot{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(readBallotBox(t)) != 2 {
+		t.Fatal("ballot not appended to the compacted box")
+	}
+	compacted, err := NewStore(false, BackendEventLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := ballotIDs(t, compacted, election.GetId()); len(ids) != len(cast)+1 {
+		t.Fatalf("ballots %v after replay, want the %d cast", ids, len(cast)+1)
+	}
+}
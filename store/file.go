@@ -17,6 +17,8 @@ type FileBackend struct {
 	path  string
 	cache *storage.STV
 	mutex sync.RWMutex
+	// update is held for the whole of an update so they are made one at a time
+	update sync.Mutex
 }
 
 func NewFileBackend(root bool) (Backend, error) {
@@ -101,9 +103,50 @@ func (fb *FileBackend) Read() (*storage.STV, error) {
 	return fb.cache, nil
 }
 
-func (fb *FileBackend) Write(state *storage.STV) error {
+func (fb *FileBackend) Update(fn func(state *storage.STV) error) error {
+	fb.update.Lock()
+	defer fb.update.Unlock()
+	state := copyState(fb.cache)
+	if err := fn(state); err != nil {
+		return err
+	}
+	if err := fb.save(state); err != nil {
+		return err
+	}
 	fb.mutex.Lock()
 	defer fb.mutex.Unlock()
 	fb.cache = state
-	return fb.save(state)
+	return nil
+}
+
+// readFileStore reads the state of an existing file store to be migrated into
+// another backend, no file is an empty state
+func readFileStore(path string) (*storage.STV, error) {
+	var stv storage.STV
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &stv, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s to migrate: %w", path, err)
+	}
+	if err = proto.Unmarshal(data, &stv); err != nil {
+		return nil, fmt.Errorf("failed to parse %s to migrate: %w", path, err)
+	}
+	return &stv, nil
+}
+
+// retireFileStore renames a file store once it has been migrated so it is only
+// migrated once, the file is kept as a backup
+func retireFileStore(path string) error {
+	err := os.Rename(path, path+".migrated")
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move %s after migrating: %w", path, err)
+	}
+	log.Printf("migrated %s, the old file is kept as %s.migrated", path, path)
+	return nil
 }
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ystv/stv-web/storage"
+)
+
+// migration upgrades the state from the schema version before it to version
+type migration struct {
+	version     uint64
+	description string
+	migrate     func(stv *storage.STV) error
+}
+
+// migrations are in version order, each one starting from the version of the
+// one before it. A change to storage.proto that existing states have to be
+// rewritten for adds a migration to the end, older migrations are never changed.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "election excluded voters are referenced by email",
+		migrate:     migrateExcludedVoters,
+	},
+}
+
+// SchemaVersion is the version of storage.proto states are written in
+func SchemaVersion() uint64 {
+	return migrations[len(migrations)-1].version
+}
+
+// checkSchemaVersion fails for a state written by a newer build, it can't be
+// read without losing what the newer schema added
+func checkSchemaVersion(stv *storage.STV) error {
+	if stv.GetSchemaVersion() > SchemaVersion() {
+		return fmt.Errorf("store is schema version %d, newer than the %d this build supports", stv.GetSchemaVersion(), SchemaVersion())
+	}
+	return nil
+}
+
+// migrate upgrades the state step by step to the current schema version
+func migrate(stv *storage.STV) error {
+	if err := checkSchemaVersion(stv); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.version <= stv.GetSchemaVersion() {
+			continue
+		}
+		if err := m.migrate(stv); err != nil {
+			return fmt.Errorf("failed to migrate store to schema version %d, %s: %w", m.version, m.description, err)
+		}
+		stv.SchemaVersion = m.version
+	}
+	return nil
+}
+
+// migrateStore brings the state of a backend up to the current schema version,
+// the state is written to a backup in folder first. A new store has nothing to
+// migrate and is only marked as the current version.
+func migrateStore(backend Backend, folder string) error {
+	stv, err := backend.Read()
+	if err != nil {
+		return err
+	}
+	if err = checkSchemaVersion(stv); err != nil || stv.GetSchemaVersion() == SchemaVersion() {
+		return err
+	}
+
+	if !proto.Equal(stv, &storage.STV{}) {
+		path, err := backupStore(stv, folder)
+		if err != nil {
+			return err
+		}
+		log.Printf("upgrading store from schema version %d to %d, backup at %s", stv.GetSchemaVersion(), SchemaVersion(), path)
+	}
+
+	return backend.Update(func(stv *storage.STV) error {
+		editAll(stv)
+		return migrate(stv)
+	})
+}
+
+// editAll replaces every record with a copy, migrations change the records
+// they upgrade in place
+func editAll(stv *storage.STV) {
+	for i := range stv.GetBallots() {
+		edit(stv.GetBallots(), i)
+	}
+	for i := range stv.GetCandidates() {
+		edit(stv.GetCandidates(), i)
+	}
+	for i := range stv.GetElections() {
+		edit(stv.GetElections(), i)
+	}
+	for i := range stv.GetUrls() {
+		edit(stv.GetUrls(), i)
+	}
+	for i := range stv.GetVoters() {
+		edit(stv.GetVoters(), i)
+	}
+}
+
+// backupStore writes the state in the file store format, it can be put back
+// as store.db to go back to the build before the upgrade
+func backupStore(stv *storage.STV, folder string) (string, error) {
+	out, err := proto.Marshal(stv)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode store backup: %w", err)
+	}
+	path := filepath.Join(folder, fmt.Sprintf("store.v%d.%s.bak", stv.GetSchemaVersion(), time.Now().Format("2006-01-02T15-04-05")))
+	if err = os.WriteFile(path, out, 0600); err != nil {
+		return "", fmt.Errorf("failed to write store backup: %w", err)
+	}
+	return path, nil
+}
+
+// migrateExcludedVoters replaces the copies of excluded voters kept on each
+// election with their emails, the copies went stale when a voter was edited
+func migrateExcludedVoters(stv *storage.STV) error {
+	for _, election := range stv.GetElections() {
+		//nolint:staticcheck // reading the field this migration retires
+		for _, voter := range election.GetExcluded() {
+			if voter.GetEmail() == "" {
+				return fmt.Errorf("election %s excludes a voter without an email", election.GetId())
+			}
+			election.ExcludedVoters = append(election.ExcludedVoters, voter.GetEmail())
+		}
+		//nolint:staticcheck // reading the field this migration retires
+		election.Excluded = nil
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ystv/stv-web/storage"
+)
+
+// installFixture copies a store.db from testdata into ./db, it must be called
+// after the test has changed into its own directory
+func installFixture(t *testing.T, fixture string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir("db", 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join("db", "store.db"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func readBackups(t *testing.T) [][]byte {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("db", "store.v*.bak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backups := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		backups = append(backups, data)
+	}
+	return backups
+}
+
+func TestMigrateExcludedVotersFixture(t *testing.T) {
+	fixture, err := filepath.Abs(filepath.Join("testdata", "v0.store.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kind := range []string{BackendFile, BackendSQLite, BackendEventLog} {
+		t.Run(kind, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			original := installFixture(t, fixture)
+
+			store, err := NewStore(false, kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stv, err := store.Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stv.GetSchemaVersion() != SchemaVersion() {
+				t.Fatalf("schema version %d after migrating, want %d", stv.GetSchemaVersion(), SchemaVersion())
+			}
+			election, err := store.FindElection("chair")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"alice@example.com", "bob@example.com"}; !slices.Equal(election.GetExcludedVoters(), want) {
+				t.Fatalf("excluded voters %v, want %v", election.GetExcludedVoters(), want)
+			}
+			//nolint:staticcheck // the field the migration retires
+			if len(election.GetExcluded()) != 0 {
+				t.Fatal("excluded voter copies kept after migrating")
+			}
+			if len(stv.GetVoters()) != 3 || len(stv.GetCandidates()) != 2 || len(stv.GetUrls()) != 1 || !stv.GetAllowRegistration() {
+				t.Fatal("records lost migrating")
+			}
+
+			backups := readBackups(t)
+			if len(backups) != 1 {
+				t.Fatalf("%d backups, want 1", len(backups))
+			}
+			var want, backup storage.STV
+			if err = proto.Unmarshal(original, &want); err != nil {
+				t.Fatal(err)
+			}
+			if err = proto.Unmarshal(backups[0], &backup); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(&backup, &want) {
+				t.Fatal("backup differs from the store before migrating")
+			}
+
+			// the version is kept, so opening again migrates nothing
+			reopened, err := NewStore(false, kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := reopened.Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(again, stv) {
+				t.Fatal("state changed reopening the migrated store")
+			}
+			if len(readBackups(t)) != 1 {
+				t.Fatal("store migrated again when reopened")
+			}
+		})
+	}
+}
+
+func TestNewStoreIsCurrentSchemaVersion(t *testing.T) {
+	t.Chdir(t.TempDir())
+	store, err := NewStore(false, BackendFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stv, err := store.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stv.GetSchemaVersion() != SchemaVersion() {
+		t.Fatalf("new store is schema version %d, want %d", stv.GetSchemaVersion(), SchemaVersion())
+	}
+	if len(readBackups(t)) != 0 {
+		t.Fatal("new store backed up")
+	}
+}
+
+func TestNewerSchemaVersionRefused(t *testing.T) {
+	t.Chdir(t.TempDir())
+	out, err := proto.Marshal(&storage.STV{SchemaVersion: SchemaVersion() + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir("db", 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join("db", "store.db"), out, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewStore(false, BackendFile); err == nil {
+		t.Fatal("store from a newer schema version opened")
+	}
+}
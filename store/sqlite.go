@@ -0,0 +1,314 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	_ "modernc.org/sqlite" // pure Go, builds with CGO_ENABLED=0
+
+	"github.com/ystv/stv-web/storage"
+)
+
+// SQLiteBackend keeps the store in a SQLite database with a table for each
+// kind of record. A write only touches the rows of the records an update
+// replaced or removed rather than rewriting every record like FileBackend.
+type SQLiteBackend struct {
+	db    *sql.DB
+	cache *storage.STV
+	mutex sync.RWMutex
+	// update is held for the whole of an update so they are made one at a time
+	update sync.Mutex
+}
+
+// sqliteTable is one kind of record, each row holds the whole encoded message
+// in data with the key and columns alongside for querying the database directly
+type sqliteTable struct {
+	name    string
+	key     string
+	columns []string
+	rows    func(stv *storage.STV) []sqliteRow
+	load    func(stv *storage.STV, data []byte) error
+}
+
+type sqliteRow struct {
+	key     string
+	columns []any
+	message proto.Message
+}
+
+func newSQLiteTable[M any, T interface {
+	*M
+	proto.Message
+}](name, key string, columns []string, list func(stv *storage.STV) *[]T, row func(message T) (string, []any)) sqliteTable {
+	return sqliteTable{
+		name:    name,
+		key:     key,
+		columns: columns,
+		rows: func(stv *storage.STV) []sqliteRow {
+			messages := *list(stv)
+			rows := make([]sqliteRow, 0, len(messages))
+			for _, message := range messages {
+				key, columns := row(message)
+				rows = append(rows, sqliteRow{key: key, columns: columns, message: message})
+			}
+			return rows
+		},
+		load: func(stv *storage.STV, data []byte) error {
+			message := T(new(M))
+			if err := proto.Unmarshal(data, message); err != nil {
+				return err
+			}
+			messages := list(stv)
+			*messages = append(*messages, message)
+			return nil
+		},
+	}
+}
+
+// sqliteTables are in the order of storage.STV, rows are read back in the
+// order they were first written so the slices keep their order
+var sqliteTables = []sqliteTable{
+	newSQLiteTable("ballots", "id", []string{"election"},
+		func(stv *storage.STV) *[]*storage.Ballot { return &stv.Ballots },
+		func(ballot *storage.Ballot) (string, []any) {
+			return ballot.GetId(), []any{ballot.GetElection()}
+		}),
+	newSQLiteTable("candidates", "id", []string{"election", "name"},
+		func(stv *storage.STV) *[]*storage.Candidate { return &stv.Candidates },
+		func(candidate *storage.Candidate) (string, []any) {
+			return candidate.GetId(), []any{candidate.GetElection(), candidate.GetName()}
+		}),
+	newSQLiteTable("elections", "id", []string{"name"},
+		func(stv *storage.STV) *[]*storage.Election { return &stv.Elections },
+		func(election *storage.Election) (string, []any) {
+			return election.GetId(), []any{election.GetName()}
+		}),
+	newSQLiteTable("urls", "url", []string{"election", "voter", "voted"},
+		func(stv *storage.STV) *[]*storage.URL { return &stv.Urls },
+		func(url *storage.URL) (string, []any) {
+			return url.GetUrl(), []any{url.GetElection(), url.GetVoter(), url.GetVoted()}
+		}),
+	newSQLiteTable("voters", "email", []string{"name"},
+		func(stv *storage.STV) *[]*storage.Voter { return &stv.Voters },
+		func(voter *storage.Voter) (string, []any) {
+			return voter.GetEmail(), []any{voter.GetName()}
+		}),
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS ballots (id TEXT PRIMARY KEY, election TEXT NOT NULL, data BLOB NOT NULL);
+CREATE INDEX IF NOT EXISTS ballots_election ON ballots (election);
+CREATE TABLE IF NOT EXISTS candidates (id TEXT PRIMARY KEY, election TEXT NOT NULL, name TEXT NOT NULL, data BLOB NOT NULL);
+CREATE INDEX IF NOT EXISTS candidates_election ON candidates (election);
+CREATE TABLE IF NOT EXISTS elections (id TEXT PRIMARY KEY, name TEXT NOT NULL, data BLOB NOT NULL);
+CREATE TABLE IF NOT EXISTS urls (url TEXT PRIMARY KEY, election TEXT NOT NULL, voter TEXT NOT NULL, voted INTEGER NOT NULL, data BLOB NOT NULL);
+CREATE INDEX IF NOT EXISTS urls_election ON urls (election);
+CREATE TABLE IF NOT EXISTS voters (email TEXT PRIMARY KEY, name TEXT NOT NULL, data BLOB NOT NULL);
+CREATE TABLE IF NOT EXISTS settings (key TEXT PRIMARY KEY, value TEXT NOT NULL);
+`
+
+func NewSQLiteBackend(root bool) (Backend, error) {
+	folder := "./db"
+	if root {
+		folder = "/db"
+	}
+	err := os.MkdirAll(folder, 0777)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make folder %s: %w", folder, err)
+	}
+
+	path := filepath.Join(folder, "store.sqlite")
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	// writes are serialised by the backend, one connection keeps them in order
+	db.SetMaxOpenConns(1)
+
+	sb := &SQLiteBackend{db: db}
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create sqlite tables: %w", err)
+	}
+
+	var settings int
+	if err = db.QueryRow("SELECT COUNT(*) FROM settings").Scan(&settings); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to read sqlite settings: %w", err)
+	}
+
+	filePath := filepath.Join(folder, "store.db")
+	if settings == 0 {
+		// a new database takes over from the file store if there is one
+		var state *storage.STV
+		state, err = readFileStore(filePath)
+		if err == nil {
+			err = sb.save(&storage.STV{}, state)
+		}
+		if err == nil {
+			err = retireFileStore(filePath)
+		}
+		sb.cache = state
+	} else {
+		sb.cache, err = sb.read()
+	}
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	log.Printf("db sqlite from: %s", path)
+	return sb, nil
+}
+
+// read loads every table into the store state
+func (sb *SQLiteBackend) read() (*storage.STV, error) {
+	var stv storage.STV
+	for _, table := range sqliteTables {
+		rows, err := sb.db.Query(fmt.Sprintf("SELECT %s, data FROM %s ORDER BY rowid", table.key, table.name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table.name, err)
+		}
+		for rows.Next() {
+			var key string
+			var data []byte
+			if err = rows.Scan(&key, &data); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("failed to read %s: %w", table.name, err)
+			}
+			if err = table.load(&stv, data); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("failed to parse %s %s: %w", table.name, key, err)
+			}
+		}
+		if err = rows.Close(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table.name, err)
+		}
+	}
+
+	settings, err := sb.db.Query("SELECT key, value FROM settings")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	defer func() {
+		_ = settings.Close()
+	}()
+	for settings.Next() {
+		var key, value string
+		if err = settings.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to read settings: %w", err)
+		}
+		switch key {
+		case "allowRegistration":
+			stv.AllowRegistration = value == "true"
+		case "schemaVersion":
+			// databases from before schema versions have no row and are version 0
+			stv.SchemaVersion, err = strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse schema version: %w", err)
+			}
+		}
+	}
+	if err = settings.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	return &stv, nil
+}
+
+// save writes the change from the saved state old to stv in one transaction,
+// nothing is changed if it fails. Records are compared by identity, a record
+// in stv that isn't the one in old under its key was added or replaced by the
+// update and is written, a key of old missing from stv is deleted.
+func (sb *SQLiteBackend) save(old, stv *storage.STV) error {
+	tx, err := sb.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin sqlite write: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, table := range sqliteTables {
+		previous := make(map[string]proto.Message)
+		for _, row := range table.rows(old) {
+			previous[row.key] = row.message
+		}
+
+		columns := append([]string{table.key}, table.columns...)
+		columns = append(columns, "data")
+		updates := make([]string, 0, len(columns)-1)
+		for _, column := range columns[1:] {
+			updates = append(updates, column+" = excluded."+column)
+		}
+		upsert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (?%s) ON CONFLICT (%s) DO UPDATE SET %s",
+			table.name, strings.Join(columns, ", "), strings.Repeat(", ?", len(columns)-1), table.key, strings.Join(updates, ", "))
+
+		for _, row := range table.rows(stv) {
+			message, ok := previous[row.key]
+			delete(previous, row.key)
+			if ok && message == row.message {
+				continue
+			}
+			data, err := proto.MarshalOptions{Deterministic: true}.Marshal(row.message)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s %s: %w", table.name, row.key, err)
+			}
+			args := append([]any{row.key}, row.columns...)
+			args = append(args, data)
+			if _, err = tx.Exec(upsert, args...); err != nil {
+				return fmt.Errorf("failed to write %s %s: %w", table.name, row.key, err)
+			}
+		}
+
+		for key := range previous {
+			_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", table.name, table.key), key)
+			if err != nil {
+				return fmt.Errorf("failed to delete %s %s: %w", table.name, key, err)
+			}
+		}
+	}
+
+	for key, value := range map[string]string{
+		"allowRegistration": strconv.FormatBool(stv.GetAllowRegistration()),
+		"schemaVersion":     strconv.FormatUint(stv.GetSchemaVersion(), 10),
+	} {
+		_, err = tx.Exec("INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value", key, value)
+		if err != nil {
+			return fmt.Errorf("failed to write settings: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sqlite write: %w", err)
+	}
+	return nil
+}
+
+func (sb *SQLiteBackend) Read() (*storage.STV, error) {
+	sb.mutex.RLock()
+	defer sb.mutex.RUnlock()
+	return sb.cache, nil
+}
+
+func (sb *SQLiteBackend) Update(fn func(state *storage.STV) error) error {
+	sb.update.Lock()
+	defer sb.update.Unlock()
+	state := copyState(sb.cache)
+	if err := fn(state); err != nil {
+		return err
+	}
+	if err := sb.save(sb.cache, state); err != nil {
+		return err
+	}
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	sb.cache = state
+	return nil
+}
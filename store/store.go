@@ -3,523 +3,800 @@ package store
 import (
 	"fmt"
 	"log"
+	"slices"
 
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/ystv/stv-web/storage"
 )
 
+// Store reads and changes the state kept by a Backend. Every change is made
+// in a single Backend.Update, and every record returned is a copy the caller
+// can change without affecting the store.
 type Store struct {
 	backend Backend
 }
 
-func NewStore(root bool) (*Store, error) {
-	backend, err := NewFileBackend(root)
+// the backends the store can be kept in
+const (
+	BackendFile     = "file"
+	BackendSQLite   = "sqlite"
+	BackendEventLog = "eventlog"
+)
+
+// NewStore opens the store in the kind of backend given, empty is a file
+func NewStore(root bool, kind string) (*Store, error) {
+	var backend Backend
+	var err error
+	switch kind {
+	case "", BackendFile:
+		backend, err = NewFileBackend(root)
+	case BackendSQLite:
+		backend, err = NewSQLiteBackend(root)
+	case BackendEventLog:
+		backend, err = NewEventLogBackend(root)
+	default:
+		return nil, fmt.Errorf("unknown store backend: %s", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	folder := "./db"
+	if root {
+		folder = "/db"
+	}
+	err = migrateStore(backend, folder)
 	if err != nil {
 		return nil, err
 	}
 	return &Store{backend: backend}, nil
 }
 
+// clone copies a record read from the shared state before it is returned
+func clone[T proto.Message](message T) T {
+	return proto.Clone(message).(T)
+}
+
+// cloneAll copies the records read from the shared state before they are returned
+func cloneAll[T proto.Message](messages []T) []T {
+	copies := make([]T, 0, len(messages))
+	for _, message := range messages {
+		copies = append(copies, clone(message))
+	}
+	return copies
+}
+
+// edit replaces the record at index with a copy for an update to change, the
+// records of the state are shared with readers and never changed in place
+func edit[T proto.Message](records []T, index int) T {
+	records[index] = clone(records[index])
+	return records[index]
+}
+
 func (store *Store) GetBallotsElectionID(id string) ([]*storage.Ballot, error) {
-	stv, err := store.Get()
+	stv, err := store.backend.Read()
 	if err != nil {
 		return nil, err
 	}
 	var ballots []*storage.Ballot
 	for _, ballot := range stv.GetBallots() {
 		if ballot.GetElection() == id {
-			ballots = append(ballots, ballot)
+			ballots = append(ballots, clone(ballot))
 		}
 	}
 	return ballots, nil
 }
 
 func (store *Store) AddBallot(ballot *storage.Ballot) (*storage.Ballot, error) {
-	stv, err := store.Get()
-	if err != nil {
-		return nil, err
-	}
-
-beforeUUID:
-	ballot.Id = uuid.NewString()
-
-	for _, b := range stv.GetBallots() {
-		if b.GetId() == ballot.GetId() {
-			log.Println("duplicate ballot id, retrying...")
-			goto beforeUUID
+	err := store.backend.Update(func(stv *storage.STV) error {
+	beforeUUID:
+		ballot.Id = uuid.NewString()
+
+		for _, b := range stv.GetBallots() {
+			if b.GetId() == ballot.GetId() {
+				log.Println("duplicate ballot id, retrying...")
+				goto beforeUUID
+			}
 		}
-	}
 
-	for _, election := range stv.GetElections() {
-		if election.GetId() == ballot.GetElection() {
-			stv.Ballots = append(stv.GetBallots(), ballot)
-
-			if err = store.backend.Write(stv); err != nil {
-				return nil, err
+		for _, election := range stv.GetElections() {
+			if election.GetId() == ballot.GetElection() {
+				stv.Ballots = append(stv.GetBallots(), clone(ballot))
+				return nil
 			}
-
-			return ballot, nil
 		}
-	}
 
-	return nil, fmt.Errorf("unable to find election fot AddBallot")
-}
-
-func (store *Store) EditBallot(ballot *storage.Ballot) (*storage.Ballot, error) {
-	stv, err := store.backend.Read()
+		return fmt.Errorf("unable to find election fot AddBallot")
+	})
 	if err != nil {
 		return nil, err
 	}
+	return ballot, nil
+}
 
-	for _, b := range stv.GetBallots() {
-		if b.GetId() == ballot.GetId() {
-			b.Choice = ballot.GetChoice()
-			if err = store.backend.Write(stv); err != nil {
-				return nil, err
+func (store *Store) EditBallot(ballot *storage.Ballot) (*storage.Ballot, error) {
+	var edited *storage.Ballot
+	err := store.backend.Update(func(stv *storage.STV) error {
+		for i, b := range stv.GetBallots() {
+			if b.GetId() == ballot.GetId() {
+				b = edit(stv.GetBallots(), i)
+				b.Choice = ballot.GetChoice()
+				edited = clone(b)
+				return nil
 			}
-			return b, nil
 		}
+		return fmt.Errorf("unable to find ballot for EditBallot")
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("unable to find ballot for EditBallot")
+	return edited, nil
 }
 
 func (store *Store) DeleteBallot(id string) error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-	s := stv.GetBallots()
-	found := false
-	var index int
-	var ballots *storage.Ballot
-	for index, ballots = range s {
-		if ballots.GetId() == id {
-			found = true
-			break
+	return store.backend.Update(func(stv *storage.STV) error {
+		s := stv.GetBallots()
+		found := false
+		var index int
+		var ballots *storage.Ballot
+		for index, ballots = range s {
+			if ballots.GetId() == id {
+				found = true
+				break
+			}
 		}
-	}
 
-	if found {
-		copy(s[index:], s[index+1:]) // Shift a[i+1:] left one index
-		s[len(s)-1] = nil            // Erase last element (write zero value)
-		stv.Ballots = s[:len(s)-1]   // Truncate slice
+		if found {
+			copy(s[index:], s[index+1:]) // Shift a[i+1:] left one index
+			s[len(s)-1] = nil            // Erase last element (write zero value)
+			stv.Ballots = s[:len(s)-1]   // Truncate slice
 
-		return store.backend.Write(stv)
-	}
-	return fmt.Errorf("ballot not found for DeleteBallot")
+			return nil
+		}
+		return fmt.Errorf("ballot not found for DeleteBallot")
+	})
 }
 
 func (store *Store) DeleteAllBallots() error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-
-	stv.Ballots = []*storage.Ballot{}
-
-	return store.backend.Write(stv)
+	return store.backend.Update(func(stv *storage.STV) error {
+		stv.Ballots = []*storage.Ballot{}
+		return nil
+	})
 }
 
 func (store *Store) GetCandidatesElectionID(id string) ([]*storage.Candidate, error) {
-	stv, err := store.Get()
+	stv, err := store.backend.Read()
 	if err != nil {
 		return nil, err
 	}
 	var candidates []*storage.Candidate
 	for _, candidate := range stv.GetCandidates() {
 		if candidate.GetElection() == id {
-			candidates = append(candidates, candidate)
+			candidates = append(candidates, clone(candidate))
 		}
 	}
 	return candidates, nil
 }
 
 func (store *Store) FindCandidate(id string) (*storage.Candidate, error) {
-	stv, err := store.Get()
+	stv, err := store.backend.Read()
 	if err != nil {
 		return nil, err
 	}
 	for _, c1 := range stv.GetCandidates() {
 		if c1.GetId() == id {
-			return c1, nil
+			return clone(c1), nil
 		}
 	}
 	return nil, fmt.Errorf("unable to find candidate for FindCandidate: %s", id)
 }
 
 func (store *Store) AddCandidate(candidate *storage.Candidate) (*storage.Candidate, error) {
-	stv, err := store.Get()
-	if err != nil {
-		return nil, err
-	}
-
-beforeUUID:
-	candidate.Id = uuid.NewString()
-
-	for _, c := range stv.GetCandidates() {
-		if c.GetId() == candidate.GetId() {
-			log.Println("duplicate candidate id, retrying...")
-			goto beforeUUID
+	err := store.backend.Update(func(stv *storage.STV) error {
+	beforeUUID:
+		candidate.Id = uuid.NewString()
+
+		for _, c := range stv.GetCandidates() {
+			if c.GetId() == candidate.GetId() {
+				log.Println("duplicate candidate id, retrying...")
+				goto beforeUUID
+			}
 		}
-	}
 
-	stv.Candidates = append(stv.GetCandidates(), candidate)
-
-	if err = store.backend.Write(stv); err != nil {
+		stv.Candidates = append(stv.GetCandidates(), clone(candidate))
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-
 	return candidate, nil
 }
 
 func (store *Store) DeleteCandidate(id string) error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-	s := stv.GetCandidates()
-	found := false
-	var index int
-	var candidate *storage.Candidate
-	for index, candidate = range s {
-		if candidate.GetId() == id {
-			found = true
-			break
+	return store.backend.Update(func(stv *storage.STV) error {
+		s := stv.GetCandidates()
+		found := false
+		var index int
+		var candidate *storage.Candidate
+		for index, candidate = range s {
+			if candidate.GetId() == id {
+				found = true
+				break
+			}
 		}
-	}
 
-	if found {
-		copy(s[index:], s[index+1:])  // Shift a[i+1:] left one index
-		s[len(s)-1] = nil             // Erase last element (write zero value)
-		stv.Candidates = s[:len(s)-1] // Truncate slice
+		if found {
+			copy(s[index:], s[index+1:])  // Shift a[i+1:] left one index
+			s[len(s)-1] = nil             // Erase last element (write zero value)
+			stv.Candidates = s[:len(s)-1] // Truncate slice
 
-		return store.backend.Write(stv)
+			return nil
+		}
+		return fmt.Errorf("candidate not found for DeleteCandidate")
+	})
+}
+
+// WithdrawCandidate marks a candidate as withdrawn, they stay on the ballots
+// already cast but are excluded before the first round of the count
+func (store *Store) WithdrawCandidate(id string) (*storage.Candidate, error) {
+	var withdrawn *storage.Candidate
+	err := store.backend.Update(func(stv *storage.STV) error {
+		for i, c1 := range stv.GetCandidates() {
+			if c1.GetId() == id {
+				if c1.GetWithdrawn() {
+					return fmt.Errorf("candidate already withdrawn for WithdrawCandidate")
+				}
+				c1 = edit(stv.GetCandidates(), i)
+				c1.Withdrawn = true
+				withdrawn = clone(c1)
+				return nil
+			}
+		}
+		return fmt.Errorf("candidate not found for WithdrawCandidate")
+	})
+	if err != nil {
+		return nil, err
 	}
-	return fmt.Errorf("candidate not found for DeleteCandidate")
+	return withdrawn, nil
 }
 
 func (store *Store) DeleteAllCandidates() error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-
-	stv.Candidates = []*storage.Candidate{}
-
-	return store.backend.Write(stv)
+	return store.backend.Update(func(stv *storage.STV) error {
+		stv.Candidates = []*storage.Candidate{}
+		return nil
+	})
 }
 
 func (store *Store) GetElections() ([]*storage.Election, error) {
-	stv, err := store.Get()
+	stv, err := store.backend.Read()
 	if err != nil {
 		return nil, err
 	}
-	return stv.GetElections(), err
+	return cloneAll(stv.GetElections()), nil
 }
 
 func (store *Store) FindElection(id string) (*storage.Election, error) {
-	stv, err := store.Get()
+	stv, err := store.backend.Read()
 	if err != nil {
 		return nil, err
 	}
 	for _, e1 := range stv.GetElections() {
 		if e1.GetId() == id {
-			return e1, nil
+			return clone(e1), nil
 		}
 	}
 	return nil, fmt.Errorf("unable to find election for FindElection")
 }
 
 func (store *Store) AddElection(election *storage.Election) (*storage.Election, error) {
-	stv, err := store.Get()
+	err := store.backend.Update(func(stv *storage.STV) error {
+	beforeUUID:
+		election.Id = uuid.NewString()
+
+		for _, e := range stv.GetElections() {
+			if e.GetId() == election.GetId() {
+				log.Println("duplicate election id, retrying...")
+				goto beforeUUID
+			}
+		}
+
+		election.Open = false
+		election.Closed = false
+
+		stv.Elections = append(stv.GetElections(), clone(election))
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return election, nil
+}
 
-beforeUUID:
-	election.Id = uuid.NewString()
-
-	for _, e := range stv.GetBallots() {
-		if e.GetId() == election.GetId() {
-			log.Println("duplicate election id, retrying...")
-			goto beforeUUID
+// ImportElection adds an open election with its candidates and ballots in a
+// single write, ballot choices refer to the candidates by their position in
+// candidates and are replaced with the candidate ids given here
+func (store *Store) ImportElection(election *storage.Election, candidates []*storage.Candidate, ballots []*storage.Ballot) (*storage.Election, error) {
+	err := store.backend.Update(func(stv *storage.STV) error {
+		ids := make(map[string]bool)
+		for _, e := range stv.GetElections() {
+			ids[e.GetId()] = true
 		}
-	}
+		for _, c := range stv.GetCandidates() {
+			ids[c.GetId()] = true
+		}
+		for _, b := range stv.GetBallots() {
+			ids[b.GetId()] = true
+		}
+		newID := func() string {
+			for {
+				id := uuid.NewString()
+				if !ids[id] {
+					ids[id] = true
+					return id
+				}
+				log.Println("duplicate id, retrying...")
+			}
+		}
+
+		election.Id = newID()
+		election.Open = true
+		election.Closed = false
+		election.Imported = true
 
-	election.Open = false
-	election.Closed = false
+		candidateIDs := make(map[string]string, len(candidates))
+		for i, candidate := range candidates {
+			candidate.Id = newID()
+			candidate.Election = election.GetId()
+			candidateIDs[fmt.Sprint(i)] = candidate.GetId()
+		}
 
-	stv.Elections = append(stv.GetElections(), election)
+		for _, ballot := range ballots {
+			ballot.Id = newID()
+			ballot.Election = election.GetId()
+			for rank, choice := range ballot.GetChoice() {
+				if choice == "R.O.N." {
+					continue
+				}
+				id, ok := candidateIDs[choice]
+				if !ok {
+					return fmt.Errorf("unknown candidate %s for ImportElection", choice)
+				}
+				ballot.Choice[rank] = id
+			}
+		}
 
-	if err = store.backend.Write(stv); err != nil {
+		stv.Elections = append(stv.GetElections(), clone(election))
+		stv.Candidates = append(stv.GetCandidates(), cloneAll(candidates)...)
+		stv.Ballots = append(stv.GetBallots(), cloneAll(ballots)...)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-
 	return election, nil
 }
 
 func (store *Store) EditElection(election *storage.Election) (*storage.Election, error) {
-	stv, err := store.backend.Read()
+	var edited *storage.Election
+	err := store.backend.Update(func(stv *storage.STV) error {
+		for i, e := range stv.GetElections() {
+			if e.GetId() == election.GetId() {
+				e = edit(stv.GetElections(), i)
+				e.Name = election.GetName()
+				e.Description = election.GetDescription()
+				e.Ron = election.GetRon()
+				e.Seats = election.GetSeats()
+				e.Open = election.GetOpen()
+				e.Closed = election.GetClosed()
+				e.Result = clone(election.GetResult())
+				e.ExcludedVoters = slices.Clone(election.GetExcludedVoters())
+				e.Method = election.GetMethod()
+				e.Arithmetic = election.GetArithmetic()
+				e.DecimalPlaces = election.GetDecimalPlaces()
+				e.Quota = election.GetQuota()
+				e.SurplusRule = election.GetSurplusRule()
+				e.BallotType = election.GetBallotType()
+				e.Threshold = election.GetThreshold()
+				e.Quorum = election.GetQuorum()
+				e.CompareMethod = election.GetCompareMethod()
+				e.BatchElimination = election.GetBatchElimination()
+				e.SetAsideInvalid = election.GetSetAsideInvalid()
+				edited = clone(e)
+				return nil
+			}
+		}
+		return fmt.Errorf("election not found for EditElection")
+	})
 	if err != nil {
 		return nil, err
 	}
+	return edited, nil
+}
 
-	for _, e := range stv.GetElections() {
-		if e.GetId() == election.GetId() {
-			e.Name = election.GetName()
-			e.Description = election.GetDescription()
-			e.Ron = election.GetRon()
-			e.Seats = election.GetSeats()
-			e.Open = election.GetOpen()
-			e.Closed = election.GetClosed()
-			e.Result = election.GetResult()
-			e.Excluded = election.GetExcluded()
-			if err = store.backend.Write(stv); err != nil {
-				return nil, err
+// AddCategory adds a category to an election that hasn't opened and puts the
+// candidates with the given ids in it
+func (store *Store) AddCategory(id string, category *storage.Category, candidates []string) error {
+	return store.backend.Update(func(stv *storage.STV) error {
+		for i, e1 := range stv.GetElections() {
+			if e1.GetId() == id {
+				if e1.GetOpen() || e1.GetClosed() {
+					return fmt.Errorf("cannot add category to open or closed election for AddCategory")
+				}
+				for _, c1 := range e1.GetCategories() {
+					if c1.GetName() == category.GetName() {
+						return fmt.Errorf("duplicate category for AddCategory")
+					}
+				}
+				for _, candidate := range candidates {
+					found := false
+					for j, c1 := range stv.GetCandidates() {
+						if c1.GetId() == candidate && c1.GetElection() == id {
+							c1 = edit(stv.GetCandidates(), j)
+							c1.Categories = append(c1.GetCategories(), category.GetName())
+							found = true
+						}
+					}
+					if !found {
+						return fmt.Errorf("candidate not found for AddCategory")
+					}
+				}
+				e1 = edit(stv.GetElections(), i)
+				e1.Categories = append(e1.GetCategories(), clone(category))
+				return nil
 			}
-			return e, nil
 		}
-	}
-	return nil, fmt.Errorf("election not found for EditElection")
+		return fmt.Errorf("election not found for AddCategory")
+	})
+}
+
+// DeleteCategory removes a category from an election that hasn't opened and
+// takes every candidate out of it
+func (store *Store) DeleteCategory(id, name string) error {
+	return store.backend.Update(func(stv *storage.STV) error {
+		for i, e1 := range stv.GetElections() {
+			if e1.GetId() == id {
+				if e1.GetOpen() || e1.GetClosed() {
+					return fmt.Errorf("cannot delete category of open or closed election for DeleteCategory")
+				}
+				categories := slices.DeleteFunc(slices.Clone(e1.GetCategories()), func(c1 *storage.Category) bool {
+					return c1.GetName() == name
+				})
+				if len(categories) == len(e1.GetCategories()) {
+					return fmt.Errorf("category not found for DeleteCategory")
+				}
+				e1 = edit(stv.GetElections(), i)
+				e1.Categories = categories
+				for j, c1 := range stv.GetCandidates() {
+					if c1.GetElection() == id && slices.Contains(c1.GetCategories(), name) {
+						c1 = edit(stv.GetCandidates(), j)
+						c1.Categories = slices.DeleteFunc(c1.GetCategories(), func(category string) bool {
+							return category == name
+						})
+					}
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("election not found for DeleteCategory")
+	})
 }
 
-func (store *Store) OpenElection(id string) error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-
-	for _, e1 := range stv.GetElections() {
-		if e1.GetId() == id {
-			if e1.GetOpen() {
-				return fmt.Errorf("already set opened for OpenElection")
+// OpenElection opens the election to the given number of voters
+func (store *Store) OpenElection(id string, voters uint64) error {
+	return store.backend.Update(func(stv *storage.STV) error {
+		for i, e1 := range stv.GetElections() {
+			if e1.GetId() == id {
+				if e1.GetOpen() {
+					return fmt.Errorf("already set opened for OpenElection")
+				}
+				if e1.GetClosed() {
+					return fmt.Errorf("election closed for OpenElection")
+				}
+				e1 = edit(stv.GetElections(), i)
+				e1.Open = true
+				e1.Voters = voters
+				return nil
 			}
-			if e1.GetClosed() {
-				return fmt.Errorf("election closed for OpenElection")
+		}
+		return nil
+	})
+}
+
+// SetTieBreakSeed commits an election that hasn't opened to a tie-break seed,
+// once set it can't be changed. A seed set after ballots were cast could be
+// chosen to decide a tie they make.
+func (store *Store) SetTieBreakSeed(id, seed string) error {
+	return store.backend.Update(func(stv *storage.STV) error {
+		for i, e1 := range stv.GetElections() {
+			if e1.GetId() == id {
+				if e1.GetOpen() || e1.GetClosed() {
+					return fmt.Errorf("cannot set tie-break seed of open or closed election for SetTieBreakSeed")
+				}
+				if len(e1.GetTieBreakSeed()) > 0 {
+					return fmt.Errorf("already set tie-break seed for SetTieBreakSeed")
+				}
+				e1 = edit(stv.GetElections(), i)
+				e1.TieBreakSeed = seed
+				return nil
 			}
-			e1.Open = true
-			return store.backend.Write(stv)
 		}
-	}
-	return nil
-}
-
-func (store *Store) CloseElection(id string) error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-
-	for _, e1 := range stv.GetElections() {
-		if e1.GetId() == id {
-			if e1.GetClosed() {
-				return fmt.Errorf("already set closed for CloseElection")
+		return fmt.Errorf("election not found for SetTieBreakSeed")
+	})
+}
+
+// CommitRecountSeed commits a closed election whose result recorded no
+// tie-break seed to seed for its recounts and returns the seed committed, a
+// seed already committed is kept so it is only ever drawn once
+func (store *Store) CommitRecountSeed(id, seed string) (string, error) {
+	var committed string
+	err := store.backend.Update(func(stv *storage.STV) error {
+		for i, e1 := range stv.GetElections() {
+			if e1.GetId() == id {
+				if !e1.GetClosed() {
+					return fmt.Errorf("election not closed for CommitRecountSeed")
+				}
+				if len(e1.GetResult().GetTieBreakSeed()) > 0 {
+					return fmt.Errorf("result already has a tie-break seed for CommitRecountSeed")
+				}
+				if len(e1.GetTieBreakSeed()) == 0 {
+					e1 = edit(stv.GetElections(), i)
+					e1.TieBreakSeed = seed
+				}
+				committed = e1.GetTieBreakSeed()
+				return nil
 			}
-			e1.Closed = true
-			e1.Open = false
-			return store.backend.Write(stv)
 		}
+		return fmt.Errorf("election not found for CommitRecountSeed")
+	})
+	if err != nil {
+		return "", err
 	}
-	return nil
+	return committed, nil
 }
 
-func (store *Store) DeleteElection(id string) error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-	s := stv.GetElections()
-	found := false
-	var index int
-	var election *storage.Election
-	for index, election = range s {
-		if election.GetId() == id {
-			if election.GetOpen() {
-				return fmt.Errorf("cannot delete open election for DeleteElection")
-			}
-			found = true
-
-			var ballots []*storage.Ballot
-			ballots, err = store.GetBallotsElectionID(id)
-			if err != nil {
-				return err
-			}
-			for _, b1 := range ballots {
-				err = store.DeleteBallot(b1.GetId())
-				if err != nil {
-					return err
+// CloseElection stores the result of counting an open election and closes it.
+// ballots are the ids of the election's ballots before it was counted, if any
+// have been cast since they would be left out of the result so it isn't closed.
+func (store *Store) CloseElection(id string, result *storage.Result, setAsideInvalid bool, ballots []string) error {
+	return store.backend.Update(func(stv *storage.STV) error {
+		for i, e1 := range stv.GetElections() {
+			if e1.GetId() == id {
+				if e1.GetClosed() {
+					return fmt.Errorf("already set closed for CloseElection")
 				}
-			}
-
-			var candidates []*storage.Candidate
-			candidates, err = store.GetCandidatesElectionID(id)
-			if err != nil {
-				return err
-			}
-			for _, c1 := range candidates {
-				err = store.DeleteCandidate(c1.GetId())
-				if err != nil {
-					return err
+				if !e1.GetOpen() {
+					return fmt.Errorf("election not open for CloseElection")
 				}
+				var cast []string
+				for _, b1 := range stv.GetBallots() {
+					if b1.GetElection() == id {
+						cast = append(cast, b1.GetId())
+					}
+				}
+				slices.Sort(cast)
+				if !slices.Equal(cast, slices.Sorted(slices.Values(ballots))) {
+					return fmt.Errorf("ballots cast while the election was counted, close it again to count them for CloseElection")
+				}
+				e1 = edit(stv.GetElections(), i)
+				e1.Result = clone(result)
+				e1.SetAsideInvalid = setAsideInvalid
+				e1.Closed = true
+				e1.Open = false
+				return nil
 			}
+		}
+		return fmt.Errorf("election not found for CloseElection")
+	})
+}
 
-			var urls []*storage.URL
-			urls, err = store.GetURLsElectionID(id)
-			if err != nil {
-				return err
+// AddCountback records a countback alongside the result of a closed election
+func (store *Store) AddCountback(id string, countback *storage.Countback) error {
+	return store.backend.Update(func(stv *storage.STV) error {
+		for i, e1 := range stv.GetElections() {
+			if e1.GetId() == id {
+				if !e1.GetClosed() {
+					return fmt.Errorf("election not closed for AddCountback")
+				}
+				e1 = edit(stv.GetElections(), i)
+				e1.Countbacks = append(e1.GetCountbacks(), clone(countback))
+				return nil
 			}
+		}
+		return fmt.Errorf("election not found for AddCountback")
+	})
+}
 
-			for _, u1 := range urls {
-				err = store.DeleteURL(u1.GetUrl())
-				if err != nil {
-					return err
+// DeleteElection removes an election that isn't open along with its
+// candidates, ballots and urls
+func (store *Store) DeleteElection(id string) error {
+	return store.backend.Update(func(stv *storage.STV) error {
+		s := stv.GetElections()
+		found := false
+		var index int
+		var election *storage.Election
+		for index, election = range s {
+			if election.GetId() == id {
+				if election.GetOpen() {
+					return fmt.Errorf("cannot delete open election for DeleteElection")
 				}
+				found = true
+				break
 			}
-			break
 		}
-	}
-
-	if found {
-		copy(s[index:], s[index+1:]) // Shift a[i+1:] left one index
-		s[len(s)-1] = nil            // Erase last element (write zero value)
-		stv.Elections = s[:len(s)-1] // Truncate slice
-	}
 
-	return store.backend.Write(stv)
+		if found {
+			stv.Ballots = slices.DeleteFunc(stv.GetBallots(), func(b1 *storage.Ballot) bool {
+				return b1.GetElection() == id
+			})
+			stv.Candidates = slices.DeleteFunc(stv.GetCandidates(), func(c1 *storage.Candidate) bool {
+				return c1.GetElection() == id
+			})
+			stv.Urls = slices.DeleteFunc(stv.GetUrls(), func(u1 *storage.URL) bool {
+				return u1.GetElection() == id
+			})
+
+			copy(s[index:], s[index+1:]) // Shift a[i+1:] left one index
+			s[len(s)-1] = nil            // Erase last element (write zero value)
+			stv.Elections = s[:len(s)-1] // Truncate slice
+		}
+		return nil
+	})
 }
 
 func (store *Store) DeleteAllElections() error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-
-	stv.Elections = []*storage.Election{}
-	stv.Candidates = []*storage.Candidate{}
-	stv.Ballots = []*storage.Ballot{}
-	stv.Urls = []*storage.URL{}
-
-	return store.backend.Write(stv)
+	return store.backend.Update(func(stv *storage.STV) error {
+		stv.Elections = []*storage.Election{}
+		stv.Candidates = []*storage.Candidate{}
+		stv.Ballots = []*storage.Ballot{}
+		stv.Urls = []*storage.URL{}
+		return nil
+	})
 }
 
 func (store *Store) GetURLsElectionID(id string) ([]*storage.URL, error) {
-	stv, err := store.Get()
+	stv, err := store.backend.Read()
 	if err != nil {
 		return nil, err
 	}
 	var urls []*storage.URL
 	for _, url := range stv.GetUrls() {
 		if url.GetElection() == id {
-			urls = append(urls, url)
+			urls = append(urls, clone(url))
 		}
 	}
 	return urls, nil
 }
 
 func (store *Store) FindURL(url string) (*storage.URL, error) {
-	stv, err := store.Get()
+	stv, err := store.backend.Read()
 	if err != nil {
 		return nil, err
 	}
 	for _, u1 := range stv.GetUrls() {
 		if u1.GetUrl() == url {
-			return u1, nil
+			return clone(u1), nil
 		}
 	}
 	return nil, fmt.Errorf("unable to find url for FindURL")
 }
 
 func (store *Store) AddURL(url *storage.URL) (*storage.URL, error) {
-	stv, err := store.Get()
-	if err != nil {
-		return nil, err
-	}
-
-beforeUUID:
-	url.Url = uuid.NewString()
-
-	for _, u := range stv.GetUrls() {
-		if u.GetUrl() == url.GetUrl() {
-			log.Println("duplicate url, retrying...")
-			goto beforeUUID
-		}
-	}
-
-	for _, u := range stv.GetUrls() {
-		if u.GetUrl() == url.GetUrl() {
-			return nil, fmt.Errorf("unable to add url duplicate url for AddURL")
+	err := store.backend.Update(func(stv *storage.STV) error {
+	beforeUUID:
+		url.Url = uuid.NewString()
+
+		for _, u := range stv.GetUrls() {
+			if u.GetUrl() == url.GetUrl() {
+				log.Println("duplicate url, retrying...")
+				goto beforeUUID
+			}
 		}
-	}
-
-	url.Voted = false
 
-	stv.Urls = append(stv.GetUrls(), url)
+		url.Voted = false
 
-	if err = store.backend.Write(stv); err != nil {
+		stv.Urls = append(stv.GetUrls(), clone(url))
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-
 	return url, nil
 }
 
 func (store *Store) SetURLVoted(url string) error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
+	return store.backend.Update(func(stv *storage.STV) error {
+		for i, u1 := range stv.GetUrls() {
+			if u1.GetUrl() == url {
+				if u1.GetVoted() {
+					return fmt.Errorf("already set voted for SetVoted")
+				}
+				u1 = edit(stv.GetUrls(), i)
+				u1.Voted = true
+				return nil
+			}
+		}
+		return nil
+	})
+}
 
-	for _, u1 := range stv.GetUrls() {
-		if u1.GetUrl() == url {
-			if u1.GetVoted() {
-				return fmt.Errorf("already set voted for SetVoted")
+// CastBallot adds a ballot cast with a voting url and marks the url as voted
+// in a single update, the url must not have voted and its election must be open
+func (store *Store) CastBallot(url string, ballot *storage.Ballot) (*storage.Ballot, error) {
+	err := store.backend.Update(func(stv *storage.STV) error {
+		index := slices.IndexFunc(stv.GetUrls(), func(u1 *storage.URL) bool { return u1.GetUrl() == url })
+		if index < 0 {
+			return fmt.Errorf("url not found for CastBallot")
+		}
+		voted := index
+		u1 := stv.GetUrls()[index]
+		if u1.GetVoted() {
+			return fmt.Errorf("already voted for CastBallot")
+		}
+
+		index = slices.IndexFunc(stv.GetElections(), func(e1 *storage.Election) bool { return e1.GetId() == u1.GetElection() })
+		if index < 0 {
+			return fmt.Errorf("unable to find election for CastBallot")
+		}
+		if e1 := stv.GetElections()[index]; !e1.GetOpen() || e1.GetClosed() {
+			return fmt.Errorf("election not open for CastBallot")
+		}
+
+	beforeUUID:
+		ballot.Id = uuid.NewString()
+
+		for _, b := range stv.GetBallots() {
+			if b.GetId() == ballot.GetId() {
+				log.Println("duplicate ballot id, retrying...")
+				goto beforeUUID
 			}
-			u1.Voted = true
-			return store.backend.Write(stv)
 		}
+
+		ballot.Election = u1.GetElection()
+		stv.Ballots = append(stv.GetBallots(), clone(ballot))
+		edit(stv.GetUrls(), voted).Voted = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return ballot, nil
 }
 
 func (store *Store) DeleteURL(url string) error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-	s := stv.GetUrls()
-	found := false
-	var index int
-	var u *storage.URL
-	for index, u = range s {
-		if u.GetUrl() == url {
-			found = true
-			break
+	return store.backend.Update(func(stv *storage.STV) error {
+		s := stv.GetUrls()
+		found := false
+		var index int
+		var u *storage.URL
+		for index, u = range s {
+			if u.GetUrl() == url {
+				found = true
+				break
+			}
 		}
-	}
 
-	if found {
-		copy(s[index:], s[index+1:]) // Shift a[i+1:] left one index
-		s[len(s)-1] = nil            // Erase last element (write zero value)
-		stv.Urls = s[:len(s)-1]      // Truncate slice
+		if found {
+			copy(s[index:], s[index+1:]) // Shift a[i+1:] left one index
+			s[len(s)-1] = nil            // Erase last element (write zero value)
+			stv.Urls = s[:len(s)-1]      // Truncate slice
 
-		return store.backend.Write(stv)
-	}
-	return fmt.Errorf("url not found for DeleteURL")
+			return nil
+		}
+		return fmt.Errorf("url not found for DeleteURL")
+	})
 }
 
 func (store *Store) DeleteAllURLs() error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-
-	stv.Urls = []*storage.URL{}
-
-	return store.backend.Write(stv)
+	return store.backend.Update(func(stv *storage.STV) error {
+		stv.Urls = []*storage.URL{}
+		return nil
+	})
 }
 
 func (store *Store) GetAllowRegistration() (bool, error) {
-	stv, err := store.Get()
+	stv, err := store.backend.Read()
 	if err != nil {
 		return false, err
 	}
@@ -527,100 +804,90 @@ func (store *Store) GetAllowRegistration() (bool, error) {
 }
 
 func (store *Store) SetAllowRegistration(allow bool) (bool, error) {
-	stv, err := store.Get()
+	err := store.backend.Update(func(stv *storage.STV) error {
+		stv.AllowRegistration = allow
+		return nil
+	})
 	if err != nil {
 		return false, err
 	}
-
-	stv.AllowRegistration = allow
-
-	if err = store.backend.Write(stv); err != nil {
-		return false, err
-	}
-
 	return allow, nil
 }
 
 func (store *Store) GetVoters() ([]*storage.Voter, error) {
-	stv, err := store.Get()
+	stv, err := store.backend.Read()
 	if err != nil {
 		return nil, err
 	}
-	return stv.GetVoters(), err
+	return cloneAll(stv.GetVoters()), nil
 }
 
 func (store *Store) FindVoter(email string) (*storage.Voter, error) {
-	stv, err := store.Get()
+	stv, err := store.backend.Read()
 	if err != nil {
 		return nil, err
 	}
 	for _, v1 := range stv.GetVoters() {
 		if v1.GetEmail() == email {
-			return v1, nil
+			return clone(v1), nil
 		}
 	}
 	return nil, fmt.Errorf("unable to find voter for FindVoter")
 }
 
 func (store *Store) AddVoter(voter *storage.Voter) (*storage.Voter, error) {
-	stv, err := store.Get()
-	if err != nil {
-		return &storage.Voter{}, err
-	}
-
-	for _, v := range stv.GetVoters() {
-		if v.GetEmail() == voter.GetEmail() {
-			return &storage.Voter{}, fmt.Errorf("unable to add voter duplicate email for AddVoter")
+	err := store.backend.Update(func(stv *storage.STV) error {
+		for _, v := range stv.GetVoters() {
+			if v.GetEmail() == voter.GetEmail() {
+				return fmt.Errorf("unable to add voter duplicate email for AddVoter")
+			}
 		}
-	}
 
-	stv.Voters = append(stv.GetVoters(), voter)
-
-	if err = store.backend.Write(stv); err != nil {
+		stv.Voters = append(stv.GetVoters(), clone(voter))
+		return nil
+	})
+	if err != nil {
 		return &storage.Voter{}, err
 	}
-
 	return voter, nil
 }
 
 func (store *Store) DeleteVoter(email string) error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-
-	s := stv.GetVoters()
-	found := false
-	var index int
-	var v *storage.Voter
-	for index, v = range s {
-		if v.GetEmail() == email {
-			found = true
-			break
+	return store.backend.Update(func(stv *storage.STV) error {
+		s := stv.GetVoters()
+		found := false
+		var index int
+		var v *storage.Voter
+		for index, v = range s {
+			if v.GetEmail() == email {
+				found = true
+				break
+			}
 		}
-	}
 
-	if found {
-		copy(s[index:], s[index+1:]) // Shift a[i+1:] left one index
-		s[len(s)-1] = nil            // Erase last element (write zero value)
-		stv.Voters = s[:len(s)-1]    // Truncate slice
+		if found {
+			copy(s[index:], s[index+1:]) // Shift a[i+1:] left one index
+			s[len(s)-1] = nil            // Erase last element (write zero value)
+			stv.Voters = s[:len(s)-1]    // Truncate slice
 
-		return store.backend.Write(stv)
-	}
-	return fmt.Errorf("voter not found for DeleteVoter")
+			return nil
+		}
+		return fmt.Errorf("voter not found for DeleteVoter")
+	})
 }
 
 func (store *Store) DeleteAllVoters() error {
-	stv, err := store.backend.Read()
-	if err != nil {
-		return err
-	}
-
-	stv.Voters = []*storage.Voter{}
-
-	return store.backend.Write(stv)
+	return store.backend.Update(func(stv *storage.STV) error {
+		stv.Voters = []*storage.Voter{}
+		return nil
+	})
 }
 
+// Get returns a copy of the whole state
 func (store *Store) Get() (*storage.STV, error) {
-	return store.backend.Read()
+	stv, err := store.backend.Read()
+	if err != nil {
+		return nil, err
+	}
+	return clone(stv), nil
 }
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ystv/stv-web/storage"
+)
+
+func TestCastBallotOncePerURL(t *testing.T) {
+	for _, kind := range []string{BackendFile, BackendSQLite, BackendEventLog} {
+		t.Run(kind, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			store, err := NewStore(false, kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			election, err := store.AddElection(&storage.Election{Name: "Chair", Seats: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			candidate, err := store.AddCandidate(&storage.Candidate{Election: election.GetId(), Name: "A"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			url, err := store.AddURL(&storage.URL{Election: election.GetId(), Voter: "voter@example.com"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = store.CastBallot(url.GetUrl(), &storage.Ballot{Choice: map[uint64]string{0: candidate.GetId()}})
+			if err == nil {
+				t.Fatal("ballot accepted before the election opened")
+			}
+
+			err = store.OpenElection(election.GetId(), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			const submissions = 50
+			var wg sync.WaitGroup
+			accepted := make(chan *storage.Ballot, submissions)
+			for range submissions {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					ballot, err := store.CastBallot(url.GetUrl(), &storage.Ballot{Choice: map[uint64]string{0: candidate.GetId()}})
+					if err == nil {
+						accepted <- ballot
+					}
+				}()
+			}
+			wg.Wait()
+			close(accepted)
+
+			if len(accepted) != 1 {
+				t.Fatalf("%d submissions of one url accepted, want 1", len(accepted))
+			}
+			ballots, err := store.GetBallotsElectionID(election.GetId())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ballots) != 1 || ballots[0].GetId() != (<-accepted).GetId() {
+				t.Fatalf("%d ballots stored, want the 1 accepted", len(ballots))
+			}
+			stored, err := store.FindURL(url.GetUrl())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !stored.GetVoted() {
+				t.Fatal("url not marked voted after its ballot was cast")
+			}
+		})
+	}
+}
+
+func TestUpdateLeavesReadStateAlone(t *testing.T) {
+	for _, kind := range []string{BackendFile, BackendSQLite, BackendEventLog} {
+		t.Run(kind, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			store, err := NewStore(false, kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			election, err := store.AddElection(&storage.Election{Name: "Chair", Seats: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = store.OpenElection(election.GetId(), 1); err != nil {
+				t.Fatal(err)
+			}
+
+			before, err := store.backend.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = store.CloseElection(election.GetId(), &storage.Result{}, false, nil); err != nil {
+				t.Fatal(err)
+			}
+			if e1 := before.GetElections()[0]; !e1.GetOpen() || e1.GetClosed() {
+				t.Fatal("update changed the election in the state it was read from")
+			}
+
+			reopened, err := NewStore(false, kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			closed, err := reopened.FindElection(election.GetId())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if closed.GetOpen() || !closed.GetClosed() {
+				t.Fatal("closing the election not saved")
+			}
+		})
+	}
+}
+
+func TestCloseElectionRefusesBallotsCastWhileCounting(t *testing.T) {
+	for _, kind := range []string{BackendFile, BackendSQLite, BackendEventLog} {
+		t.Run(kind, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			store, err := NewStore(false, kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			election, err := store.AddElection(&storage.Election{Name: "Chair", Seats: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var urls []*storage.URL
+			for _, voter := range []string{"a@example.com", "b@example.com"} {
+				url, err := store.AddURL(&storage.URL{Election: election.GetId(), Voter: voter})
+				if err != nil {
+					t.Fatal(err)
+				}
+				urls = append(urls, url)
+			}
+			if err = store.OpenElection(election.GetId(), 2); err != nil {
+				t.Fatal(err)
+			}
+			first, err := store.CastBallot(urls[0].GetUrl(), &storage.Ballot{Abstain: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the count saw the first ballot, the second is cast before the close
+			counted := []string{first.GetId()}
+			if _, err = store.CastBallot(urls[1].GetUrl(), &storage.Ballot{Abstain: true}); err != nil {
+				t.Fatal(err)
+			}
+			result := &storage.Result{Abstained: 1}
+			if err = store.CloseElection(election.GetId(), result, false, counted); err == nil {
+				t.Fatal("election closed on a count missing a ballot")
+			}
+			open, err := store.FindElection(election.GetId())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !open.GetOpen() || open.GetResult() != nil {
+				t.Fatal("refused close changed the election")
+			}
+
+			result = &storage.Result{Abstained: 2}
+			if err = store.CloseElection(election.GetId(), result, false, ballotIDs(t, store, election.GetId())); err != nil {
+				t.Fatal(err)
+			}
+			closed, err := store.FindElection(election.GetId())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !closed.GetClosed() || closed.GetResult().GetAbstained() != 2 {
+				t.Fatal("result not stored with the close")
+			}
+		})
+	}
+}
+
+func TestSetTieBreakSeedBeforeOpening(t *testing.T) {
+	t.Chdir(t.TempDir())
+	store, err := NewStore(false, BackendFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	election, err := store.AddElection(&storage.Election{Name: "Chair", Seats: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.SetTieBreakSeed(election.GetId(), "dice"); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.SetTieBreakSeed(election.GetId(), "again"); err == nil {
+		t.Fatal("committed tie-break seed changed")
+	}
+
+	opened, err := store.AddElection(&storage.Election{Name: "Secretary", Seats: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.OpenElection(opened.GetId(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.SetTieBreakSeed(opened.GetId(), "dice"); err == nil {
+		t.Fatal("tie-break seed set after the election opened")
+	}
+}
+
+func TestCommitRecountSeedOnce(t *testing.T) {
+	t.Chdir(t.TempDir())
+	store, err := NewStore(false, BackendFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	election, err := store.AddElection(&storage.Election{Name: "Chair", Seats: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.CommitRecountSeed(election.GetId(), "first"); err == nil {
+		t.Fatal("recount seed committed before the election closed")
+	}
+	if err = store.OpenElection(election.GetId(), 1); err != nil {
+		t.Fatal(err)
+	}
+	// a result from before seeds were recorded
+	if err = store.CloseElection(election.GetId(), &storage.Result{}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, seed := range []string{"first", "second"} {
+		committed, err := store.CommitRecountSeed(election.GetId(), seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if committed != "first" {
+			t.Fatalf("recount seed %s committed, want the first drawn", committed)
+		}
+	}
+}
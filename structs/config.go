@@ -15,6 +15,7 @@ type (
 		Address               string `toml:"address"`
 		DomainName            string `toml:"domain_name"`
 		ForceResetURLEndpoint string `toml:"force_reset_url_endpoint"`
+		StoreBackend          string `toml:"store_backend"`
 		Commit                string `toml:"commit,omitempty"`
 		Version               string `toml:"version,omitempty"`
 	}
@@ -6,6 +6,9 @@ import (
 	"html/template"
 	"io"
 	"log"
+	"math/big"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -27,6 +30,7 @@ const (
 	ErrorTemplate             Template = "error.tmpl"
 	HomeTemplate              Template = "home.tmpl"
 	QRTemplate                Template = "qr.tmpl"
+	RecountTemplate           Template = "recount.tmpl"
 	RegisteredTemplate        Template = "registered.tmpl"
 	RegistrationTemplate      Template = "registration.tmpl"
 	RegistrationErrorTemplate Template = "registrationError.tmpl"
@@ -58,6 +62,69 @@ func (t *Templater) RenderTemplate(w io.Writer, data interface{}, mainTmpl Templ
 		"divPercent": func(a, b uint64) string {
 			return fmt.Sprintf("%03.2f%%", (float64(a)/float64(b))*float64(100))
 		},
+		"votes": func(exact string) string {
+			r, ok := new(big.Rat).SetString(exact)
+			if !ok || r.IsInt() || !strings.Contains(exact, "/") {
+				return exact
+			}
+			return fmt.Sprintf("%s (%s)", exact, r.FloatString(5))
+		},
+		"methodName": func(method string) string {
+			switch method {
+			case "Meek":
+				return "Meek STV"
+			case "Schulze":
+				return "Schulze (Condorcet)"
+			case "Approval":
+				return "Approval voting"
+			case "Plurality":
+				return "Plurality (first past the post)"
+			default:
+				return "Single Transferable Vote"
+			}
+		},
+		"thresholdName": func(threshold string) string {
+			switch threshold {
+			case "TwoThirds":
+				return "two-thirds of votes cast, abstentions included"
+			case "TwoThirdsExcludingAbstentions":
+				return "two-thirds of votes cast, excluding abstentions"
+			default:
+				return "simple majority, more for than against"
+			}
+		},
+		"surplusRuleName": func(surplusRule string) string {
+			switch surplusRule {
+			case "LastParcel":
+				return "last parcel"
+			case "WeightedInclusiveGregory":
+				return "weighted inclusive Gregory"
+			default:
+				return "inclusive Gregory"
+			}
+		},
+		"has": slices.Contains[[]string],
+		"unixTime": func(seconds int64) string {
+			return time.Unix(seconds, 0).Format("2 January 2006 15:04")
+		},
+		"compareMethodName": func(compareMethod string) string {
+			if compareMethod == "Random" {
+				return "by lot"
+			}
+			return "by later preferences, then by lot"
+		},
+		"quotaName": func(quota string) string {
+			switch quota {
+			case "Droop":
+				return "Droop, floor(votes/(seats+1))+1"
+			case "HagenbachBischoff":
+				return "Hagenbach-Bischoff, votes/(seats+1)"
+			case "Hare":
+				return "Hare, votes/seats"
+			default:
+				return "Exact Droop, votes/(seats+1)"
+			}
+		},
 	})
 
 	t1, err = t1.ParseFS(tmpls, "_base.tmpl", "_top.tmpl", "_footer.tmpl", string(mainTmpl))
@@ -85,6 +152,7 @@ var (
 		{"error.tmpl", "_base.tmpl", "_top.tmpl", "_footer.tmpl"},
 		{"home.tmpl", "_base.tmpl", "_top.tmpl", "_footer.tmpl"},
 		{"qr.tmpl", "_base.tmpl", "_top.tmpl", "_footer.tmpl"},
+		{"recount.tmpl", "_base.tmpl", "_top.tmpl", "_footer.tmpl"},
 		{"registered.tmpl", "_base.tmpl", "_top.tmpl", "_footer.tmpl"},
 		{"registration.tmpl", "_base.tmpl", "_top.tmpl", "_footer.tmpl"},
 		{"registrationError.tmpl", "_base.tmpl", "_top.tmpl", "_footer.tmpl"},
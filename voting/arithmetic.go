@@ -0,0 +1,91 @@
+package voting
+
+import (
+	"fmt"
+	"math/big"
+)
+
+type Arithmetic string
+
+const (
+	// ArithmeticExact keeps every vote value as an exact fraction
+	ArithmeticExact = "Exact"
+	// ArithmeticFixedDecimal truncates transfer values and transferred votes to a
+	// declared number of decimal places, as in the ERS and Scottish STV rules
+	ArithmeticFixedDecimal = "FixedDecimal"
+)
+
+// DefaultDecimalPlaces is the precision used by the Scottish STV rules
+const DefaultDecimalPlaces = 5
+
+// Precision describes how vote values are held during a count
+type Precision struct {
+	Arithmetic    Arithmetic
+	DecimalPlaces uint
+}
+
+func (p Precision) Validate() error {
+	switch p.Arithmetic {
+	case "", ArithmeticExact, ArithmeticFixedDecimal:
+		return nil
+	default:
+		return fmt.Errorf("unknown arithmetic: %s", p.Arithmetic)
+	}
+}
+
+// IsFixedDecimal reports whether values are truncated to DecimalPlaces
+func (p Precision) IsFixedDecimal() bool {
+	return p.Arithmetic == ArithmeticFixedDecimal
+}
+
+// Truncate rounds r down in place to the declared decimal places, exact
+// arithmetic leaves r untouched
+func (p Precision) Truncate(r *big.Rat) *big.Rat {
+	if !p.IsFixedDecimal() {
+		return r
+	}
+	return truncateRat(r, p.DecimalPlaces)
+}
+
+// FormatVotes renders a vote value losslessly, fixed decimal values as a
+// decimal with the declared places and exact values as a reduced fraction
+func (p Precision) FormatVotes(r *big.Rat) string {
+	if r == nil {
+		return "0"
+	}
+	if p.IsFixedDecimal() {
+		return r.FloatString(int(p.DecimalPlaces))
+	}
+	return r.RatString()
+}
+
+func decimalScale(places uint) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+}
+
+// truncateRat rounds a non-negative r down to the given decimal places in place
+func truncateRat(r *big.Rat, places uint) *big.Rat {
+	scale := decimalScale(places)
+	num := new(big.Int).Mul(r.Num(), scale)
+	num.Quo(num, r.Denom())
+	return r.SetFrac(num, scale)
+}
+
+// roundUpRat rounds a non-negative r up to the given decimal places in place
+func roundUpRat(r *big.Rat, places uint) *big.Rat {
+	scale := decimalScale(places)
+	num := new(big.Int).Mul(r.Num(), scale)
+	quo, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	return r.SetFrac(quo, scale)
+}
+
+func newRat(n int64) *big.Rat {
+	return new(big.Rat).SetInt64(n)
+}
+
+func copyRat(r *big.Rat) *big.Rat {
+	return new(big.Rat).Set(r)
+}
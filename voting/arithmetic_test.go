@@ -0,0 +1,86 @@
+package voting
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrecisionRounding(t *testing.T) {
+	tests := []struct {
+		value     string
+		places    uint
+		truncated string
+		roundedUp string
+	}{
+		{value: "16/3", places: 2, truncated: "5.33", roundedUp: "5.34"},
+		{value: "2/3", places: 5, truncated: "0.66666", roundedUp: "0.66667"},
+		{value: "1/4", places: 2, truncated: "0.25", roundedUp: "0.25"},
+		{value: "7/2", places: 0, truncated: "3", roundedUp: "4"},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			value, ok := new(big.Rat).SetString(test.value)
+			require.True(t, ok)
+			precision := Precision{Arithmetic: ArithmeticFixedDecimal, DecimalPlaces: test.places}
+			assert.Equal(t, test.truncated, precision.FormatVotes(precision.Truncate(copyRat(value))))
+			assert.Equal(t, test.roundedUp, precision.FormatVotes(roundUpRat(copyRat(value), test.places)))
+
+			exact := Precision{Arithmetic: ArithmeticExact}
+			assert.Equal(t, value.RatString(), exact.FormatVotes(exact.Truncate(copyRat(value))))
+		})
+	}
+}
+
+func TestSTVArithmetic(t *testing.T) {
+	// A's surplus of 7 less the quota of 14/3 moves on to B at 1/3 a ballot
+	tests := []struct {
+		name            string
+		precision       Precision
+		quota           string
+		votesB          string
+		lossByFractions string
+	}{
+		{
+			name:            "exact fractions",
+			precision:       Precision{Arithmetic: ArithmeticExact},
+			quota:           "14/3",
+			votesB:          "16/3",
+			lossByFractions: "0",
+		},
+		{
+			// the transfer value of 2.34/7 is truncated to 0.33, losing 0.03
+			name:            "fixed decimal",
+			precision:       Precision{Arithmetic: ArithmeticFixedDecimal, DecimalPlaces: 2},
+			quota:           "4.66",
+			votesB:          "5.31",
+			lossByFractions: "0.03",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidates := testCandidates("A", "B", "C")
+			ballots := testBallots(t, candidates, 7, 0, 1)
+			ballots = append(ballots, testBallots(t, candidates, 3, 1)...)
+			ballots = append(ballots, testBallots(t, candidates, 4, 2)...)
+
+			options := DefaultSingleTransferableVoteOptions()
+			options.Precision = test.precision
+			results, err := SingleTransferableVote(candidates, ballots, 2, options)
+			require.NoError(t, err)
+			AssertVoteWinners(t, results, []string{"A", "B"})
+			require.Len(t, results.Rounds, 2)
+
+			assert.Equal(t, test.quota, test.precision.FormatVotes(results.QuotaValue))
+			second := results.Rounds[1]
+			for _, result := range second.CandidateResults {
+				if result.Candidate == candidates[1] {
+					assert.Equal(t, test.votesB, test.precision.FormatVotes(result.NumberOfVotes))
+				}
+			}
+			assert.Equal(t, test.lossByFractions, test.precision.FormatVotes(second.LossByFractions))
+		})
+	}
+}
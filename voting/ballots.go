@@ -0,0 +1,112 @@
+package voting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InvalidBallot is a ballot that can't be counted and the reason why
+type InvalidBallot struct {
+	Ballot *Ballot
+	Reason string
+}
+
+func (ib *InvalidBallot) String() string {
+	return fmt.Sprintf("%s: %s", ib.Ballot.ID, ib.Reason)
+}
+
+// InvalidBallotsError is returned when ballots can't be counted, it lists
+// every invalid ballot rather than only the first
+type InvalidBallotsError struct {
+	Ballots []*InvalidBallot
+}
+
+func (e *InvalidBallotsError) Error() string {
+	invalid := make([]string, 0, len(e.Ballots))
+	for _, ballot := range e.Ballots {
+		invalid = append(invalid, ballot.String())
+	}
+	return fmt.Sprintf("%d invalid ballots: %s", len(e.Ballots), strings.Join(invalid, "; "))
+}
+
+// ValidateBallots checks every ballot can be counted, returning an
+// *InvalidBallotsError if any can't
+func ValidateBallots(candidates []*Candidate, ballots []*Ballot) error {
+	if _, invalid := SetAsideInvalidBallots(candidates, ballots); len(invalid) > 0 {
+		return &InvalidBallotsError{Ballots: invalid}
+	}
+	return nil
+}
+
+// SetAsideInvalidBallots splits the ballots into those that can be counted and
+// those set aside as spoilt, a ballot is spoilt if it ranks a candidate more
+// than once, ranks someone who isn't a candidate or has a weight that isn't
+// positive
+func SetAsideInvalidBallots(candidates []*Candidate, ballots []*Ballot) ([]*Ballot, []*InvalidBallot) {
+	isCandidate := make(map[*Candidate]bool, len(candidates))
+	for _, candidate := range candidates {
+		isCandidate[candidate] = true
+	}
+
+	valid := make([]*Ballot, 0, len(ballots))
+	invalid := make([]*InvalidBallot, 0)
+	for _, ballot := range ballots {
+		if reason := invalidReason(ballot, isCandidate); len(reason) > 0 {
+			invalid = append(invalid, &InvalidBallot{Ballot: ballot, Reason: reason})
+			continue
+		}
+		valid = append(valid, ballot)
+	}
+	return valid, invalid
+}
+
+// invalidReason returns why the ballot can't be counted, empty if it can
+func invalidReason(ballot *Ballot, isCandidate map[*Candidate]bool) string {
+	ranked := make(map[*Candidate]bool, len(ballot.RankedCandidates))
+	for _, candidate := range ballot.RankedCandidates {
+		if candidate == nil {
+			return "ranks a missing candidate"
+		}
+		if !isCandidate[candidate] {
+			return fmt.Sprintf("ranks %s who is not a candidate", candidate.Name)
+		}
+		if ranked[candidate] {
+			return fmt.Sprintf("ranks %s more than once", candidate.Name)
+		}
+		ranked[candidate] = true
+	}
+	if ballot.Weight != nil && ballot.Weight.Sign() <= 0 {
+		return fmt.Sprintf("has a weight of %s, weights must be positive", ballot.Weight.RatString())
+	}
+	return ""
+}
+
+// SetAsideInvalidMotionBallots splits motion ballots into those that can be
+// counted and those set aside as spoilt, a motion ballot must choose exactly
+// one of the motion options
+func SetAsideInvalidMotionBallots(ballots []*Ballot) ([]*Ballot, []*InvalidBallot) {
+	valid := make([]*Ballot, 0, len(ballots))
+	invalid := make([]*InvalidBallot, 0)
+	for _, ballot := range ballots {
+		if reason := invalidMotionReason(ballot); len(reason) > 0 {
+			invalid = append(invalid, &InvalidBallot{Ballot: ballot, Reason: reason})
+			continue
+		}
+		valid = append(valid, ballot)
+	}
+	return valid, invalid
+}
+
+// invalidMotionReason returns why the motion ballot can't be counted, empty if it can
+func invalidMotionReason(ballot *Ballot) string {
+	if len(ballot.RankedCandidates) != 1 {
+		return fmt.Sprintf("chooses %d options, exactly 1 allowed", len(ballot.RankedCandidates))
+	}
+	switch option := ballot.RankedCandidates[0]; {
+	case option == nil:
+		return "chooses a missing option"
+	case option.Name != MotionFor && option.Name != MotionAgainst && option.Name != MotionAbstain:
+		return fmt.Sprintf("chooses %s which is not a motion option", option.Name)
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package voting
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBallot(t *testing.T) {
+	candidates := testCandidates("A", "B", "C")
+	tests := []struct {
+		name    string
+		ranking []*Candidate
+		err     bool
+	}{
+		{name: "ranked", ranking: []*Candidate{candidates[2], candidates[0]}},
+		{name: "blank", ranking: []*Candidate{}},
+		{name: "duplicate candidate", ranking: []*Candidate{candidates[0], candidates[1], candidates[0]}, err: true},
+		// candidates are the same by name even when they are different values
+		{name: "duplicate name", ranking: []*Candidate{candidates[1], NewCandidate("B")}, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ballot, err := NewBallot(test.ranking)
+			if test.err {
+				assert.Error(t, err)
+				assert.Nil(t, ballot)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.ranking, ballot.RankedCandidates)
+		})
+	}
+}
+
+func TestInvalidBallots(t *testing.T) {
+	candidates := testCandidates("A", "B")
+	valid := testBallots(t, candidates, 2, 0, 1)
+	// ballots built without NewBallot are checked when counted
+	invalid := []*Ballot{
+		{ID: "duplicate", RankedCandidates: []*Candidate{candidates[0], candidates[0]}},
+		{ID: "stranger", RankedCandidates: []*Candidate{NewCandidate("C")}},
+		{ID: "missing", RankedCandidates: []*Candidate{nil}},
+		{ID: "weightless", RankedCandidates: []*Candidate{candidates[1]}, Weight: new(big.Rat)},
+	}
+	ballots := append(append([]*Ballot{}, valid...), invalid...)
+
+	kept, setAside := SetAsideInvalidBallots(candidates, ballots)
+	assert.Equal(t, valid, kept)
+	require.Len(t, setAside, len(invalid))
+	for i, ballot := range setAside {
+		assert.Equal(t, invalid[i], ballot.Ballot)
+		assert.NotEmpty(t, ballot.Reason)
+	}
+
+	// a count returns every invalid ballot instead of panicking on the first
+	for _, method := range []CountMethod{CountMethodSingleTransferableVote, CountMethodSchulze, CountMethodPlurality} {
+		_, err := Count(method, candidates, ballots, 1, DefaultSingleTransferableVoteOptions())
+		var invalidErr *InvalidBallotsError
+		require.True(t, errors.As(err, &invalidErr), "%s counted invalid ballots", method)
+		assert.Len(t, invalidErr.Ballots, len(invalid))
+	}
+}
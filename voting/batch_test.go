@@ -0,0 +1,120 @@
+package voting
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertBatchElimination counts the election with and without batch
+// elimination, the winners must be the same in fewer rounds
+func assertBatchElimination(tb testing.TB, candidates []*Candidate, ballots []*Ballot, numberOfSeats uint64, winners []string) {
+	tb.Helper()
+	options := DefaultSingleTransferableVoteOptions()
+	single, err := SingleTransferableVote(candidates, ballots, numberOfSeats, options)
+	require.NoError(tb, err)
+	options.BatchElimination = true
+	batch, err := SingleTransferableVote(candidates, ballots, numberOfSeats, options)
+	require.NoError(tb, err)
+
+	AssertVoteWinners(tb, single, winners)
+	AssertVoteWinners(tb, batch, winners)
+	assert.Less(tb, len(batch.Rounds), len(single.Rounds), "batch elimination took as many rounds")
+	assert.True(tb, batch.BatchElimination)
+}
+
+func TestBatchEliminationScotland2022(t *testing.T) {
+	blt := parseTestBLT(t, "testdata/Scotland2022_Ward_1_Penicuik.blt")
+
+	assertBatchElimination(t, blt.Candidates, blt.Ballots, blt.NumberOfSeats, []string{
+		"Debbi MCCALL",
+		"Willie MCEWAN",
+		"Connor MCMANUS",
+	})
+}
+
+func TestBatchEliminationSynthetic(t *testing.T) {
+	// E, F and G hold 4 votes between them, fewer than D and too few to take A
+	// to the quota of 26, so they go together in the first round
+	candidates := testCandidates("A", "B", "C", "D", "E", "F", "G")
+	ballots := testBallots(t, candidates, 20, 0)
+	ballots = append(ballots, testBallots(t, candidates, 19, 1)...)
+	ballots = append(ballots, testBallots(t, candidates, 18, 2, 1)...)
+	ballots = append(ballots, testBallots(t, candidates, 17, 3, 2)...)
+	ballots = append(ballots, testBallots(t, candidates, 2, 4, 3)...)
+	ballots = append(ballots, testBallots(t, candidates, 1, 5, 3)...)
+	ballots = append(ballots, testBallots(t, candidates, 1, 6, 3)...)
+
+	assertBatchElimination(t, candidates, ballots, 2, []string{"B", "D"})
+
+	options := DefaultSingleTransferableVoteOptions()
+	options.BatchElimination = true
+	results, err := SingleTransferableVote(candidates, ballots, 2, options)
+	require.NoError(t, err)
+	var batched []string
+	for _, event := range results.Rounds[0].Events {
+		if event.Kind == RoundEventExcludedBatch {
+			batched = append(batched, event.Candidates[0].Name)
+		}
+	}
+	assert.ElementsMatch(t, []string{"E", "F", "G"}, batched)
+}
+
+func TestBatchEliminationTail(t *testing.T) {
+	tests := []struct {
+		name string
+		// tail is the number of ballots for E, F and G and where each goes next
+		tail    []int
+		next    []int
+		batched bool
+	}{
+		{
+			// E goes last, so E's ballots only reach A with the batch
+			name:    "top of the tail goes last",
+			tail:    []int{3, 1, 1},
+			next:    []int{0, 3, 3},
+			batched: true,
+		},
+		{
+			// F and G could take A over the quota before E goes
+			name: "tail takes a candidate to the quota first",
+			tail: []int{3, 1, 1},
+			next: []int{3, 0, 0},
+		},
+		{
+			// E can't be sure to go last, so E's ballots could reach A early
+			name: "top of the tail could go early",
+			tail: []int{2, 1, 1},
+			next: []int{0, 3, 3},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// A is just short of the quota, D is well above the tail
+			candidates := testCandidates("A", "B", "C", "D", "E", "F", "G")
+			ballots := testBallots(t, candidates, 25, 0)
+			ballots = append(ballots, testBallots(t, candidates, 17, 1)...)
+			ballots = append(ballots, testBallots(t, candidates, 16, 2, 1)...)
+			ballots = append(ballots, testBallots(t, candidates, 16, 3, 2)...)
+			for i, count := range test.tail {
+				ballots = append(ballots, testBallots(t, candidates, count, 4+i, test.next[i])...)
+			}
+
+			options := DefaultSingleTransferableVoteOptions()
+			options.BatchElimination = true
+			results, err := SingleTransferableVote(candidates, ballots, 2, options)
+			require.NoError(t, err)
+			batched := slices.ContainsFunc(results.Rounds[0].Events, func(event *RoundEvent) bool {
+				return event.Kind == RoundEventExcludedBatch
+			})
+			assert.Equal(t, test.batched, batched)
+
+			options.BatchElimination = false
+			single, err := SingleTransferableVote(candidates, ballots, 2, options)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, winnerNames(single), winnerNames(results))
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package voting
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// BLT is an election in the BLT ballot file format used by OpenSTV, OpaVote
+// and Droop. Ballots are listed individually, identical ballots are only
+// grouped by weight in the file itself.
+type BLT struct {
+	Title         string
+	NumberOfSeats uint64
+	Candidates    []*Candidate
+	// Withdrawn candidates are listed in the file but take no part in the count
+	Withdrawn []*Candidate
+	Ballots   []*Ballot
+}
+
+// ParseBLT reads a BLT file, candidates are named by the quoted names at the
+// end of the file
+func ParseBLT(r io.Reader) (*BLT, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 && !strings.HasPrefix(line, "\"") {
+			line = strings.TrimSpace(line[:i])
+		}
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blt: %w", err)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("blt is empty")
+	}
+
+	var numberOfCandidates int
+	blt := &BLT{}
+	if _, err := fmt.Sscanf(lines[0], "%d %d", &numberOfCandidates, &blt.NumberOfSeats); err != nil {
+		return nil, fmt.Errorf("failed to parse blt header: %w", err)
+	}
+	if numberOfCandidates < 1 {
+		return nil, fmt.Errorf("blt has no candidates")
+	}
+
+	candidates := make([]*Candidate, numberOfCandidates)
+	for i := range candidates {
+		candidates[i] = &Candidate{}
+	}
+
+	line := 1
+	withdrawn := make([]int, 0)
+	if line < len(lines) && strings.HasPrefix(lines[line], "-") {
+		for _, field := range strings.Fields(lines[line]) {
+			n, err := strconv.Atoi(field)
+			if err != nil || n >= 0 || -n > numberOfCandidates {
+				return nil, fmt.Errorf("invalid withdrawn candidate in blt: %s", field)
+			}
+			withdrawn = append(withdrawn, -n-1)
+		}
+		line++
+	}
+
+	type rawBallot struct {
+		line       int
+		weight     int
+		candidates []int
+	}
+	rawBallots := make([]rawBallot, 0)
+	for ; line < len(lines) && lines[line] != "0"; line++ {
+		fields := strings.Fields(lines[line])
+		if len(fields) < 2 || fields[len(fields)-1] != "0" {
+			return nil, fmt.Errorf("blt ballot line %d must be a weight and preferences ending in 0", line+1)
+		}
+		weight, err := strconv.Atoi(fields[0])
+		if err != nil || weight < 1 {
+			return nil, fmt.Errorf("blt ballot line %d has an invalid weight: %s", line+1, fields[0])
+		}
+		ranked := make([]int, 0, len(fields)-2)
+		for _, field := range fields[1 : len(fields)-1] {
+			if strings.Contains(field, "=") {
+				return nil, fmt.Errorf("blt ballot line %d ranks candidates equally, which isn't supported", line+1)
+			}
+			n, err := strconv.Atoi(field)
+			if err != nil || n < 1 || n > numberOfCandidates {
+				return nil, fmt.Errorf("blt ballot line %d has an invalid candidate: %s", line+1, field)
+			}
+			ranked = append(ranked, n-1)
+		}
+		rawBallots = append(rawBallots, rawBallot{line: line + 1, weight: weight, candidates: ranked})
+	}
+	if line >= len(lines) {
+		return nil, fmt.Errorf("blt ballots must end with a line of 0")
+	}
+	line++
+
+	if len(lines)-line < numberOfCandidates {
+		return nil, fmt.Errorf("blt has %d candidate names, expected %d", len(lines)-line, numberOfCandidates)
+	}
+	for i, candidate := range candidates {
+		name, err := unquotePrefix(lines[line+i])
+		if err != nil {
+			return nil, fmt.Errorf("blt candidate name must be quoted: %s", lines[line+i])
+		}
+		candidate.Name = name
+	}
+	line += numberOfCandidates
+	if line < len(lines) {
+		title, err := unquotePrefix(lines[line])
+		if err != nil {
+			return nil, fmt.Errorf("blt title must be quoted: %s", lines[line])
+		}
+		blt.Title = title
+	}
+
+	isWithdrawn := make(map[int]bool, len(withdrawn))
+	for _, i := range withdrawn {
+		isWithdrawn[i] = true
+		blt.Withdrawn = append(blt.Withdrawn, candidates[i])
+	}
+	for i, candidate := range candidates {
+		if !isWithdrawn[i] {
+			blt.Candidates = append(blt.Candidates, candidate)
+		}
+	}
+
+	for _, raw := range rawBallots {
+		ranked := make([]*Candidate, 0, len(raw.candidates))
+		for _, i := range raw.candidates {
+			if !isWithdrawn[i] {
+				ranked = append(ranked, candidates[i])
+			}
+		}
+		for j := 0; j < raw.weight; j++ {
+			ballot, err := NewBallot(ranked)
+			if err != nil {
+				return nil, fmt.Errorf("blt ballot line %d: %w", raw.line, err)
+			}
+			blt.Ballots = append(blt.Ballots, ballot)
+		}
+	}
+
+	return blt, nil
+}
+
+// unquotePrefix returns the first quoted string on a line, anything after it
+// such as the party some files list after a candidate is ignored
+func unquotePrefix(line string) (string, error) {
+	quoted, err := strconv.QuotedPrefix(line)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Unquote(quoted)
+}
+
+// WriteBLT writes the election as a BLT file, identical ballots are grouped
+// into a single weighted line in the order they first appear. BLT weights are
+// whole numbers so weighted ballots must add up to a whole number on each line.
+func WriteBLT(w io.Writer, blt *BLT) error {
+	all := append(append([]*Candidate{}, blt.Candidates...), blt.Withdrawn...)
+	number := make(map[*Candidate]int, len(all))
+	for i, candidate := range all {
+		number[candidate] = i + 1
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d %d\n", len(all), blt.NumberOfSeats)
+	if len(blt.Withdrawn) > 0 {
+		withdrawn := make([]string, 0, len(blt.Withdrawn))
+		for _, candidate := range blt.Withdrawn {
+			withdrawn = append(withdrawn, strconv.Itoa(-number[candidate]))
+		}
+		fmt.Fprintln(bw, strings.Join(withdrawn, " "))
+	}
+
+	lines := make([]string, 0)
+	weights := make(map[string]*big.Rat)
+	for _, ballot := range blt.Ballots {
+		fields := make([]string, 0, len(ballot.RankedCandidates))
+		for _, candidate := range ballot.RankedCandidates {
+			n, ok := number[candidate]
+			if !ok {
+				return fmt.Errorf("ballot has a candidate not in the blt: %s", candidate.Name)
+			}
+			fields = append(fields, strconv.Itoa(n))
+		}
+		fields = append(fields, "0")
+		line := strings.Join(fields, " ")
+		if _, ok := weights[line]; !ok {
+			lines = append(lines, line)
+			weights[line] = new(big.Rat)
+		}
+		weights[line].Add(weights[line], ballot.value())
+	}
+	for _, line := range lines {
+		if !weights[line].IsInt() {
+			return fmt.Errorf("blt weights must be whole numbers, ballots %s have a weight of %s", line, weights[line].RatString())
+		}
+		fmt.Fprintf(bw, "%s %s\n", weights[line].RatString(), line)
+	}
+	fmt.Fprintln(bw, "0")
+
+	for _, candidate := range all {
+		fmt.Fprintln(bw, strconv.Quote(candidate.Name))
+	}
+	fmt.Fprintln(bw, strconv.Quote(blt.Title))
+
+	return bw.Flush()
+}
@@ -0,0 +1,158 @@
+package voting
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rankingNames are the names each ballot ranks, in ballot order
+func rankingNames(ballots []*Ballot) [][]string {
+	rankings := make([][]string, 0, len(ballots))
+	for _, ballot := range ballots {
+		names := make([]string, 0, len(ballot.RankedCandidates))
+		for _, candidate := range ballot.RankedCandidates {
+			names = append(names, candidate.Name)
+		}
+		rankings = append(rankings, names)
+	}
+	return rankings
+}
+
+func candidateNames(candidates []*Candidate) []string {
+	names := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		names = append(names, candidate.Name)
+	}
+	return names
+}
+
+func TestParseBLT(t *testing.T) {
+	blt, err := ParseBLT(strings.NewReader(`# an election with a withdrawn candidate
+4 2
+-3
+2 1 3 2 0  # Carol is struck off the second preference
+1 2 0
+3 4 1 0
+0
+"Alice" "Party A"
+"Bob"
+"Carol"
+"R.O.N."
+"Chair # and secretary"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "Chair # and secretary", blt.Title)
+	assert.Equal(t, uint64(2), blt.NumberOfSeats)
+	assert.Equal(t, []string{"Alice", "Bob", "R.O.N."}, candidateNames(blt.Candidates))
+	assert.Equal(t, []string{"Carol"}, candidateNames(blt.Withdrawn))
+	// a line of weight 2 is two ballots
+	assert.Equal(t, [][]string{
+		{"Alice", "Bob"},
+		{"Alice", "Bob"},
+		{"Bob"},
+		{"R.O.N.", "Alice"},
+		{"R.O.N.", "Alice"},
+		{"R.O.N.", "Alice"},
+	}, rankingNames(blt.Ballots))
+}
+
+func TestParseBLTRefuses(t *testing.T) {
+	names := "\"A\"\n\"B\"\n"
+	tests := []struct {
+		name string
+		blt  string
+	}{
+		{name: "empty", blt: "# nothing but a comment\n"},
+		{name: "no candidates", blt: "0 1\n0\n"},
+		{name: "withdrawn out of range", blt: "2 1\n-3\n1 1 0\n0\n" + names},
+		{name: "weight not a whole number", blt: "2 1\n1.5 1 0\n0\n" + names},
+		{name: "zero weight", blt: "2 1\n0 1 0\n0\n" + names},
+		{name: "ballot not ending in 0", blt: "2 1\n1 1 2\n0\n" + names},
+		{name: "equal ranking", blt: "2 1\n1 1=2 0\n0\n" + names},
+		{name: "unknown candidate", blt: "2 1\n1 3 0\n0\n" + names},
+		{name: "duplicate candidate", blt: "2 1\n1 1 1 0\n0\n" + names},
+		{name: "no end of ballots", blt: "2 1\n1 1 0\n"},
+		{name: "too few names", blt: "2 1\n1 1 0\n0\n\"A\"\n"},
+		{name: "unquoted name", blt: "2 1\n1 1 0\n0\nA\n\"B\"\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseBLT(strings.NewReader(test.blt))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWriteBLT(t *testing.T) {
+	candidates := testCandidates("A", "B", "C")
+	withdrawn := NewCandidate("D")
+	ballots := testBallots(t, candidates, 2, 0, 1)
+	ballots = append(ballots, testBallots(t, candidates, 1, 2)...)
+	ballots = append(ballots, testBallots(t, candidates, 1, 0, 1)...)
+	// two halves of the same ranking make a whole line
+	halves := testBallots(t, candidates, 2, 1)
+	for _, ballot := range halves {
+		ballot.Weight = big.NewRat(1, 2)
+	}
+	ballots = append(ballots, halves...)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBLT(&buf, &BLT{
+		Title:         "Chair",
+		NumberOfSeats: 1,
+		Candidates:    candidates,
+		Withdrawn:     []*Candidate{withdrawn},
+		Ballots:       ballots,
+	}))
+	assert.Equal(t, `4 1
+-4
+3 1 2 0
+1 3 0
+1 2 0
+0
+"A"
+"B"
+"C"
+"D"
+"Chair"
+`, buf.String())
+}
+
+func TestWriteBLTRefusesFractionalWeights(t *testing.T) {
+	candidates := testCandidates("A", "B")
+	ballots := testBallots(t, candidates, 3, 0)
+	for _, ballot := range ballots {
+		ballot.Weight = big.NewRat(1, 2)
+	}
+
+	var buf bytes.Buffer
+	err := WriteBLT(&buf, &BLT{NumberOfSeats: 1, Candidates: candidates, Ballots: ballots})
+	assert.Error(t, err)
+}
+
+func TestBLTRoundTripScotland2022(t *testing.T) {
+	blt := parseTestBLT(t, "testdata/Scotland2022_Ward_1_Penicuik.blt")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBLT(&buf, blt))
+	again, err := ParseBLT(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, blt.Title, again.Title)
+	assert.Equal(t, blt.NumberOfSeats, again.NumberOfSeats)
+	assert.Equal(t, candidateNames(blt.Candidates), candidateNames(again.Candidates))
+	assert.ElementsMatch(t, rankingNames(blt.Ballots), rankingNames(again.Ballots))
+
+	results, err := SingleTransferableVote(again.Candidates, again.Ballots, again.NumberOfSeats, DefaultSingleTransferableVoteOptions())
+	require.NoError(t, err)
+	AssertVoteWinners(t, results, []string{
+		"Debbi MCCALL",
+		"Willie MCEWAN",
+		"Connor MCMANUS",
+	})
+}
@@ -0,0 +1,293 @@
+package voting
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Category is a group of candidates, such as first-years or a department, with
+// the fewest and most seats it can fill. A candidate can be in any number of
+// categories.
+type Category struct {
+	Name       string
+	Candidates []*Candidate
+	Min        uint64
+	// Max is the most seats the category can fill, 0 is no maximum
+	Max uint64
+}
+
+func (c *Category) String() string {
+	return fmt.Sprintf("Category('%s', min=%d, max=%d)", c.Name, c.Min, c.Max)
+}
+
+func (c *Category) contains(candidate *Candidate) bool {
+	return slices.Contains(c.Candidates, candidate)
+}
+
+// ValidateCategories checks every category can be met by the candidates still
+// standing, categories are only counted by SingleTransferableVote
+func ValidateCategories(categories []*Category, candidates []*Candidate, withdrawn []*Candidate, numberOfSeats uint64) error {
+	names := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		if len(category.Name) == 0 {
+			return fmt.Errorf("category name cannot be empty")
+		}
+		if names[category.Name] {
+			return fmt.Errorf("duplicate category: %s", category.Name)
+		}
+		names[category.Name] = true
+		if category.Max > 0 && category.Min > category.Max {
+			return fmt.Errorf("category %s has a minimum above its maximum", category.Name)
+		}
+		if category.Min > numberOfSeats {
+			return fmt.Errorf("category %s has a minimum of %d but there are only %d seats", category.Name, category.Min, numberOfSeats)
+		}
+		var standing uint64
+		for _, candidate := range category.Candidates {
+			if !slices.Contains(candidates, candidate) {
+				return fmt.Errorf("category %s has a candidate not in the election: %s", category.Name, candidate.Name)
+			}
+			if !slices.Contains(withdrawn, candidate) {
+				standing++
+			}
+		}
+		if standing < category.Min {
+			return fmt.Errorf("category %s has a minimum of %d but only %d candidates standing", category.Name, category.Min, standing)
+		}
+	}
+	return nil
+}
+
+// categoryCount returns how many of the category's candidates are elected and
+// how many are still hopeful
+func (em *ElectionManager) categoryCount(category *Category) (uint64, uint64) {
+	var elected, hopeful uint64
+	for _, candidate := range category.Candidates {
+		switch em.CandidateVoteCounts[candidate].Status {
+		case Elected:
+			elected++
+		case Hopeful:
+			hopeful++
+		}
+	}
+	return elected, hopeful
+}
+
+// categoryGroup is the hopefuls in exactly the same categories, electing any
+// one of them counts the same towards every category
+type categoryGroup struct {
+	in        []bool
+	available uint64
+}
+
+// minimumsReachable reports whether every category minimum can still be met by
+// electing at most seats more of the hopefuls, on top of those elected and
+// elect, leaving out those in without and going over no maximum. A candidate
+// counts towards every category they are in, so categories can overlap.
+func (em *ElectionManager) minimumsReachable(categories []*Category, seats uint64, elect *Candidate, without []*Candidate) bool {
+	counts := make([]uint64, len(categories))
+	seen := make(map[*Candidate]bool)
+	groups := make(map[string]*categoryGroup)
+	// in the order first seen so the search is the same every time
+	ordered := make([]*categoryGroup, 0)
+	for _, category := range categories {
+		for _, candidate := range category.Candidates {
+			if seen[candidate] {
+				continue
+			}
+			seen[candidate] = true
+			in := make([]bool, len(categories))
+			key := make([]byte, len(categories))
+			for i, other := range categories {
+				in[i] = other.contains(candidate)
+				if in[i] {
+					key[i] = 1
+				}
+			}
+			status := em.CandidateVoteCounts[candidate].Status
+			switch {
+			case status == Elected || candidate == elect:
+				for i := range categories {
+					if in[i] {
+						counts[i]++
+					}
+				}
+			case status == Hopeful && !slices.Contains(without, candidate):
+				group, ok := groups[string(key)]
+				if !ok {
+					group = &categoryGroup{in: in}
+					groups[string(key)] = group
+					ordered = append(ordered, group)
+				}
+				group.available++
+			}
+		}
+	}
+	return reachMinimums(categories, counts, ordered, 0, seats)
+}
+
+// reachMinimums searches for how many of each group, from start on, to elect
+// to meet the minimums. Only a group in a category still short is worth
+// electing for the minimums, and taking the groups in order tries each mix of
+// them once.
+func reachMinimums(categories []*Category, counts []uint64, groups []*categoryGroup, start int, seats uint64) bool {
+	var shortfall uint64
+	for i, category := range categories {
+		if counts[i] < category.Min {
+			shortfall = max(shortfall, category.Min-counts[i])
+		}
+	}
+	if shortfall == 0 {
+		return true
+	}
+	if shortfall > seats {
+		return false
+	}
+
+groups:
+	for g := start; g < len(groups); g++ {
+		group := groups[g]
+		if group.available == 0 {
+			continue
+		}
+		helps := false
+		for i, category := range categories {
+			if !group.in[i] {
+				continue
+			}
+			if category.Max > 0 && counts[i] >= category.Max {
+				continue groups
+			}
+			if counts[i] < category.Min {
+				helps = true
+			}
+		}
+		if !helps {
+			continue
+		}
+
+		group.available--
+		for i := range categories {
+			if group.in[i] {
+				counts[i]++
+			}
+		}
+		reached := reachMinimums(categories, counts, groups, g, seats-1)
+		group.available++
+		for i := range categories {
+			if group.in[i] {
+				counts[i]--
+			}
+		}
+		if reached {
+			return true
+		}
+	}
+	return false
+}
+
+// shortCategory returns the first category below its minimum that the
+// candidate is in, or isn't in when in is false
+func (em *ElectionManager) shortCategory(candidate *Candidate, categories []*Category, in bool) *Category {
+	for _, category := range categories {
+		if elected, _ := em.categoryCount(category); elected < category.Min && category.contains(candidate) == in {
+			return category
+		}
+	}
+	return nil
+}
+
+// canElect reports whether the candidate can fill one of the seats left
+// without going over a category maximum or leaving the category minimums out
+// of reach of the seats after it, if not it returns the event and the category
+// stopping them. There must be at least one seat left.
+func (em *ElectionManager) canElect(candidate *Candidate, categories []*Category, seatsLeft uint64) (RoundEventKind, *Category, bool) {
+	if len(categories) == 0 {
+		return "", nil, true
+	}
+	for _, category := range categories {
+		if !category.contains(candidate) {
+			continue
+		}
+		if elected, _ := em.categoryCount(category); category.Max > 0 && elected >= category.Max {
+			return RoundEventExcludedCategoryFull, category, false
+		}
+	}
+	if !em.minimumsReachable(categories, seatsLeft-1, candidate, nil) {
+		short := em.shortCategory(candidate, categories, false)
+		if short == nil {
+			short = em.shortCategory(candidate, categories, true)
+		}
+		return RoundEventExcludedCategoryMinimum, short, false
+	}
+	return "", nil, true
+}
+
+// guardingCategory returns the category the candidate is needed for, when
+// excluding them as well as those in without would leave the minimums out of
+// reach of the seats left
+func (em *ElectionManager) guardingCategory(candidate *Candidate, categories []*Category, seatsLeft uint64, without []*Candidate) *Category {
+	// minimums already out of reach are left to checkCategories
+	if !em.minimumsReachable(categories, seatsLeft, nil, without) ||
+		em.minimumsReachable(categories, seatsLeft, nil, append(slices.Clone(without), candidate)) {
+		return nil
+	}
+	return em.shortCategory(candidate, categories, true)
+}
+
+// guardExclusions takes the guarded candidates out of those about to be
+// excluded, if none are left the hopeful with the fewest votes who isn't
+// guarded is excluded in their place
+func (em *ElectionManager) guardExclusions(candidatesToReject []*Candidate, categories []*Category, seatsLeft uint64) ([]*Candidate, error) {
+	unguarded := make([]*Candidate, 0, len(candidatesToReject))
+	for _, candidate := range candidatesToReject {
+		if category := em.guardingCategory(candidate, categories, seatsLeft, unguarded); category != nil {
+			em.recordCategoryEvent(RoundEventGuarded, candidate, category)
+			continue
+		}
+		unguarded = append(unguarded, candidate)
+	}
+	if len(unguarded) > 0 || len(candidatesToReject) == 0 {
+		return unguarded, nil
+	}
+
+	candidatesInRace := em.GetCandidatesInRace()
+	for i := len(candidatesInRace) - 1; i >= 0; i-- {
+		if em.guardingCategory(candidatesInRace[i], categories, seatsLeft, nil) == nil {
+			return []*Candidate{candidatesInRace[i]}, nil
+		}
+	}
+	return nil, fmt.Errorf("categories cannot be met, every hopeful is needed for a minimum")
+}
+
+// categoryExclusions returns the hopefuls that can no longer be elected
+// because of the categories, with the event and category stopping each
+func (em *ElectionManager) categoryExclusions(categories []*Category, seatsLeft uint64) ([]*Candidate, []RoundEventKind, []*Category) {
+	excluded := make([]*Candidate, 0)
+	kinds := make([]RoundEventKind, 0)
+	stoppedBy := make([]*Category, 0)
+	if seatsLeft == 0 {
+		return excluded, kinds, stoppedBy
+	}
+	for _, candidate := range em.GetCandidatesInRace() {
+		if kind, category, ok := em.canElect(candidate, categories, seatsLeft); !ok {
+			excluded = append(excluded, candidate)
+			kinds = append(kinds, kind)
+			stoppedBy = append(stoppedBy, category)
+		}
+	}
+	return excluded, kinds, stoppedBy
+}
+
+// checkCategories returns an error if the category minimums can no longer be reached
+func (em *ElectionManager) checkCategories(categories []*Category, seatsLeft uint64) error {
+	for _, category := range categories {
+		if elected, hopeful := em.categoryCount(category); elected+hopeful < category.Min {
+			return fmt.Errorf("category %s can no longer meet its minimum of %d", category.Name, category.Min)
+		}
+	}
+	if !em.minimumsReachable(categories, seatsLeft, nil, nil) {
+		return fmt.Errorf("categories can no longer all meet their minimums with %d seats left", seatsLeft)
+	}
+	return nil
+}
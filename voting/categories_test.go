@@ -0,0 +1,105 @@
+package voting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCategory is a category of the candidates at the indexes in members
+type testCategory struct {
+	name     string
+	members  []int
+	min, max uint64
+}
+
+func TestSTVCategories(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		categories []testCategory
+		seats      uint64
+		ballots    [][]int
+		counts     []int
+		winners    []string
+	}{
+		{
+			// X is the only candidate in both, so electing Y as well leaves one
+			// seat which X alone can fill for both minimums
+			name:       "overlapping minimums",
+			candidates: []string{"X", "Y", "F", "D"},
+			categories: []testCategory{
+				{name: "first-year", members: []int{0, 2}, min: 1},
+				{name: "department A", members: []int{0, 3}, min: 1},
+			},
+			seats:   2,
+			ballots: [][]int{{1}, {2}, {3}, {0}},
+			counts:  []int{10, 6, 5, 4},
+			winners: []string{"X", "Y"},
+		},
+		{
+			// B fills both maximums, so neither A nor C can join them
+			name:       "overlapping maximums",
+			candidates: []string{"A", "B", "C", "D"},
+			categories: []testCategory{
+				{name: "sabbatical", members: []int{0, 1}, max: 1},
+				{name: "executive", members: []int{1, 2}, max: 1},
+			},
+			seats:   2,
+			ballots: [][]int{{1}, {0}, {2}, {3}},
+			counts:  []int{10, 8, 7, 2},
+			winners: []string{"B", "D"},
+		},
+		{
+			// without categories A and B win, the minimum has to be met by C
+			name:       "minimum elects from below",
+			candidates: []string{"A", "B", "C"},
+			categories: []testCategory{
+				{name: "first-year", members: []int{2}, min: 1},
+			},
+			seats:   2,
+			ballots: [][]int{{0}, {1}, {2}},
+			counts:  []int{10, 8, 2},
+			winners: []string{"A", "C"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidates := testCandidates(test.candidates...)
+			options := DefaultSingleTransferableVoteOptions()
+			for _, c := range test.categories {
+				category := &Category{Name: c.name, Min: c.min, Max: c.max}
+				for _, i := range c.members {
+					category.Candidates = append(category.Candidates, candidates[i])
+				}
+				options.Categories = append(options.Categories, category)
+			}
+			var ballots []*Ballot
+			for i, ranking := range test.ballots {
+				ballots = append(ballots, testBallots(t, candidates, test.counts[i], ranking...)...)
+			}
+
+			results, err := SingleTransferableVote(candidates, ballots, test.seats, options)
+			require.NoError(t, err)
+			AssertVoteWinners(t, results, test.winners)
+		})
+	}
+}
+
+func TestSTVCategoriesOverlappingUnreachable(t *testing.T) {
+	// each minimum can be met on its own, but X and Z are both needed and the
+	// maximum they share allows only one of them
+	candidates := testCandidates("X", "Y", "Z")
+	options := DefaultSingleTransferableVoteOptions()
+	options.Categories = []*Category{
+		{Name: "first-year", Candidates: []*Candidate{candidates[0]}, Min: 1},
+		{Name: "department A", Candidates: []*Candidate{candidates[2]}, Min: 1},
+		{Name: "sabbatical", Candidates: []*Candidate{candidates[0], candidates[2]}, Max: 1},
+	}
+	ballots := testBallots(t, candidates, 3, 1, 0, 2)
+
+	_, err := SingleTransferableVote(candidates, ballots, 2, options)
+	assert.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package voting
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// CountbackResult is the count that filled a casual vacancy
+type CountbackResult struct {
+	// Results are the rounds of the countback, with the single candidate elected
+	Results *ElectionResults
+	// Ballots is the number of ballots the vacating candidate held when elected
+	// and Votes the value they held, each is counted again at that value
+	Ballots int
+	Votes   *big.Rat
+}
+
+// Countback fills the seat of a vacating candidate by counting again the
+// ballots they held when elected, at the value they held, among the standing
+// candidates. The original count is replayed with the same options and seed to
+// find those ballots, so it must have been a SingleTransferableVote count. A
+// standing candidate is elected once they hold a majority of the continuing
+// votes, otherwise the lowest is excluded and their ballots move on.
+func Countback(candidates []*Candidate, ballots []*Ballot, numberOfSeats uint64, options SingleTransferableVoteOptions, vacating *Candidate, standing []*Candidate) (*CountbackResult, error) {
+	if len(standing) == 0 {
+		return nil, fmt.Errorf("countback needs at least one standing candidate")
+	}
+
+	_, original, err := singleTransferableVote(candidates, ballots, numberOfSeats, options)
+	if err != nil {
+		return nil, err
+	}
+	vacatingCV, ok := original.CandidateVoteCounts[vacating]
+	if !ok || vacatingCV.Status != Elected {
+		return nil, fmt.Errorf("vacating candidate was not elected: %s", vacating.Name)
+	}
+	for _, candidate := range standing {
+		cvc, ok := original.CandidateVoteCounts[candidate]
+		if !ok {
+			return nil, fmt.Errorf("standing candidate is not in the election: %s", candidate.Name)
+		}
+		if cvc.Status == Elected {
+			return nil, fmt.Errorf("standing candidate was elected: %s", candidate.Name)
+		}
+		if cvc.Status == Withdrawn {
+			return nil, fmt.Errorf("standing candidate has withdrawn: %s", candidate.Name)
+		}
+	}
+
+	// a candidate elected without their surplus being transferred kept every ballot
+	heldBallots, heldWeights := vacatingCV.QuotaBallots, vacatingCV.QuotaWeights
+	if heldBallots == nil {
+		heldBallots, heldWeights = vacatingCV.Votes, vacatingCV.Weights
+	}
+
+	countbackBallots := make([]*Ballot, 0, len(heldBallots))
+	for i, ballot := range heldBallots {
+		ranked := make([]*Candidate, 0, len(ballot.RankedCandidates))
+		for _, candidate := range ballot.RankedCandidates {
+			if slices.Contains(standing, candidate) {
+				ranked = append(ranked, candidate)
+			}
+		}
+		countbackBallots = append(countbackBallots, &Ballot{RankedCandidates: ranked, Weight: copyRat(heldWeights[i])})
+	}
+
+	// the ballots carry different values, so exclusions move each one at the
+	// value it holds rather than sharing the votes equally
+	manager, err := NewElectionManager(standing, countbackBallots, ElectionManagerOptions{
+		NumberOfVotesPerVoter: 1,
+		CompareMethodIfEqual:  options.CompareMethodIfEquals,
+		Precision:             options.Precision,
+		SurplusRule:           SurplusRuleWeightedInclusiveGregory,
+		TieBreakSeed:          original.TieBreakSeed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	electionResults := NewElectionResults(options.Precision, manager)
+
+	for {
+		candidatesInRace := manager.GetCandidatesInRace()
+		continuing := new(big.Rat)
+		for _, candidate := range candidatesInRace {
+			votes, err := manager.GetNumberOfVotes(candidate)
+			if err != nil {
+				return nil, err
+			}
+			continuing.Add(continuing, votes)
+		}
+
+		leader := candidatesInRace[0]
+		leaderVotes, err := manager.GetNumberOfVotes(leader)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidatesInRace) == 1 || new(big.Rat).Mul(leaderVotes, newRat(2)).Cmp(continuing) > 0 {
+			event := RoundEventKind(RoundEventElectedMajority)
+			if len(candidatesInRace) == 1 {
+				event = RoundEventElectedRemainingSeats
+			}
+			manager.recordEvent(event, leader)
+			if err = manager.ElectCandidate(leader); err != nil {
+				return nil, err
+			}
+			remaining := manager.GetCandidatesInRace()
+			for i := len(remaining) - 1; i >= 0; i-- {
+				manager.recordEvent(RoundEventExcludedSeatsFilled, remaining[i])
+				if err = manager.RejectCandidate(remaining[i]); err != nil {
+					return nil, err
+				}
+			}
+			electionResults.RegisterResults(manager.GetResults())
+			break
+		}
+
+		lowest, err := manager.GetCandidateWithLeastVotesInRace()
+		if err != nil {
+			return nil, err
+		}
+		manager.recordExclusionTie([]*Candidate{lowest})
+		manager.recordEvent(RoundEventExcludedLowest, lowest)
+		if err = manager.RejectCandidate(lowest); err != nil {
+			return nil, err
+		}
+		electionResults.RegisterResults(manager.GetResults())
+
+		votes, err := manager.GetNumberOfVotes(lowest)
+		if err != nil {
+			return nil, err
+		}
+		if err = manager.TransferVotes(lowest, votes); err != nil {
+			return nil, err
+		}
+	}
+
+	return &CountbackResult{
+		Results: electionResults,
+		Ballots: len(heldBallots),
+		Votes:   sumSlice(heldWeights),
+	}, nil
+}
@@ -0,0 +1,88 @@
+package voting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countbackElection elects A on the quota of 6 in the first round, A's surplus
+// of 2 moves on at 1/4 a ballot and C is elected once D is excluded
+func countbackElection(tb testing.TB) ([]*Candidate, []*Ballot) {
+	tb.Helper()
+	candidates := testCandidates("A", "B", "C", "D")
+	ballots := testBallots(tb, candidates, 6, 0, 2, 1)
+	ballots = append(ballots, testBallots(tb, candidates, 2, 0, 3)...)
+	ballots = append(ballots, testBallots(tb, candidates, 5, 1)...)
+	ballots = append(ballots, testBallots(tb, candidates, 3, 2)...)
+	ballots = append(ballots, testBallots(tb, candidates, 2, 3, 2)...)
+	return candidates, ballots
+}
+
+func TestCountback(t *testing.T) {
+	candidates, ballots := countbackElection(t)
+	options := DefaultSingleTransferableVoteOptions()
+	results, err := SingleTransferableVote(candidates, ballots, 2, options)
+	require.NoError(t, err)
+	AssertVoteWinners(t, results, []string{"A", "C"})
+
+	tests := []struct {
+		name     string
+		standing []*Candidate
+		winner   string
+		votes    string
+	}{
+		{
+			// A kept the 8 ballots at 3/4, the 6 going on to B hold a majority of
+			// the 6 continuing votes
+			name:     "majority",
+			standing: []*Candidate{candidates[1], candidates[3]},
+			winner:   "B",
+			votes:    "9/2",
+		},
+		{
+			name:     "only one standing",
+			standing: []*Candidate{candidates[3]},
+			winner:   "D",
+			votes:    "3/2",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			countback, err := Countback(candidates, ballots, 2, options, candidates[0], test.standing)
+			require.NoError(t, err)
+			assert.Equal(t, 8, countback.Ballots)
+			assert.Equal(t, "6", countback.Votes.RatString())
+			AssertVoteWinners(t, countback.Results, []string{test.winner})
+
+			first := countback.Results.Rounds[0]
+			for _, result := range first.CandidateResults {
+				if result.Candidate.Name == test.winner {
+					assert.Equal(t, test.votes, result.NumberOfVotes.RatString())
+				}
+			}
+		})
+	}
+}
+
+func TestCountbackRefuses(t *testing.T) {
+	candidates, ballots := countbackElection(t)
+	options := DefaultSingleTransferableVoteOptions()
+	tests := []struct {
+		name     string
+		vacating *Candidate
+		standing []*Candidate
+	}{
+		{name: "no one standing", vacating: candidates[0]},
+		{name: "vacating candidate not elected", vacating: candidates[1], standing: []*Candidate{candidates[3]}},
+		{name: "standing candidate elected", vacating: candidates[0], standing: []*Candidate{candidates[2]}},
+		{name: "standing candidate not in the election", vacating: candidates[0], standing: []*Candidate{NewCandidate("E")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Countback(candidates, ballots, 2, options, test.vacating, test.standing)
+			assert.Error(t, err)
+		})
+	}
+}
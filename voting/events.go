@@ -0,0 +1,126 @@
+package voting
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+type RoundEventKind string
+
+const (
+	// RoundEventElectedQuota is a candidate elected by reaching the quota
+	RoundEventElectedQuota = "ElectedQuota"
+	// RoundEventElectedRemainingSeats is a candidate elected because the
+	// hopefuls left equalled the seats left
+	RoundEventElectedRemainingSeats = "ElectedRemainingSeats"
+	// RoundEventElectedSchulze is a candidate elected as the Schulze winner
+	RoundEventElectedSchulze = "ElectedSchulze"
+	// RoundEventElectedMostVotes is a candidate elected with the most votes on
+	// approval or plurality ballots
+	RoundEventElectedMostVotes = "ElectedMostVotes"
+	// RoundEventElectedMajority is a candidate elected in a countback with more
+	// than half of the continuing votes
+	RoundEventElectedMajority = "ElectedMajority"
+	// RoundEventExcludedLowest is a candidate excluded with the fewest votes
+	RoundEventExcludedLowest = "ExcludedLowest"
+	// RoundEventExcludedBatch is a candidate excluded with others whose combined
+	// votes couldn't catch the candidate above them or take anyone to the quota
+	RoundEventExcludedBatch = "ExcludedBatch"
+	// RoundEventWithdrawn is a candidate who withdrew, excluded before the first round
+	RoundEventWithdrawn = "Withdrawn"
+	// RoundEventExcludedCategoryFull is a candidate excluded as a category they
+	// are in has already filled its maximum
+	RoundEventExcludedCategoryFull = "ExcludedCategoryFull"
+	// RoundEventExcludedCategoryMinimum is a candidate excluded as the seats left
+	// are needed to meet category minimums they don't count towards
+	RoundEventExcludedCategoryMinimum = "ExcludedCategoryMinimum"
+	// RoundEventGuarded is a candidate kept in the count with the fewest votes as
+	// a category needs them to meet its minimum
+	RoundEventGuarded = "Guarded"
+	// RoundEventExcludedSeatsFilled is a candidate excluded as every seat is filled
+	RoundEventExcludedSeatsFilled = "ExcludedSeatsFilled"
+	// RoundEventTieResolvedBySecondPreferences is a tie deciding an exclusion
+	// that was resolved by later preferences
+	RoundEventTieResolvedBySecondPreferences = "TieResolvedBySecondPreferences"
+	// RoundEventTieResolvedByLot is a tie deciding an exclusion that was
+	// resolved by the lot order
+	RoundEventTieResolvedByLot = "TieResolvedByLot"
+)
+
+// RoundEvent explains why something happened in a round
+type RoundEvent struct {
+	Kind RoundEventKind
+	// Candidates is the candidate elected or excluded, or the tied candidates
+	// in the order the tie was decided
+	Candidates []*Candidate
+	// Votes held by the candidate when elected or excluded, or by each tied candidate
+	Votes *big.Rat
+	// Category whose minimum or maximum caused the event, empty for most events
+	Category string
+}
+
+func (re *RoundEvent) String() string {
+	names := make([]string, 0, len(re.Candidates))
+	for _, c := range re.Candidates {
+		names = append(names, c.Name)
+	}
+	return fmt.Sprintf("RoundEvent(%s: %s, votes=%s)", re.Kind, strings.Join(names, " > "), re.Votes.FloatString(2))
+}
+
+// recordEvent adds an event for the candidate to the current round, it must be
+// recorded before the candidate's votes are transferred
+func (em *ElectionManager) recordEvent(kind RoundEventKind, candidate *Candidate) {
+	em.roundEvents = append(em.roundEvents, &RoundEvent{
+		Kind:       kind,
+		Candidates: []*Candidate{candidate},
+		Votes:      copyRat(em.CandidateVoteCounts[candidate].NumberOfVotes),
+	})
+}
+
+// recordCategoryEvent adds an event for the candidate caused by the category
+func (em *ElectionManager) recordCategoryEvent(kind RoundEventKind, candidate *Candidate, category *Category) {
+	em.recordEvent(kind, candidate)
+	em.roundEvents[len(em.roundEvents)-1].Category = category.Name
+}
+
+// recordExclusionTie records how a tie was decided if one of the candidates
+// about to be excluded is on equal votes with a hopeful that stays in the race
+func (em *ElectionManager) recordExclusionTie(excluded []*Candidate) {
+	highest := new(big.Rat)
+	for _, candidate := range excluded {
+		if votes := em.CandidateVoteCounts[candidate].NumberOfVotes; votes.Cmp(highest) > 0 {
+			highest = votes
+		}
+	}
+
+	tied := false
+	for _, cvc := range em.CandidatesInRace {
+		if !slices.Contains(excluded, cvc.Candidate) && cvc.NumberOfVotes.Cmp(highest) == 0 {
+			tied = true
+		}
+	}
+	if !tied {
+		return
+	}
+
+	for i := len(em.roundTieBreaks) - 1; i >= 0; i-- {
+		tieBreak := em.roundTieBreaks[i]
+		if tieBreak.Reason != TieBreakReasonEqualVotes || !slices.ContainsFunc(excluded, func(c *Candidate) bool {
+			return slices.Contains(tieBreak.Candidates, c)
+		}) {
+			continue
+		}
+		kind := RoundEventKind(RoundEventTieResolvedByLot)
+		if tieBreak.Method == CompareMethodMostSecondChoice {
+			kind = RoundEventTieResolvedBySecondPreferences
+		}
+		em.roundEvents = append(em.roundEvents, &RoundEvent{
+			Kind:       kind,
+			Candidates: tieBreak.Candidates,
+			Votes:      copyRat(highest),
+		})
+		return
+	}
+}
@@ -1,51 +1,62 @@
 package voting
 
 import (
-	"crypto/rand"
 	"fmt"
-	"math"
 	"math/big"
+	"math/rand/v2"
+	"slices"
 	"sort"
 
 	"github.com/bndr/gotabulate"
 )
 
-const float64EqualityThreshold = 1e-9
-
-func almostEqual(a, b float64) bool {
-	return math.Abs(a-b) <= float64EqualityThreshold
-}
-
 type CandidateStatus string
 
 const (
 	Elected  = "Elected"
 	Hopeful  = "Hopeful"
 	Rejected = "Rejected"
+	// Withdrawn candidates stood down after voting opened, they are left out of
+	// the count and skipped on every ballot
+	Withdrawn = "Withdrawn"
 )
 
 type CandidateResult struct {
 	Candidate     *Candidate
-	NumberOfVotes float64
-	Status        CandidateStatus
+	NumberOfVotes *big.Rat
+	// NumberOfBallots is the number of ballots making up the votes, they differ
+	// once ballots are transferred at less than their value or are weighted
+	NumberOfBallots int
+	Status          CandidateStatus
 }
 
 type RoundResult struct {
 	CandidateResults   []*CandidateResult
-	NumberOfBlankVotes float64
+	NumberOfBlankVotes *big.Rat
+	// LossByFractions is the value dropped by truncation in fixed decimal counts
+	LossByFractions *big.Rat
+	TieBreaks       []*TieBreak
+	Events          []*RoundEvent
+	// Transfers are the transfers made since the previous round that produced
+	// these totals
+	Transfers []*Transfer
 }
 
-func NewRoundResult(candidateResults []*CandidateResult, nbBlankVotes float64) *RoundResult {
+func NewRoundResult(candidateResults []*CandidateResult, nbBlankVotes, lossByFractions *big.Rat, tieBreaks []*TieBreak, events []*RoundEvent, transfers []*Transfer) *RoundResult {
 	return &RoundResult{
 		CandidateResults:   candidateResults,
-		NumberOfBlankVotes: nbBlankVotes,
+		NumberOfBlankVotes: copyRat(nbBlankVotes),
+		LossByFractions:    copyRat(lossByFractions),
+		TieBreaks:          tieBreaks,
+		Events:             events,
+		Transfers:          transfers,
 	}
 }
 
 func (rr *RoundResult) String() string {
 	// Checking if we need to include Blank votes as a result
 	resultsWithBlankVotes := rr.CandidateResults
-	if !almostEqual(rr.NumberOfBlankVotes, 0.0) {
+	if rr.NumberOfBlankVotes.Sign() != 0 {
 		resultsWithBlankVotes = append(resultsWithBlankVotes,
 			&CandidateResult{
 				Candidate:     NewCandidate("BlankVotes"),
@@ -57,7 +68,7 @@ func (rr *RoundResult) String() string {
 	// Prepares the rows
 	rows := make([][]interface{}, 0)
 	for _, result := range resultsWithBlankVotes {
-		row := []interface{}{result.Candidate, result.NumberOfVotes, result.Status}
+		row := []interface{}{result.Candidate, result.NumberOfVotes.FloatString(DefaultDecimalPlaces), result.Status}
 		rows = append(rows, row)
 	}
 
@@ -71,16 +82,60 @@ func (rr *RoundResult) String() string {
 type CandidateVoteCount struct {
 	Candidate     *Candidate
 	Status        CandidateStatus
-	NumberOfVotes float64
+	NumberOfVotes *big.Rat
 	Votes         []*Ballot
+	// Weights is the value each ballot in Votes holds for the candidate
+	Weights []*big.Rat
+	// Parcels is the index in Votes each parcel of ballots starts at, the first
+	// preferences and then one parcel for every stage that transferred to them
+	Parcels []int
+	parcel  int
+	// QuotaBallots are the ballots an elected candidate kept after their surplus
+	// was transferred and QuotaWeights the value each kept, a countback counts
+	// these again
+	QuotaBallots []*Ballot
+	QuotaWeights []*big.Rat
 }
 
 func NewCandidateVoteCount(candidate *Candidate) *CandidateVoteCount {
 	return &CandidateVoteCount{
 		Candidate:     candidate,
 		Status:        Hopeful,
-		NumberOfVotes: 0.0,
+		NumberOfVotes: new(big.Rat),
 		Votes:         make([]*Ballot, 0),
+		Weights:       make([]*big.Rat, 0),
+		Parcels:       make([]int, 0),
+	}
+}
+
+func (cvc *CandidateVoteCount) addVote(ballot *Ballot, value *big.Rat, parcel int) {
+	if len(cvc.Parcels) == 0 || cvc.parcel != parcel {
+		cvc.Parcels = append(cvc.Parcels, len(cvc.Votes))
+		cvc.parcel = parcel
+	}
+	cvc.NumberOfVotes.Add(cvc.NumberOfVotes, value)
+	cvc.Votes = append(cvc.Votes, ballot)
+	cvc.Weights = append(cvc.Weights, copyRat(value))
+}
+
+// keepQuota records what each ballot is still worth to an elected candidate
+// once the given values have moved on as their surplus
+func (cvc *CandidateVoteCount) keepQuota(moved []*Ballot, values []*big.Rat) {
+	movedValues := make(map[*Ballot]*big.Rat, len(moved))
+	for i, ballot := range moved {
+		movedValues[ballot] = values[i]
+	}
+	cvc.QuotaBallots = make([]*Ballot, 0, len(cvc.Votes))
+	cvc.QuotaWeights = make([]*big.Rat, 0, len(cvc.Votes))
+	for i, ballot := range cvc.Votes {
+		kept := copyRat(cvc.Weights[i])
+		if value, ok := movedValues[ballot]; ok {
+			kept.Sub(kept, value)
+		}
+		if kept.Sign() > 0 {
+			cvc.QuotaBallots = append(cvc.QuotaBallots, ballot)
+			cvc.QuotaWeights = append(cvc.QuotaWeights, kept)
+		}
 	}
 }
 
@@ -89,15 +144,21 @@ func (cvc *CandidateVoteCount) IsInRace() bool {
 }
 
 func (cvc *CandidateVoteCount) GetCandidateResult() *CandidateResult {
+	numberOfBallots := len(cvc.Votes)
+	if cvc.QuotaBallots != nil {
+		// an elected candidate keeps a share of the ballots their surplus moved on
+		numberOfBallots = len(cvc.QuotaBallots)
+	}
 	return &CandidateResult{
-		Candidate:     cvc.Candidate,
-		NumberOfVotes: cvc.NumberOfVotes,
-		Status:        cvc.Status,
+		Candidate:       cvc.Candidate,
+		NumberOfVotes:   copyRat(cvc.NumberOfVotes),
+		NumberOfBallots: numberOfBallots,
+		Status:          cvc.Status,
 	}
 }
 
 func (cvc *CandidateVoteCount) String() string {
-	return fmt.Sprintf("CandidateVoteCount(candidate=%s, votes=%.2f)", cvc.Candidate, cvc.NumberOfVotes)
+	return fmt.Sprintf("CandidateVoteCount(candidate=%s, votes=%s)", cvc.Candidate, cvc.NumberOfVotes.FloatString(2))
 }
 
 type CompareMethod string
@@ -116,15 +177,39 @@ type ElectionManager struct {
 	CandidatesInRace    []*CandidateVoteCount
 	CandidatesElected   []*CandidateVoteCount
 	CandidatesRejected  []*CandidateVoteCount
+	CandidatesWithdrawn []*CandidateVoteCount
 	ExhaustedBallots    []*Ballot
-	NumberOfBlankVotes  float64
+	NumberOfBlankVotes  *big.Rat
+	LossByFractions     *big.Rat
 	NumberOfCandidates  int
+
+	// TieBreakSeed is the seed every random decision was drawn from
+	TieBreakSeed string
+	// LotOrder is the order ties decided by lot go in, drawn once from the seed
+	LotOrder       []*Candidate
+	lotRank        map[*Candidate]int
+	rng            *rand.Rand
+	roundTieBreaks []*TieBreak
+	roundEvents    []*RoundEvent
+	roundTransfers []*Transfer
+	// meekBallots is the number of ballots each candidate kept some of in the
+	// last Meek distribution, as Meek never moves ballots between candidates
+	meekBallots map[*Candidate]int
+	// parcel numbers the stages of the count so each recipient can tell the
+	// ballots received at each stage apart
+	parcel int
 }
 
 type ElectionManagerOptions struct {
 	NumberOfVotesPerVoter int
 	CompareMethodIfEqual  CompareMethod
 	PickRandomIfBlank     bool
+	Precision             Precision
+	SurplusRule           SurplusRule
+	// TieBreakSeed makes tie-breaks reproducible, a fresh seed is generated if empty
+	TieBreakSeed string
+	// Withdrawn candidates are excluded before the first round
+	Withdrawn []*Candidate
 }
 
 func DefaultElectionManagerOptions() ElectionManagerOptions {
@@ -132,46 +217,79 @@ func DefaultElectionManagerOptions() ElectionManagerOptions {
 		NumberOfVotesPerVoter: 1,
 		CompareMethodIfEqual:  CompareMethodMostSecondChoice,
 		PickRandomIfBlank:     false,
+		Precision:             Precision{Arithmetic: ArithmeticExact},
 	}
 }
 
-func NewElectionManager(candidates []*Candidate, ballots []*Ballot, options ElectionManagerOptions) *ElectionManager {
+// NewElectionManager allocates the ballots to their first preferences, it
+// returns an *InvalidBallotsError if any of the ballots can't be counted
+func NewElectionManager(candidates []*Candidate, ballots []*Ballot, options ElectionManagerOptions) (*ElectionManager, error) {
+	if err := ValidateBallots(candidates, ballots); err != nil {
+		return nil, err
+	}
 	candidateVoteCounts := make(map[*Candidate]*CandidateVoteCount)
+	candidatesInRace := make([]*CandidateVoteCount, 0)
+	candidatesWithdrawn := make([]*CandidateVoteCount, 0)
+	roundEvents := make([]*RoundEvent, 0)
 	for _, candidate := range candidates {
 		candidateVoteCounts[candidate] = NewCandidateVoteCount(candidate)
+		if slices.Contains(options.Withdrawn, candidate) {
+			candidateVoteCounts[candidate].Status = Withdrawn
+			candidatesWithdrawn = append(candidatesWithdrawn, candidateVoteCounts[candidate])
+			roundEvents = append(roundEvents, &RoundEvent{
+				Kind:       RoundEventWithdrawn,
+				Candidates: []*Candidate{candidate},
+				Votes:      new(big.Rat),
+			})
+			continue
+		}
+		candidatesInRace = append(candidatesInRace, candidateVoteCounts[candidate])
 	}
 
-	candidatesInRace := make([]*CandidateVoteCount, 0)
-	for _, cvc := range candidateVoteCounts {
-		candidatesInRace = append(candidatesInRace, cvc)
+	tieBreakSeed := options.TieBreakSeed
+	if len(tieBreakSeed) == 0 {
+		tieBreakSeed = NewTieBreakSeed()
+	}
+	rng := newTieBreakRand(tieBreakSeed)
+	lotOrder := drawLotOrder(candidates, rng)
+	lotRank := make(map[*Candidate]int, len(lotOrder))
+	for i, candidate := range lotOrder {
+		lotRank[candidate] = i
 	}
+	roundTieBreaks := make([]*TieBreak, 0)
 
 	exhaustedBallots := make([]*Ballot, 0)
-	nbBlankVotes := 0.0
+	nbBlankVotes := new(big.Rat)
 
 	for _, ballot := range ballots {
-		candidatesThatShouldBeVotedOn := ballot.RankedCandidates[0:options.NumberOfVotesPerVoter]
-		numberOfBlankVotes := options.NumberOfVotesPerVoter - len(ballot.RankedCandidates)
+		_, standing := withoutWithdrawn(nil, []*Ballot{ballot}, options.Withdrawn)
+		rankedCandidates := standing[0].RankedCandidates
+		// blank ballots, such as those imported from a blt, rank fewer candidates
+		candidatesThatShouldBeVotedOn := rankedCandidates[0:min(options.NumberOfVotesPerVoter, len(rankedCandidates))]
+		numberOfBlankVotes := options.NumberOfVotesPerVoter - len(rankedCandidates)
 
 		if numberOfBlankVotes > 0 {
 			if options.PickRandomIfBlank {
+				if len(candidatesInRace) == 0 {
+					return nil, fmt.Errorf("no candidates standing to pick from for a blank ballot")
+				}
 				for i := 0; i < numberOfBlankVotes; i++ {
-					nBig, err := rand.Int(rand.Reader, big.NewInt(int64(len(candidates))))
-					if err != nil {
-						panic(err)
-					}
-					newCandidateChoice := candidates[int(nBig.Int64())]
+					newCandidateChoice := candidatesInRace[rng.IntN(len(candidatesInRace))].Candidate
 					candidatesThatShouldBeVotedOn = append(candidatesThatShouldBeVotedOn, newCandidateChoice)
+					roundTieBreaks = append(roundTieBreaks, &TieBreak{
+						Candidates: []*Candidate{newCandidateChoice},
+						Method:     CompareMethodRandom,
+						Reason:     TieBreakReasonBlankBallot,
+					})
 				}
 			} else {
 				exhaustedBallots = append(exhaustedBallots, ballot)
-				nbBlankVotes += float64(numberOfBlankVotes)
+				nbBlankVotes.Add(nbBlankVotes, new(big.Rat).Mul(newRat(int64(numberOfBlankVotes)), ballot.value()))
 			}
 		}
 
 		for _, candidate := range candidatesThatShouldBeVotedOn {
-			candidateVoteCounts[candidate].NumberOfVotes++
-			candidateVoteCounts[candidate].Votes = append(candidateVoteCounts[candidate].Votes, ballot)
+			candidateVoteCounts[candidate].addVote(ballot, ballot.value(), 0)
 		}
 	}
 
@@ -184,36 +302,43 @@ func NewElectionManager(candidates []*Candidate, ballots []*Ballot, options Elec
 		CandidatesInRace:    candidatesInRace,
 		CandidatesElected:   make([]*CandidateVoteCount, 0),
 		CandidatesRejected:  make([]*CandidateVoteCount, 0),
+		CandidatesWithdrawn: candidatesWithdrawn,
 		ExhaustedBallots:    exhaustedBallots,
 		NumberOfBlankVotes:  nbBlankVotes,
+		LossByFractions:     new(big.Rat),
 		NumberOfCandidates:  len(candidates),
+
+		TieBreakSeed:   tieBreakSeed,
+		LotOrder:       lotOrder,
+		lotRank:        lotRank,
+		rng:            rng,
+		roundTieBreaks: roundTieBreaks,
+		roundEvents:    roundEvents,
+		roundTransfers: make([]*Transfer, 0),
 	}
 
 	electionManager.SortCandidatesInRace()
 
-	return electionManager
+	return electionManager, nil
 }
 
+// SortCandidatesInRace orders the hopeful candidates by votes, each group on
+// equal votes is ordered by the tie-break rules and the decision recorded
 func (em *ElectionManager) SortCandidatesInRace() {
 	sort.SliceStable(em.CandidatesInRace, func(i, j int) bool {
-		c1Votes := em.CandidatesInRace[i].NumberOfVotes
-		c2Votes := em.CandidatesInRace[j].NumberOfVotes
-
-		if !almostEqual(c1Votes, c2Votes) {
-			return c1Votes > c2Votes
-		}
+		return em.CandidatesInRace[i].NumberOfVotes.Cmp(em.CandidatesInRace[j].NumberOfVotes) > 0
+	})
 
-		if em.CompareMethodIfEqual == CompareMethodMostSecondChoice {
-			return em.Candidate1HasMostSecondChoices(em.CandidatesInRace[i], em.CandidatesInRace[j], 1)
+	for start := 0; start < len(em.CandidatesInRace); {
+		end := start + 1
+		for end < len(em.CandidatesInRace) && em.CandidatesInRace[end].NumberOfVotes.Cmp(em.CandidatesInRace[start].NumberOfVotes) == 0 {
+			end++
 		}
-		// Random method by default if not second choice
-		// requires to be input of 2 as it doesn't include upper bound
-		nBig, err := rand.Int(rand.Reader, big.NewInt(int64(2)))
-		if err != nil {
-			panic(err)
+		if end-start > 1 {
+			em.breakTie(em.CandidatesInRace[start:end])
 		}
-		return nBig.Int64() == 0
-	})
+		start = end
+	}
 }
 
 func (em *ElectionManager) IsValidCandidate(candidate *Candidate) bool {
@@ -263,11 +388,15 @@ func (em *ElectionManager) RejectCandidate(candidate *Candidate) error {
 	return nil
 }
 
-func (em *ElectionManager) TransferVotes(candidate *Candidate, numberOfTransferVotes float64) error {
+// TransferVotes moves numberOfTransferVotes from the candidate to the next
+// preference on their ballots, which ballots move and at what value is decided
+// by the surplus rule. In fixed decimal counts the transfer values are
+// truncated and the remainder is recorded as lost by fractions.
+func (em *ElectionManager) TransferVotes(candidate *Candidate, numberOfTransferVotes *big.Rat) error {
 	if !em.IsValidCandidate(candidate) {
 		return fmt.Errorf("candidate not found in election manager")
 	}
-	if math.Floor(numberOfTransferVotes*10_000)/10_000 == 0.000 {
+	if numberOfTransferVotes.Sign() == 0 {
 		return nil
 	}
 
@@ -275,35 +404,49 @@ func (em *ElectionManager) TransferVotes(candidate *Candidate, numberOfTransferV
 	if candidateCV.Status == Hopeful {
 		return fmt.Errorf("election manager cannot transfer votes from a candidate that is still in the race (hopeful)")
 	}
+	if len(candidateCV.Votes) == 0 {
+		return nil
+	}
 
-	voters := len(candidateCV.Votes)
-	votesPerVoter := numberOfTransferVotes / float64(voters)
+	ballots, values, transfer := em.transferBallots(candidateCV, numberOfTransferVotes)
+	transferred := new(big.Rat)
+	for i, ballot := range ballots {
+		votesPerVoter := values[i]
+		transferred.Add(transferred, votesPerVoter)
 
-	for _, ballot := range candidateCV.Votes {
 		newCandidateChoice := em.GetBallotCandidateNrXInRaceOrNone(ballot, em.NumberOfVotesPerVoter-1)
 
 		if newCandidateChoice == nil && em.PickRandomIfBlank {
 			candidatesInRace := em.GetCandidatesInRace()
 			if len(candidatesInRace) > 0 {
-				nBig, err := rand.Int(rand.Reader, big.NewInt(int64(len(candidatesInRace))))
-				if err != nil {
-					return fmt.Errorf("failed to generate random new candidate choice: %w", err)
-				}
-				newCandidateChoice = candidatesInRace[int(nBig.Int64())]
+				newCandidateChoice = candidatesInRace[em.rng.IntN(len(candidatesInRace))]
+				em.recordTieBreak(&TieBreak{
+					Candidates: []*Candidate{newCandidateChoice},
+					Method:     CompareMethodRandom,
+					Reason:     TieBreakReasonBlankBallot,
+				})
 			}
 		}
 
 		if newCandidateChoice != nil {
-			em.CandidateVoteCounts[newCandidateChoice].NumberOfVotes += votesPerVoter
-			em.CandidateVoteCounts[newCandidateChoice].Votes = append(em.CandidateVoteCounts[newCandidateChoice].Votes, ballot)
+			em.CandidateVoteCounts[newCandidateChoice].addVote(ballot, votesPerVoter, em.parcel)
 		} else {
 			em.ExhaustedBallots = append(em.ExhaustedBallots, ballot)
-			em.NumberOfBlankVotes += votesPerVoter
+			em.NumberOfBlankVotes.Add(em.NumberOfBlankVotes, votesPerVoter)
 		}
+		transfer.add(newCandidateChoice, votesPerVoter)
 	}
 
-	candidateCV.NumberOfVotes -= numberOfTransferVotes
+	transfer.LossByFractions.Sub(numberOfTransferVotes, transferred)
+	em.LossByFractions.Add(em.LossByFractions, transfer.LossByFractions)
+	em.roundTransfers = append(em.roundTransfers, transfer)
+	candidateCV.NumberOfVotes.Sub(candidateCV.NumberOfVotes, numberOfTransferVotes)
+	if candidateCV.Status == Elected {
+		candidateCV.keepQuota(ballots, values)
+	}
 	candidateCV.Votes = []*Ballot{}
+	candidateCV.Weights = []*big.Rat{}
+	candidateCV.Parcels = []int{}
 
 	em.SortCandidatesInRace()
 
@@ -335,12 +478,16 @@ func (em *ElectionManager) GetCandidatesInRace() []*Candidate {
 	return candidatesInRace
 }
 
-func (em *ElectionManager) GetNumberOfNonExhaustedVotes() float64 {
-	return float64(len(em.Ballots)*em.NumberOfVotesPerVoter) - em.NumberOfBlankVotes
+func (em *ElectionManager) GetNumberOfNonExhaustedVotes() *big.Rat {
+	votes := new(big.Rat).Mul(totalValue(em.Ballots), newRat(int64(em.NumberOfVotesPerVoter)))
+	return votes.Sub(votes, em.NumberOfBlankVotes)
 }
 
-func (em *ElectionManager) GetNumberOfNonExhaustedBallots() float64 {
-	return float64(len(em.Ballots) - len(em.ExhaustedBallots))
+// GetNumberOfNonExhaustedBallots returns the value of the ballots that aren't
+// exhausted, weighted ballots count at their weight
+func (em *ElectionManager) GetNumberOfNonExhaustedBallots() *big.Rat {
+	ballots := totalValue(em.Ballots)
+	return ballots.Sub(ballots, totalValue(em.ExhaustedBallots))
 }
 
 func (em *ElectionManager) GetNumberOfCandidatesInRace() uint64 {
@@ -351,11 +498,11 @@ func (em *ElectionManager) GetNumberOfElectedCandidates() uint64 {
 	return uint64(len(em.CandidatesElected))
 }
 
-func (em *ElectionManager) GetNumberOfVotes(candidate *Candidate) (float64, error) {
+func (em *ElectionManager) GetNumberOfVotes(candidate *Candidate) (*big.Rat, error) {
 	if !em.IsValidCandidate(candidate) {
-		return 0.0, fmt.Errorf("candidate not found in election manager")
+		return nil, fmt.Errorf("candidate not found in election manager")
 	}
-	return em.CandidateVoteCounts[candidate].NumberOfVotes, nil
+	return copyRat(em.CandidateVoteCounts[candidate].NumberOfVotes), nil
 }
 
 func (em *ElectionManager) GetCandidateWithLeastVotesInRace() (*Candidate, error) {
@@ -368,7 +515,7 @@ func (em *ElectionManager) GetCandidateWithLeastVotesInRace() (*Candidate, error
 func (em *ElectionManager) GetCandidatesWithMoreThanXVotes(x int) []*Candidate {
 	candidates := make([]*Candidate, 0)
 	for _, cvc := range em.CandidatesInRace {
-		if math.Floor(cvc.NumberOfVotes*10_000)/10_000 > float64(x*10_000)/10_000 {
+		if cvc.NumberOfVotes.Cmp(newRat(int64(x))) > 0 {
 			candidates = append(candidates, cvc.Candidate)
 		}
 	}
@@ -380,55 +527,82 @@ func (em *ElectionManager) GetResults() *RoundResult {
 	candidatesVC = append(candidatesVC, em.CandidatesElected...)
 	candidatesVC = append(candidatesVC, em.CandidatesInRace...)
 	candidatesVC = append(candidatesVC, em.CandidatesRejected...)
+	candidatesVC = append(candidatesVC, em.CandidatesWithdrawn...)
 
 	candidateResults := make([]*CandidateResult, 0)
 	for _, c := range candidatesVC {
-		candidateResults = append(candidateResults, c.GetCandidateResult())
-	}
-
-	return NewRoundResult(candidateResults, em.NumberOfBlankVotes)
-}
-
-func (em *ElectionManager) Candidate1HasMostSecondChoices(c1vc, c2vc *CandidateVoteCount, x int) bool {
-	if x >= em.NumberOfCandidates {
-		// requires to be input of 2 as it doesn't include upper bound
-		nBig, err := rand.Int(rand.Reader, big.NewInt(int64(2)))
-		if err != nil {
-			panic(err)
+		candidateResult := c.GetCandidateResult()
+		if em.meekBallots != nil {
+			candidateResult.NumberOfBallots = em.meekBallots[c.Candidate]
 		}
-		return nBig.Uint64() == 1
+		candidateResults = append(candidateResults, candidateResult)
 	}
 
-	votesCandidate1 := 0
-	votesCandidate2 := 0
+	tieBreaks := em.roundTieBreaks
+	em.roundTieBreaks = make([]*TieBreak, 0)
+	events := em.roundEvents
+	em.roundEvents = make([]*RoundEvent, 0)
+	transfers := em.roundTransfers
+	em.roundTransfers = make([]*Transfer, 0)
+	em.parcel++
 
-	for _, ballot := range em.Ballots {
-		candidate := em.GetBallotCandidateNrXInRaceOrNone(ballot, x)
-
-		switch candidate {
-		case c1vc.Candidate:
-			votesCandidate1++
-		case c2vc.Candidate:
-			votesCandidate2++
-		default:
-			continue
-		}
-	}
+	return NewRoundResult(candidateResults, em.NumberOfBlankVotes, em.LossByFractions, tieBreaks, events, transfers)
+}
 
-	if votesCandidate1 == votesCandidate2 {
-		return em.Candidate1HasMostSecondChoices(c1vc, c2vc, x+1)
+// Candidate1HasMostSecondChoices reports whether c1vc wins a tie against c2vc
+// on the xth and later choices, falling back to the lot order
+func (em *ElectionManager) Candidate1HasMostSecondChoices(c1vc, c2vc *CandidateVoteCount, x int) bool {
+	if cmp := em.compareLaterChoices(c1vc, c2vc, x); cmp != 0 {
+		return cmp > 0
 	}
-	return votesCandidate1 > votesCandidate2
+	return em.compareLot(c1vc, c2vc) < 0
 }
 
 type ElectionResults struct {
 	Rounds []*RoundResult
+	// Precision is the arithmetic the count was carried out in
+	Precision Precision
+	// TieBreakSeed and LotOrder let the tie-breaks be replayed
+	TieBreakSeed string
+	LotOrder     []*Candidate
+	// Withdrawn are the candidates excluded before the first round
+	Withdrawn []*Candidate
+	// Quota is the quota the count used, QuotaValue the votes it came to, in
+	// Meek this is the quota in the last round as it falls while ballots exhaust
+	Quota      Quota
+	QuotaValue *big.Rat
+	// SurplusRule is the rule surpluses were transferred by, empty for Meek
+	SurplusRule SurplusRule
+	// BatchElimination is whether hopeless candidates were excluded together
+	BatchElimination bool
+	// Schulze holds the pairwise tables of a Schulze count, nil otherwise
+	Schulze *SchulzeMatrix
+	// NumberOfBallots is the number of ballots counted and WeightOfBallots the
+	// value they entered the count with, the same unless ballots are weighted
+	NumberOfBallots int
+	WeightOfBallots *big.Rat
+}
+
+func NewElectionResults(precision Precision, manager *ElectionManager) *ElectionResults {
+	return &ElectionResults{
+		Rounds:          make([]*RoundResult, 0),
+		Precision:       precision,
+		TieBreakSeed:    manager.TieBreakSeed,
+		LotOrder:        manager.LotOrder,
+		Withdrawn:       withdrawnCandidates(manager.CandidatesWithdrawn),
+		QuotaValue:      new(big.Rat),
+		NumberOfBallots: len(manager.Ballots),
+		WeightOfBallots: totalValue(manager.Ballots),
+	}
 }
 
-func NewElectionResults() *ElectionResults {
-	return &ElectionResults{
-		Rounds: make([]*RoundResult, 0),
+// withdrawnCandidates are the candidates of the withdrawn vote counts
+func withdrawnCandidates(withdrawn []*CandidateVoteCount) []*Candidate {
+	candidates := make([]*Candidate, 0, len(withdrawn))
+	for _, cvc := range withdrawn {
+		candidates = append(candidates, cvc.Candidate)
 	}
+	return candidates
 }
 
 func (er *ElectionResults) RegisterResults(round *RoundResult) {
@@ -450,3 +624,23 @@ func (er *ElectionResults) GetWinners() []*Candidate {
 
 	return winnerCandidates
 }
+
+// withoutWithdrawn returns the candidates still standing and the ballots with
+// the withdrawn candidates struck off, the ballots are copied only if needed
+func withoutWithdrawn(candidates []*Candidate, ballots []*Ballot, withdrawn []*Candidate) ([]*Candidate, []*Ballot) {
+	if len(withdrawn) == 0 {
+		return candidates, ballots
+	}
+	isWithdrawn := func(candidate *Candidate) bool {
+		return slices.Contains(withdrawn, candidate)
+	}
+	standing := slices.DeleteFunc(slices.Clone(candidates), isWithdrawn)
+	standingBallots := make([]*Ballot, 0, len(ballots))
+	for _, ballot := range ballots {
+		standingBallots = append(standingBallots, &Ballot{
+			RankedCandidates: slices.DeleteFunc(slices.Clone(ballot.RankedCandidates), isWithdrawn),
+			Weight:           ballot.Weight,
+		})
+	}
+	return standing, standingBallots
+}
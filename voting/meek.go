@@ -0,0 +1,208 @@
+package voting
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// meekMaxIterations bounds the keep value iteration in a single round, Meek
+// converges geometrically so this is only hit if something has gone wrong
+const meekMaxIterations = 1000
+
+// meekSurplusLimitPlaces is the number of decimal places of total surplus at
+// which the keep values are considered to have converged
+const meekSurplusLimitPlaces = 5
+
+// MeekSingleTransferableVote counts the ballots using Meek's method, each
+// elected candidate keeps a fraction of every vote that reaches them (their
+// keep value) and passes the rest on. Keep values and the quota are
+// recalculated until the surpluses are negligible, so the quota falls as
+// ballots exhaust and earlier transfers are revisited every round.
+//
+// Keep values are rounded up and votes truncated to the precision's decimal
+// places, exact arithmetic is refused as keep values only converge in the limit.
+func MeekSingleTransferableVote(candidates []*Candidate, ballots []*Ballot, numberOfSeats uint64, options SingleTransferableVoteOptions) (*ElectionResults, error) {
+	if err := options.Precision.Validate(); err != nil {
+		return nil, err
+	}
+	if err := options.Quota.Validate(); err != nil {
+		return nil, err
+	}
+	if !options.Precision.IsFixedDecimal() {
+		return nil, fmt.Errorf("%s needs fixed decimal arithmetic, keep values only converge in the limit", CountMethodMeek)
+	}
+	manager, err := NewElectionManager(candidates, ballots, ElectionManagerOptions{
+		NumberOfVotesPerVoter: 1,
+		CompareMethodIfEqual:  options.CompareMethodIfEquals,
+		PickRandomIfBlank:     options.PickRandomIfBlank,
+		Precision:             options.Precision,
+		TieBreakSeed:          options.TieBreakSeed,
+		Withdrawn:             options.Withdrawn,
+	})
+	if err != nil {
+		return nil, err
+	}
+	electionResults := NewElectionResults(options.Precision, manager)
+	electionResults.Quota = options.Quota
+
+	keepValues := make(map[*Candidate]*big.Rat, len(candidates))
+	for _, c := range candidates {
+		keepValues[c] = newRat(1)
+		if slices.Contains(options.Withdrawn, c) {
+			keepValues[c] = new(big.Rat)
+		}
+	}
+
+	for {
+		votesNeededToWin, err := manager.meekConverge(keepValues, options.Quota, numberOfSeats)
+		if err != nil {
+			return nil, err
+		}
+		electionResults.QuotaValue = votesNeededToWin
+
+		candidatesToElect := make([]*Candidate, 0)
+		for _, candidate := range manager.GetCandidatesInRace() {
+			votes, err := manager.GetNumberOfVotes(candidate)
+			if err != nil {
+				return nil, err
+			}
+			if options.Quota.IsReached(votes, votesNeededToWin) && manager.GetNumberOfElectedCandidates()+uint64(len(candidatesToElect)) < numberOfSeats {
+				candidatesToElect = append(candidatesToElect, candidate)
+			}
+		}
+
+		for _, candidate := range candidatesToElect {
+			manager.recordEvent(RoundEventElectedQuota, candidate)
+			if err = manager.ElectCandidate(candidate); err != nil {
+				return nil, err
+			}
+		}
+
+		seatsLeft := numberOfSeats - manager.GetNumberOfElectedCandidates()
+		if len(candidatesToElect) == 0 && manager.GetNumberOfCandidatesInRace() > seatsLeft {
+			candidate, err := manager.GetCandidateWithLeastVotesInRace()
+			if err != nil {
+				return nil, fmt.Errorf("election ended up in an illegal state: %w", err)
+			}
+			manager.recordExclusionTie([]*Candidate{candidate})
+			manager.recordEvent(RoundEventExcludedLowest, candidate)
+			if err = manager.RejectCandidate(candidate); err != nil {
+				return nil, err
+			}
+			keepValues[candidate] = new(big.Rat)
+		}
+
+		seatsLeft = numberOfSeats - manager.GetNumberOfElectedCandidates()
+		if manager.GetNumberOfCandidatesInRace() <= seatsLeft {
+			for _, candidate := range manager.GetCandidatesInRace() {
+				manager.recordEvent(RoundEventElectedRemainingSeats, candidate)
+				if err = manager.ElectCandidate(candidate); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		seatsLeft = numberOfSeats - manager.GetNumberOfElectedCandidates()
+		if seatsLeft == 0 {
+			candidatesInRace := manager.GetCandidatesInRace()
+			for i := len(candidatesInRace) - 1; i >= 0; i-- {
+				manager.recordEvent(RoundEventExcludedSeatsFilled, candidatesInRace[i])
+				if err = manager.RejectCandidate(candidatesInRace[i]); err != nil {
+					return nil, err
+				}
+				keepValues[candidatesInRace[i]] = new(big.Rat)
+			}
+		}
+
+		electionResults.RegisterResults(manager.GetResults())
+
+		if manager.GetNumberOfCandidatesInRace() == 0 {
+			break
+		}
+	}
+	return electionResults, nil
+}
+
+// meekDistribute passes every ballot down its preferences, each candidate
+// keeps their keep value's share of what reaches them and anything left at
+// the end of the ballot is exhausted. Whatever truncation drops is lost by
+// fractions.
+func (em *ElectionManager) meekDistribute(keepValues map[*Candidate]*big.Rat) {
+	for _, cvc := range em.CandidateVoteCounts {
+		cvc.NumberOfVotes = new(big.Rat)
+	}
+	em.NumberOfBlankVotes = new(big.Rat)
+
+	em.meekBallots = make(map[*Candidate]int, len(em.CandidateVoteCounts))
+	one := newRat(1)
+	for _, ballot := range em.Ballots {
+		weight := ballot.value()
+		for _, candidate := range ballot.RankedCandidates {
+			keep := keepValues[candidate]
+			if keep.Sign() == 0 {
+				continue
+			}
+			kept := em.Precision.Truncate(new(big.Rat).Mul(weight, keep))
+			if kept.Sign() > 0 {
+				em.meekBallots[candidate]++
+			}
+			em.CandidateVoteCounts[candidate].NumberOfVotes.Add(em.CandidateVoteCounts[candidate].NumberOfVotes, kept)
+			weight.Mul(weight, new(big.Rat).Sub(one, keep))
+			em.Precision.Truncate(weight)
+			if weight.Sign() == 0 {
+				break
+			}
+		}
+		em.NumberOfBlankVotes.Add(em.NumberOfBlankVotes, weight)
+	}
+
+	em.LossByFractions = totalValue(em.Ballots)
+	em.LossByFractions.Sub(em.LossByFractions, em.NumberOfBlankVotes)
+	for _, cvc := range em.CandidateVoteCounts {
+		em.LossByFractions.Sub(em.LossByFractions, cvc.NumberOfVotes)
+	}
+}
+
+// meekConverge iterates the keep values of the elected candidates until their
+// total surplus is negligible or stops falling, returning the final quota
+func (em *ElectionManager) meekConverge(keepValues map[*Candidate]*big.Rat, quota Quota, numberOfSeats uint64) (*big.Rat, error) {
+	surplusLimit := new(big.Rat).SetFrac(big.NewInt(1), decimalScale(min(em.Precision.DecimalPlaces, meekSurplusLimitPlaces)))
+	var lastSurplus *big.Rat
+	for i := 0; i < meekMaxIterations; i++ {
+		em.meekDistribute(keepValues)
+
+		votesRemaining := totalValue(em.Ballots)
+		votesRemaining.Sub(votesRemaining, em.NumberOfBlankVotes)
+		votesRemaining.Sub(votesRemaining, em.LossByFractions)
+		votesNeededToWin := quota.Value(votesRemaining, numberOfSeats, em.Precision)
+
+		surplus := new(big.Rat)
+		for _, cvc := range em.CandidatesElected {
+			surplus.Add(surplus, new(big.Rat).Sub(cvc.NumberOfVotes, votesNeededToWin))
+		}
+
+		hopefulReachedQuota := false
+		for _, cvc := range em.CandidatesInRace {
+			if quota.IsReached(cvc.NumberOfVotes, votesNeededToWin) {
+				hopefulReachedQuota = true
+			}
+		}
+
+		if surplus.Cmp(surplusLimit) < 0 || hopefulReachedQuota || (lastSurplus != nil && surplus.Cmp(lastSurplus) >= 0) {
+			em.SortCandidatesInRace()
+			return votesNeededToWin, nil
+		}
+		lastSurplus = surplus
+
+		for _, cvc := range em.CandidatesElected {
+			if cvc.NumberOfVotes.Sign() > 0 {
+				keep := keepValues[cvc.Candidate]
+				keep.Mul(keep, votesNeededToWin)
+				keep.Quo(keep, cvc.NumberOfVotes)
+				roundUpRat(keep, em.Precision.DecimalPlaces)
+			}
+		}
+	}
+	return nil, fmt.Errorf("meek keep values failed to converge after %d iterations", meekMaxIterations)
+}
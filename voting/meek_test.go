@@ -0,0 +1,62 @@
+package voting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func meekOptions() SingleTransferableVoteOptions {
+	options := DefaultSingleTransferableVoteOptions()
+	options.Precision = Precision{Arithmetic: ArithmeticFixedDecimal, DecimalPlaces: 9}
+	return options
+}
+
+func TestMeekScotland2022(t *testing.T) {
+	blt := parseTestBLT(t, "testdata/Scotland2022_Ward_1_Penicuik.blt")
+
+	results, err := MeekSingleTransferableVote(blt.Candidates, blt.Ballots, blt.NumberOfSeats, meekOptions())
+	require.NoError(t, err)
+	AssertVoteWinners(t, results, []string{
+		"Debbi MCCALL",
+		"Willie MCEWAN",
+		"Connor MCMANUS",
+	})
+}
+
+func TestMeekSurplusElects(t *testing.T) {
+	// 13 votes for 2 seats, A's surplus over the quota of 13/3 takes C past B
+	candidates := testCandidates("A", "B", "C")
+	ballots := testBallots(t, candidates, 6, 0, 2)
+	ballots = append(ballots, testBallots(t, candidates, 4, 1)...)
+	ballots = append(ballots, testBallots(t, candidates, 3, 2)...)
+
+	results, err := MeekSingleTransferableVote(candidates, ballots, 2, meekOptions())
+	require.NoError(t, err)
+	AssertVoteWinners(t, results, []string{"A", "C"})
+	assert.Equal(t, Precision{Arithmetic: ArithmeticFixedDecimal, DecimalPlaces: 9}, results.Precision)
+}
+
+func TestMeekQuotaFallsAsBallotsExhaust(t *testing.T) {
+	// no one reaches the quota of 16/3 until D is excluded, D's voters rank no
+	// one else so the quota falls to 13/3 and A's surplus then elects B
+	candidates := testCandidates("A", "B", "C", "D")
+	ballots := testBallots(t, candidates, 5, 0, 1)
+	ballots = append(ballots, testBallots(t, candidates, 4, 1)...)
+	ballots = append(ballots, testBallots(t, candidates, 4, 2)...)
+	ballots = append(ballots, testBallots(t, candidates, 3, 3)...)
+
+	results, err := MeekSingleTransferableVote(candidates, ballots, 2, meekOptions())
+	require.NoError(t, err)
+	AssertVoteWinners(t, results, []string{"A", "B"})
+	assert.Equal(t, "4.333333333", results.Precision.FormatVotes(results.QuotaValue))
+}
+
+func TestMeekRefusesExactArithmetic(t *testing.T) {
+	candidates := testCandidates("A", "B")
+	ballots := testBallots(t, candidates, 2, 0, 1)
+
+	_, err := MeekSingleTransferableVote(candidates, ballots, 1, DefaultSingleTransferableVoteOptions())
+	assert.Error(t, err)
+}
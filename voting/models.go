@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"strings"
 )
 
@@ -30,14 +31,52 @@ func (c *Candidate) Equals(a *Candidate) bool {
 }
 
 type Ballot struct {
+	// ID is the stored ballot's id, it is only used to report invalid ballots
+	ID               string
 	RankedCandidates []*Candidate
+	// Weight is the value the ballot enters the count with, nil counts as 1
+	Weight *big.Rat
 }
 
-func NewBallot(candidates []*Candidate) *Ballot {
+// NewBallot returns a ballot ranking the candidates, a candidate can only be
+// ranked once
+func NewBallot(candidates []*Candidate) (*Ballot, error) {
 	if hasDuplicates(candidates) {
-		panic("duplicate candidate")
+		return nil, fmt.Errorf("ballot ranks a candidate more than once")
 	}
-	return &Ballot{candidates}
+	return &Ballot{RankedCandidates: candidates}, nil
+}
+
+// value returns the value the ballot enters the count with
+func (b *Ballot) value() *big.Rat {
+	if b.Weight == nil {
+		return newRat(1)
+	}
+	return copyRat(b.Weight)
+}
+
+// totalValue returns the value the ballots enter the count with, with no
+// weighted ballots it is the number of ballots
+func totalValue(ballots []*Ballot) *big.Rat {
+	total := new(big.Rat)
+	for _, ballot := range ballots {
+		if ballot.Weight == nil {
+			total.Add(total, newRat(1))
+			continue
+		}
+		total.Add(total, ballot.Weight)
+	}
+	return total
+}
+
+// isWeighted reports whether any of the ballots has a weight other than 1
+func isWeighted(ballots []*Ballot) bool {
+	for _, ballot := range ballots {
+		if ballot.Weight != nil && ballot.Weight.Cmp(newRat(1)) != 0 {
+			return true
+		}
+	}
+	return false
 }
 
 func hasDuplicates(candidates []*Candidate) bool {
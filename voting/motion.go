@@ -0,0 +1,95 @@
+package voting
+
+import "fmt"
+
+const (
+	MotionFor     = "For"
+	MotionAgainst = "Against"
+	MotionAbstain = "Abstain"
+)
+
+type MotionThreshold string
+
+const (
+	// MotionThresholdSimpleMajority passes with more votes for than against
+	MotionThresholdSimpleMajority = "SimpleMajority"
+	// MotionThresholdTwoThirds passes with two-thirds of every vote cast for,
+	// abstentions included
+	MotionThresholdTwoThirds = "TwoThirds"
+	// MotionThresholdTwoThirdsExcludingAbstentions passes with two-thirds of the
+	// votes for or against for
+	MotionThresholdTwoThirdsExcludingAbstentions = "TwoThirdsExcludingAbstentions"
+)
+
+func (mt MotionThreshold) Validate() error {
+	switch mt {
+	case MotionThresholdSimpleMajority, MotionThresholdTwoThirds, MotionThresholdTwoThirdsExcludingAbstentions:
+		return nil
+	default:
+		return fmt.Errorf("unknown motion threshold: %s", mt)
+	}
+}
+
+// MotionCandidates are the fixed options of a motion, ballots choose one of them
+func MotionCandidates() []*Candidate {
+	return []*Candidate{NewCandidate(MotionFor), NewCandidate(MotionAgainst), NewCandidate(MotionAbstain)}
+}
+
+type MotionResult struct {
+	VotesFor     uint64
+	VotesAgainst uint64
+	Abstentions  uint64
+	Threshold    MotionThreshold
+	// Quorum is the number of ballots, abstentions included, needed for the
+	// motion to be decided
+	Quorum        uint64
+	QuorumReached bool
+	Passed        bool
+}
+
+func (mr *MotionResult) String() string {
+	return fmt.Sprintf("MotionResult(for=%d, against=%d, abstain=%d, quorum=%t, passed=%t)", mr.VotesFor, mr.VotesAgainst, mr.Abstentions, mr.QuorumReached, mr.Passed)
+}
+
+// CountMotion counts ballots each choosing a single motion option, the motion
+// passes if the quorum is reached and the votes for meet the threshold
+func CountMotion(ballots []*Ballot, threshold MotionThreshold, quorum uint64) (*MotionResult, error) {
+	if err := threshold.Validate(); err != nil {
+		return nil, err
+	}
+
+	if isWeighted(ballots) {
+		return nil, fmt.Errorf("motions cannot count weighted ballots")
+	}
+
+	result := &MotionResult{
+		Threshold: threshold,
+		Quorum:    quorum,
+	}
+	if _, invalid := SetAsideInvalidMotionBallots(ballots); len(invalid) > 0 {
+		return nil, &InvalidBallotsError{Ballots: invalid}
+	}
+	for _, ballot := range ballots {
+		switch ballot.RankedCandidates[0].Name {
+		case MotionFor:
+			result.VotesFor++
+		case MotionAgainst:
+			result.VotesAgainst++
+		case MotionAbstain:
+			result.Abstentions++
+		}
+	}
+
+	result.QuorumReached = uint64(len(ballots)) >= quorum
+	switch threshold {
+	case MotionThresholdSimpleMajority:
+		result.Passed = result.VotesFor > result.VotesAgainst
+	case MotionThresholdTwoThirds:
+		result.Passed = 3*result.VotesFor >= 2*(result.VotesFor+result.VotesAgainst+result.Abstentions)
+	case MotionThresholdTwoThirdsExcludingAbstentions:
+		result.Passed = 3*result.VotesFor >= 2*(result.VotesFor+result.VotesAgainst)
+	}
+	result.Passed = result.Passed && result.QuorumReached && result.VotesFor > 0
+
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package voting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// motionBallots makes ballots for, against and abstaining on a motion
+func motionBallots(tb testing.TB, votesFor, votesAgainst, abstentions int) []*Ballot {
+	tb.Helper()
+	options := MotionCandidates()
+	ballots := testBallots(tb, options, votesFor, 0)
+	ballots = append(ballots, testBallots(tb, options, votesAgainst, 1)...)
+	return append(ballots, testBallots(tb, options, abstentions, 2)...)
+}
+
+func TestCountMotion(t *testing.T) {
+	tests := []struct {
+		name         string
+		threshold    MotionThreshold
+		votesFor     int
+		votesAgainst int
+		abstentions  int
+		quorum       uint64
+		passed       bool
+		quorumMet    bool
+	}{
+		{name: "simple majority", threshold: MotionThresholdSimpleMajority, votesFor: 5, votesAgainst: 4, abstentions: 6, passed: true, quorumMet: true},
+		{name: "simple majority tied", threshold: MotionThresholdSimpleMajority, votesFor: 4, votesAgainst: 4, quorumMet: true},
+		// 6 of 9 is exactly two-thirds
+		{name: "two-thirds at the boundary", threshold: MotionThresholdTwoThirds, votesFor: 6, votesAgainst: 2, abstentions: 1, passed: true, quorumMet: true},
+		{name: "two-thirds one short", threshold: MotionThresholdTwoThirds, votesFor: 6, votesAgainst: 2, abstentions: 2, quorumMet: true},
+		// the abstentions that sink the two-thirds above are left out here
+		{name: "two-thirds excluding abstentions", threshold: MotionThresholdTwoThirdsExcludingAbstentions, votesFor: 6, votesAgainst: 3, abstentions: 5, passed: true, quorumMet: true},
+		{name: "two-thirds excluding abstentions one short", threshold: MotionThresholdTwoThirdsExcludingAbstentions, votesFor: 5, votesAgainst: 3, abstentions: 5, quorumMet: true},
+		// abstentions count towards the quorum
+		{name: "quorum met with abstentions", threshold: MotionThresholdSimpleMajority, votesFor: 3, votesAgainst: 1, abstentions: 6, quorum: 10, passed: true, quorumMet: true},
+		{name: "quorum not met", threshold: MotionThresholdSimpleMajority, votesFor: 8, votesAgainst: 0, abstentions: 1, quorum: 10},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := CountMotion(motionBallots(t, test.votesFor, test.votesAgainst, test.abstentions), test.threshold, test.quorum)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(test.votesFor), result.VotesFor)
+			assert.Equal(t, uint64(test.votesAgainst), result.VotesAgainst)
+			assert.Equal(t, uint64(test.abstentions), result.Abstentions)
+			assert.Equal(t, test.quorumMet, result.QuorumReached)
+			assert.Equal(t, test.passed, result.Passed)
+		})
+	}
+}
+
+func TestCountMotionRefuses(t *testing.T) {
+	ballots := motionBallots(t, 1, 1, 0)
+	_, err := CountMotion(ballots, "Unanimous", 0)
+	assert.Error(t, err)
+
+	options := MotionCandidates()
+	both := testBallots(t, options, 1, 0, 1)
+	_, err = CountMotion(append(ballots, both...), MotionThresholdSimpleMajority, 0)
+	assert.Error(t, err)
+}
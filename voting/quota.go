@@ -0,0 +1,76 @@
+package voting
+
+import (
+	"fmt"
+	"math/big"
+)
+
+type Quota string
+
+const (
+	// QuotaDroop is the whole number floor(votes/(seats+1))+1, reached when a
+	// candidate's votes equal it
+	QuotaDroop = "Droop"
+	// QuotaHare is votes/seats, reached when a candidate's votes equal it
+	QuotaHare = "Hare"
+	// QuotaHagenbachBischoff is votes/(seats+1), reached when a candidate's
+	// votes equal it
+	QuotaHagenbachBischoff = "HagenbachBischoff"
+	// QuotaExactDroop is votes/(seats+1) as a fraction, only exceeded votes
+	// elect so it can't elect more candidates than seats
+	QuotaExactDroop = "ExactDroop"
+)
+
+func (q Quota) Validate() error {
+	switch q {
+	case "", QuotaDroop, QuotaHare, QuotaHagenbachBischoff, QuotaExactDroop:
+		return nil
+	default:
+		return fmt.Errorf("unknown quota: %s", q)
+	}
+}
+
+// Value calculates the quota for the valid votes and seats, fractional quotas
+// are rounded in the precision's favour of not electing too early, up for
+// quotas that are reached and down for quotas that must be exceeded
+func (q Quota) Value(votes *big.Rat, numberOfSeats uint64, precision Precision) *big.Rat {
+	switch q {
+	case QuotaDroop:
+		quota := new(big.Int).Quo(votes.Num(), votes.Denom())
+		quota.Quo(quota, new(big.Int).SetUint64(numberOfSeats+1))
+		quota.Add(quota, big.NewInt(1))
+		return new(big.Rat).SetInt(quota)
+	case QuotaHare:
+		quota := new(big.Rat).Quo(votes, new(big.Rat).SetUint64(numberOfSeats))
+		if precision.IsFixedDecimal() {
+			roundUpRat(quota, precision.DecimalPlaces)
+		}
+		return quota
+	case QuotaHagenbachBischoff:
+		quota := new(big.Rat).Quo(votes, new(big.Rat).SetUint64(numberOfSeats+1))
+		if precision.IsFixedDecimal() {
+			roundUpRat(quota, precision.DecimalPlaces)
+		}
+		return quota
+	default:
+		return precision.Truncate(new(big.Rat).Quo(votes, new(big.Rat).SetUint64(numberOfSeats+1)))
+	}
+}
+
+// IsReached reports whether votes are enough to be elected on the quota
+func (q Quota) IsReached(votes, quota *big.Rat) bool {
+	switch q {
+	case QuotaDroop, QuotaHare, QuotaHagenbachBischoff:
+		return votes.Cmp(quota) >= 0
+	default:
+		return votes.Cmp(quota) > 0
+	}
+}
+
+// String names the quota, an empty quota is counted as QuotaExactDroop
+func (q Quota) String() string {
+	if len(q) == 0 {
+		return QuotaExactDroop
+	}
+	return string(q)
+}
@@ -0,0 +1,79 @@
+package voting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaValue(t *testing.T) {
+	exact := Precision{Arithmetic: ArithmeticExact}
+	fixed := Precision{Arithmetic: ArithmeticFixedDecimal, DecimalPlaces: 2}
+	tests := []struct {
+		quota     Quota
+		votes     int64
+		seats     uint64
+		precision Precision
+		value     string
+	}{
+		{quota: QuotaDroop, votes: 12, seats: 2, precision: exact, value: "5"},
+		{quota: QuotaHare, votes: 12, seats: 2, precision: exact, value: "6"},
+		{quota: QuotaHagenbachBischoff, votes: 12, seats: 2, precision: exact, value: "4"},
+		{quota: QuotaExactDroop, votes: 12, seats: 2, precision: exact, value: "4"},
+		{quota: QuotaDroop, votes: 100, seats: 3, precision: exact, value: "26"},
+		{quota: QuotaHare, votes: 100, seats: 3, precision: exact, value: "100/3"},
+		{quota: QuotaExactDroop, votes: 10, seats: 2, precision: exact, value: "10/3"},
+		// fixed decimal rounds up the quotas that are reached and down the one that is exceeded
+		{quota: QuotaHare, votes: 100, seats: 3, precision: fixed, value: "33.34"},
+		{quota: QuotaHagenbachBischoff, votes: 10, seats: 2, precision: fixed, value: "3.34"},
+		{quota: QuotaExactDroop, votes: 10, seats: 2, precision: fixed, value: "3.33"},
+	}
+	for _, test := range tests {
+		value := test.quota.Value(newRat(test.votes), test.seats, test.precision)
+		assert.Equal(t, test.value, test.precision.FormatVotes(value), "%s of %d votes for %d seats", test.quota, test.votes, test.seats)
+	}
+}
+
+func TestSTVExactQuota(t *testing.T) {
+	// 12 votes for 2 seats, a Droop quota of 5 and an exact Droop or
+	// Hagenbach-Bischoff quota of 4
+	tests := []struct {
+		name    string
+		quota   Quota
+		counts  []int
+		elected int
+	}{
+		{name: "exact Droop must be exceeded", quota: QuotaExactDroop, counts: []int{4, 4, 3, 1}, elected: 0},
+		{name: "exact Droop exceeded", quota: QuotaExactDroop, counts: []int{5, 4, 3}, elected: 1},
+		{name: "Droop is a whole vote more", quota: QuotaDroop, counts: []int{4, 4, 3, 1}, elected: 0},
+		{name: "Droop reached", quota: QuotaDroop, counts: []int{5, 4, 3}, elected: 1},
+		{name: "Hagenbach-Bischoff reached", quota: QuotaHagenbachBischoff, counts: []int{4, 4, 3, 1}, elected: 2},
+		// all three reach the quota but there are only two seats
+		{name: "Hagenbach-Bischoff reached by more than the seats", quota: QuotaHagenbachBischoff, counts: []int{4, 4, 4}, elected: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidates := testCandidates("A", "B", "C", "D")[:len(test.counts)]
+			var ballots []*Ballot
+			for i, count := range test.counts {
+				ballots = append(ballots, testBallots(t, candidates, count, i)...)
+			}
+
+			options := DefaultSingleTransferableVoteOptions()
+			options.Quota = test.quota
+			options.TieBreakSeed = "quota"
+			results, err := SingleTransferableVote(candidates, ballots, 2, options)
+			require.NoError(t, err)
+			assert.Len(t, results.GetWinners(), 2)
+
+			var elected int
+			for _, result := range results.Rounds[0].CandidateResults {
+				if result.Status == Elected {
+					elected++
+				}
+			}
+			assert.Equal(t, test.elected, elected, "elected in the first round")
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package voting
+
+import (
+	"fmt"
+	"slices"
+)
+
+// SchulzeMatrix holds the pairwise comparisons of a Schulze count, rows and
+// columns are both in the order of Candidates
+type SchulzeMatrix struct {
+	Candidates []*Candidate
+	// Preferences is the number of voters who ranked the row candidate above
+	// the column candidate, unranked candidates count as below every ranked one
+	Preferences [][]int
+	// StrongestPaths is the strength of the strongest path from the row
+	// candidate to the column candidate through the preferences
+	StrongestPaths [][]int
+}
+
+// NewSchulzeMatrix counts the pairwise preferences of the ballots and finds the
+// strongest paths between every pair of candidates
+func NewSchulzeMatrix(candidates []*Candidate, ballots []*Ballot) *SchulzeMatrix {
+	n := len(candidates)
+	index := make(map[*Candidate]int, n)
+	for i, c := range candidates {
+		index[c] = i
+	}
+
+	preferences := newSquare(n)
+	for _, ballot := range ballots {
+		ranks := make([]int, n)
+		for i := range ranks {
+			ranks[i] = len(ballot.RankedCandidates)
+		}
+		for rank, c := range ballot.RankedCandidates {
+			ranks[index[c]] = rank
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if ranks[i] < ranks[j] {
+					preferences[i][j]++
+				}
+			}
+		}
+	}
+
+	strongestPaths := newSquare(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j && preferences[i][j] > preferences[j][i] {
+				strongestPaths[i][j] = preferences[i][j]
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i == j {
+				continue
+			}
+			for k := 0; k < n; k++ {
+				if i != k && j != k {
+					strongestPaths[j][k] = max(strongestPaths[j][k], min(strongestPaths[j][i], strongestPaths[i][k]))
+				}
+			}
+		}
+	}
+
+	return &SchulzeMatrix{
+		Candidates:     candidates,
+		Preferences:    preferences,
+		StrongestPaths: strongestPaths,
+	}
+}
+
+// Beats reports whether the ith candidate is ranked above the jth by Schulze
+func (sm *SchulzeMatrix) Beats(i, j int) bool {
+	return sm.StrongestPaths[i][j] > sm.StrongestPaths[j][i]
+}
+
+func (sm *SchulzeMatrix) wins(i int) int {
+	wins := 0
+	for j := range sm.Candidates {
+		if sm.Beats(i, j) {
+			wins++
+		}
+	}
+	return wins
+}
+
+// Schulze counts ranked ballots as a Condorcet election, the candidate whose
+// strongest paths beat every other candidate's wins. Candidates that no path
+// separates are ordered by the lot order drawn from the tie-break seed. The
+// count is a single round, with the pairwise tables in ElectionResults.Schulze.
+func Schulze(candidates []*Candidate, ballots []*Ballot, numberOfSeats uint64, options SingleTransferableVoteOptions) (*ElectionResults, error) {
+	if numberOfSeats != 1 {
+		return nil, fmt.Errorf("schulze elects a single winner, not %d", numberOfSeats)
+	}
+	manager, err := NewElectionManager(candidates, ballots, ElectionManagerOptions{
+		NumberOfVotesPerVoter: 1,
+		CompareMethodIfEqual:  CompareMethodRandom,
+		PickRandomIfBlank:     options.PickRandomIfBlank,
+		Precision:             Precision{Arithmetic: ArithmeticExact},
+		TieBreakSeed:          options.TieBreakSeed,
+		Withdrawn:             options.Withdrawn,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if isWeighted(ballots) {
+		return nil, fmt.Errorf("schulze cannot count weighted ballots")
+	}
+	// the first preference tie-breaks only order the table of first preferences
+	manager.roundTieBreaks = make([]*TieBreak, 0)
+	electionResults := NewElectionResults(manager.Precision, manager)
+
+	// withdrawn candidates are reported by the manager but left out of the matrix
+	candidates, ballots = withoutWithdrawn(candidates, ballots, options.Withdrawn)
+	matrix := NewSchulzeMatrix(candidates, ballots)
+	electionResults.Schulze = matrix
+
+	ranking := make([]int, len(candidates))
+	for i := range ranking {
+		ranking[i] = i
+	}
+	slices.SortStableFunc(ranking, func(i, j int) int {
+		if cmp := matrix.wins(j) - matrix.wins(i); cmp != 0 {
+			return cmp
+		}
+		return manager.lotRank[candidates[i]] - manager.lotRank[candidates[j]]
+	})
+
+	for start := 0; start < len(ranking); {
+		end := start + 1
+		for end < len(ranking) && !matrix.Beats(ranking[start], ranking[end]) && !matrix.Beats(ranking[end], ranking[start]) &&
+			matrix.wins(ranking[start]) == matrix.wins(ranking[end]) {
+			end++
+		}
+		if end-start > 1 {
+			tied := make([]*Candidate, 0, end-start)
+			for _, i := range ranking[start:end] {
+				tied = append(tied, candidates[i])
+			}
+			manager.recordTieBreak(&TieBreak{
+				Candidates: tied,
+				Method:     CompareMethodRandom,
+				Reason:     TieBreakReasonEqualVotes,
+			})
+		}
+		start = end
+	}
+
+	for place, i := range ranking {
+		candidate := candidates[i]
+		if uint64(place) < numberOfSeats {
+			manager.recordEvent(RoundEventElectedSchulze, candidate)
+			if err := manager.ElectCandidate(candidate); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := manager.RejectCandidate(candidate); err != nil {
+			return nil, err
+		}
+	}
+
+	electionResults.RegisterResults(manager.GetResults())
+	return electionResults, nil
+}
+
+func newSquare(n int) [][]int {
+	square := make([][]int, n)
+	for i := range square {
+		square[i] = make([]int, n)
+	}
+	return square
+}
@@ -0,0 +1,110 @@
+package voting
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schulzeBallots makes the ballots from counts of each ranking, given as
+// candidate indexes
+func schulzeBallots(tb testing.TB, candidates []*Candidate, counts []int, rankings [][]int) []*Ballot {
+	tb.Helper()
+	var ballots []*Ballot
+	for i, count := range counts {
+		ballots = append(ballots, testBallots(tb, candidates, count, rankings[i]...)...)
+	}
+	return ballots
+}
+
+func TestSchulze(t *testing.T) {
+	tests := []struct {
+		name     string
+		counts   []int
+		rankings [][]int
+		winner   string
+		paths    [][]int
+	}{
+		{
+			// A beats B 6 to 3, B beats C 7 to 2 and C beats A 5 to 4, the cycle
+			// is broken at its weakest link C over A
+			name:     "cycle",
+			counts:   []int{4, 3, 2},
+			rankings: [][]int{{0, 1, 2}, {1, 2, 0}, {2, 0, 1}},
+			winner:   "A",
+			paths: [][]int{
+				{0, 6, 6},
+				{5, 0, 7},
+				{5, 5, 0},
+			},
+		},
+		{
+			// the 45 voter example from Schulze's paper, E wins with no Condorcet winner
+			name:   "five candidates",
+			counts: []int{5, 5, 8, 3, 7, 2, 7, 8},
+			rankings: [][]int{
+				{0, 2, 1, 4, 3},
+				{0, 3, 4, 2, 1},
+				{1, 4, 3, 0, 2},
+				{2, 0, 1, 4, 3},
+				{2, 0, 4, 1, 3},
+				{2, 1, 0, 3, 4},
+				{3, 2, 4, 1, 0},
+				{4, 1, 0, 3, 2},
+			},
+			winner: "E",
+			paths: [][]int{
+				{0, 28, 28, 30, 24},
+				{25, 0, 28, 33, 24},
+				{25, 29, 0, 29, 24},
+				{25, 28, 28, 0, 24},
+				{25, 28, 28, 31, 0},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidates := testCandidates("A", "B", "C", "D", "E")[:len(test.paths)]
+			ballots := schulzeBallots(t, candidates, test.counts, test.rankings)
+
+			results, err := Schulze(candidates, ballots, 1, DefaultSingleTransferableVoteOptions())
+			require.NoError(t, err)
+			AssertVoteWinners(t, results, []string{test.winner})
+			assert.Equal(t, test.paths, results.Schulze.StrongestPaths)
+			assert.Empty(t, results.Rounds[0].TieBreaks)
+		})
+	}
+}
+
+func TestSchulzeTiedCycle(t *testing.T) {
+	// every path is as strong as its reverse, so the lot order decides
+	candidates := testCandidates("A", "B", "C")
+	ballots := schulzeBallots(t, candidates, []int{1, 1, 1}, [][]int{{0, 1, 2}, {1, 2, 0}, {2, 0, 1}})
+	options := DefaultSingleTransferableVoteOptions()
+	options.TieBreakSeed = "cycle"
+
+	results, err := Schulze(candidates, ballots, 1, options)
+	require.NoError(t, err)
+	require.Len(t, results.GetWinners(), 1)
+	require.Len(t, results.Rounds[0].TieBreaks, 1)
+	assert.Len(t, results.Rounds[0].TieBreaks[0].Candidates, 3)
+	assert.Equal(t, results.LotOrder[0], results.GetWinners()[0])
+
+	again, err := Schulze(candidates, ballots, 1, options)
+	require.NoError(t, err)
+	assert.Equal(t, results.GetWinners(), again.GetWinners())
+}
+
+func TestSchulzeRefuses(t *testing.T) {
+	candidates := testCandidates("A", "B")
+	ballots := testBallots(t, candidates, 2, 0, 1)
+
+	_, err := Schulze(candidates, ballots, 2, DefaultSingleTransferableVoteOptions())
+	assert.Error(t, err, "more than one seat")
+
+	ballots[0].Weight = big.NewRat(2, 1)
+	_, err = Schulze(candidates, ballots, 1, DefaultSingleTransferableVoteOptions())
+	assert.Error(t, err, "weighted ballots")
+}
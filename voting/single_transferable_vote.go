@@ -2,47 +2,145 @@ package voting
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
 )
 
+type CountMethod string
+
+const (
+	CountMethodSingleTransferableVote = "SingleTransferableVote"
+	CountMethodMeek                   = "Meek"
+	CountMethodSchulze                = "Schulze"
+	CountMethodApproval               = "Approval"
+	CountMethodPlurality              = "Plurality"
+	// CountMethodMotion is counted by CountMotion rather than Count as a motion
+	// has no winners
+	CountMethodMotion = "Motion"
+)
+
+// Count runs the count with the given method, an empty method falls back to
+// SingleTransferableVote so elections stored before methods existed still close
+func Count(method CountMethod, candidates []*Candidate, ballots []*Ballot, numberOfSeats uint64, options SingleTransferableVoteOptions) (*ElectionResults, error) {
+	if len(options.Categories) > 0 && method != "" && method != CountMethodSingleTransferableVote {
+		return nil, fmt.Errorf("categories can only be counted by %s, not %s", CountMethodSingleTransferableVote, method)
+	}
+	if options.BatchElimination && method != "" && method != CountMethodSingleTransferableVote {
+		return nil, fmt.Errorf("batch elimination can only be used by %s, not %s", CountMethodSingleTransferableVote, method)
+	}
+	switch method {
+	case "", CountMethodSingleTransferableVote:
+		return SingleTransferableVote(candidates, ballots, numberOfSeats, options)
+	case CountMethodMeek:
+		return MeekSingleTransferableVote(candidates, ballots, numberOfSeats, options)
+	case CountMethodSchulze:
+		return Schulze(candidates, ballots, numberOfSeats, options)
+	case CountMethodApproval:
+		return Approval(candidates, ballots, numberOfSeats, options)
+	case CountMethodPlurality:
+		return Plurality(candidates, ballots, numberOfSeats, options)
+	default:
+		return nil, fmt.Errorf("unknown count method: %s", method)
+	}
+}
+
 type SingleTransferableVoteOptions struct {
 	CompareMethodIfEquals CompareMethod
 	PickRandomIfBlank     bool
+	Precision             Precision
+	// Quota is the number of votes needed to be elected, an empty quota is
+	// QuotaExactDroop
+	Quota Quota
+	// SurplusRule decides which ballots a surplus moves and at what value, an
+	// empty rule is SurplusRuleInclusiveGregory. Meek has no discrete transfers
+	// so ignores it.
+	SurplusRule SurplusRule
+	// TieBreakSeed is the seed committed to by the returning officer before the
+	// count, the same seed and ballots always produce the same result
+	TieBreakSeed string
+	// Withdrawn candidates stay on the ballots but are excluded before the
+	// first round, each ballot moving on to its next preference
+	Withdrawn []*Candidate
+	// Categories set the fewest and most seats groups of candidates can fill,
+	// only SingleTransferableVote counts them
+	Categories []*Category
+	// BatchElimination excludes every hopeful who can't catch the candidate
+	// above them together rather than one at a time, only
+	// SingleTransferableVote uses it and it can't be used with categories
+	BatchElimination bool
 }
 
 func DefaultSingleTransferableVoteOptions() SingleTransferableVoteOptions {
 	return SingleTransferableVoteOptions{
 		CompareMethodIfEquals: CompareMethodMostSecondChoice,
 		PickRandomIfBlank:     false,
+		Precision:             Precision{Arithmetic: ArithmeticExact},
+		Quota:                 QuotaExactDroop,
+		SurplusRule:           SurplusRuleInclusiveGregory,
 	}
 }
 
 func SingleTransferableVote(candidates []*Candidate, ballots []*Ballot, numberOfSeats uint64, options SingleTransferableVoteOptions) (*ElectionResults, error) {
-	roundingError := 1e-6
-	manager := NewElectionManager(candidates, ballots, ElectionManagerOptions{
+	electionResults, _, err := singleTransferableVote(candidates, ballots, numberOfSeats, options)
+	return electionResults, err
+}
+
+// singleTransferableVote runs the count and also returns the manager it was
+// counted with, so the ballots each candidate ended up with can be looked at
+func singleTransferableVote(candidates []*Candidate, ballots []*Ballot, numberOfSeats uint64, options SingleTransferableVoteOptions) (*ElectionResults, *ElectionManager, error) {
+	if err := options.Precision.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := options.Quota.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := options.SurplusRule.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := ValidateCategories(options.Categories, candidates, options.Withdrawn, numberOfSeats); err != nil {
+		return nil, nil, err
+	}
+	if options.BatchElimination && len(options.Categories) > 0 {
+		return nil, nil, fmt.Errorf("batch elimination cannot be used with categories")
+	}
+	manager, err := NewElectionManager(candidates, ballots, ElectionManagerOptions{
 		NumberOfVotesPerVoter: 1,
 		CompareMethodIfEqual:  options.CompareMethodIfEquals,
 		PickRandomIfBlank:     options.PickRandomIfBlank,
+		Precision:             options.Precision,
+		SurplusRule:           options.SurplusRule,
+		TieBreakSeed:          options.TieBreakSeed,
+		Withdrawn:             options.Withdrawn,
 	})
-	electionResults := NewElectionResults()
+	if err != nil {
+		return nil, nil, err
+	}
+	// categories can each be met on their own but not together when they overlap
+	if err = manager.checkCategories(options.Categories, numberOfSeats); err != nil {
+		return nil, nil, err
+	}
+	electionResults := NewElectionResults(options.Precision, manager)
 
 	voters := manager.GetNumberOfNonExhaustedBallots()
-	seats := numberOfSeats
-	votesNeededToWin := voters / float64(seats+1) // Droop Quota
+	votesNeededToWin := options.Quota.Value(voters, numberOfSeats, options.Precision)
+	electionResults.Quota = options.Quota
+	electionResults.QuotaValue = votesNeededToWin
+	electionResults.SurplusRule = options.SurplusRule
+	electionResults.BatchElimination = options.BatchElimination
 
 	for {
 		seatsLeft := numberOfSeats - manager.GetNumberOfElectedCandidates()
 		candidatesInRace := manager.GetCandidatesInRace()
-		candidatesInRaceVotes := make([]float64, 0)
+		candidatesInRaceVotes := make([]*big.Rat, 0)
 		for _, c := range candidatesInRace {
 			votes, err := manager.GetNumberOfVotes(c)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			candidatesInRaceVotes = append(candidatesInRaceVotes, votes)
 		}
 		votesRemaining := sumSlice(candidatesInRaceVotes)
-		lastVotes := 0.0
+		lastVotes := new(big.Rat)
 		candidatesToElect := make([]*Candidate, 0)
 		candidatesToReject := make([]*Candidate, 0)
 
@@ -50,49 +148,101 @@ func SingleTransferableVote(candidates []*Candidate, ballots []*Ballot, numberOf
 		for i, candidate := range candidatesInRace {
 			j, err := strconv.ParseUint(strconv.Itoa(i), 10, 64)
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse uint candidate in race: %w", err)
+				return nil, nil, fmt.Errorf("failed to parse uint candidate in race: %w", err)
 			}
 			votesForCandidate := candidatesInRaceVotes[j]
 			candidateCount, err := strconv.ParseUint(strconv.Itoa(len(candidatesInRace)-1), 10, 64)
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse uint last candidate in race: %w", err)
+				return nil, nil, fmt.Errorf("failed to parse uint last candidate in race: %w", err)
 			}
 			isLastCandidate := j == candidateCount
 
 			switch {
-			case votesForCandidate-roundingError >= votesNeededToWin:
+			// a quota that is reached rather than exceeded can be met by one more
+			// candidate than there are seats
+			case uint64(len(candidatesToElect)) < seatsLeft && options.Quota.IsReached(votesForCandidate, votesNeededToWin):
 				candidatesToElect = append(candidatesToElect, candidate)
-			case j >= seatsLeft && (votesRemaining-roundingError) <= lastVotes:
+			// every candidate below one being excluded in a batch is excluded with them
+			case len(candidatesToReject) > 0:
+				candidatesToReject = append(candidatesToReject, candidate)
+			case j >= seatsLeft && (isLastCandidate || options.BatchElimination && manager.isHopeless(candidatesInRace[j:], votesRemaining, lastVotes, votesNeededToWin, options.Quota)):
 				if len(candidatesToElect) > 0 {
 					break candidatesInRaceLoop
 				}
 				candidatesToReject = append(candidatesToReject, candidate)
 			case isLastCandidate:
-				return nil, fmt.Errorf("election ended up in an illegal state")
+				return nil, nil, fmt.Errorf("election ended up in an illegal state")
 			}
 
 			lastVotes = votesForCandidate
-			votesRemaining -= votesForCandidate
+			votesRemaining.Sub(votesRemaining, votesForCandidate)
 		}
 
+		// a candidate the categories stop from taking a seat is excluded below
+		// even if they reached the quota
+		elected := make([]*Candidate, 0, len(candidatesToElect))
 		for _, candidate := range candidatesToElect {
+			if _, _, ok := manager.canElect(candidate, options.Categories, numberOfSeats-manager.GetNumberOfElectedCandidates()); !ok {
+				continue
+			}
+			manager.recordEvent(RoundEventElectedQuota, candidate)
 			if err := manager.ElectCandidate(candidate); err != nil {
-				return nil, err
+				return nil, nil, err
 			}
+			elected = append(elected, candidate)
 		}
+		candidatesToElect = elected
 
+		if len(options.Categories) > 0 {
+			var err error
+			candidatesToReject, err = manager.guardExclusions(candidatesToReject, options.Categories, numberOfSeats-manager.GetNumberOfElectedCandidates())
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if len(candidatesToReject) > 0 {
+			manager.recordExclusionTie(candidatesToReject)
+		}
+		rejectEvent := RoundEventKind(RoundEventExcludedLowest)
+		if len(candidatesToReject) > 1 {
+			rejectEvent = RoundEventExcludedBatch
+		}
 		for i := len(candidatesToReject) - 1; i >= 0; i-- {
+			manager.recordEvent(rejectEvent, candidatesToReject[i])
 			if err := manager.RejectCandidate(candidatesToReject[i]); err != nil {
-				return nil, err
+				return nil, nil, err
+			}
+		}
+
+		if len(options.Categories) > 0 {
+			excluded, kinds, categories := manager.categoryExclusions(options.Categories, numberOfSeats-manager.GetNumberOfElectedCandidates())
+			for i, candidate := range excluded {
+				candidatesToReject = append(candidatesToReject, candidate)
+				manager.recordCategoryEvent(kinds[i], candidate, categories[i])
+				if err := manager.RejectCandidate(candidate); err != nil {
+					return nil, nil, err
+				}
+			}
+			if err := manager.checkCategories(options.Categories, numberOfSeats-manager.GetNumberOfElectedCandidates()); err != nil {
+				return nil, nil, err
 			}
 		}
 
 		seatsLeft = numberOfSeats - manager.GetNumberOfElectedCandidates()
 		if manager.GetNumberOfCandidatesInRace() <= seatsLeft {
 			for _, candidate := range manager.GetCandidatesInRace() {
+				if kind, category, ok := manager.canElect(candidate, options.Categories, numberOfSeats-manager.GetNumberOfElectedCandidates()); !ok {
+					candidatesToReject = append(candidatesToReject, candidate)
+					manager.recordCategoryEvent(kind, candidate, category)
+					if err := manager.RejectCandidate(candidate); err != nil {
+						return nil, nil, err
+					}
+					continue
+				}
 				candidatesToElect = append(candidatesToElect, candidate)
+				manager.recordEvent(RoundEventElectedRemainingSeats, candidate)
 				if err := manager.ElectCandidate(candidate); err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 			}
 		}
@@ -102,8 +252,9 @@ func SingleTransferableVote(candidates []*Candidate, ballots []*Ballot, numberOf
 			candidatesInRace = manager.GetCandidatesInRace()
 			for i := len(candidatesInRace) - 1; i >= 0; i-- {
 				candidatesToReject = append(candidatesToReject, candidatesInRace[i])
+				manager.recordEvent(RoundEventExcludedSeatsFilled, candidatesInRace[i])
 				if err := manager.RejectCandidate(candidatesInRace[i]); err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 			}
 		}
@@ -116,31 +267,93 @@ func SingleTransferableVote(candidates []*Candidate, ballots []*Ballot, numberOf
 		for _, c := range candidatesToElect {
 			votesForCandidate, err := manager.GetNumberOfVotes(c)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
-			excessVotes := votesForCandidate - votesNeededToWin
+			excessVotes := new(big.Rat).Sub(votesForCandidate, votesNeededToWin)
 			if err = manager.TransferVotes(c, excessVotes); err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 
 		for _, c := range candidatesToReject {
 			votesForCandidate, err := manager.GetNumberOfVotes(c)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if err = manager.TransferVotes(c, votesForCandidate); err != nil {
-				return nil, err
+				return nil, nil, err
+			}
+		}
+	}
+	return electionResults, manager, nil
+}
+
+// isHopeless reports whether the tail, the candidates with the fewest votes
+// holding votesRemaining between them, can be excluded together. Excluding them
+// one at a time only passes their votes between each other and up to the rest,
+// so while they hold fewer votes than the candidate above them and no one above
+// can reach the quota before the last of them goes, the winners are the same
+// either way.
+func (em *ElectionManager) isHopeless(tail []*Candidate, votesRemaining, votesAbove, votesNeededToWin *big.Rat, quota Quota) bool {
+	if votesRemaining.Cmp(votesAbove) >= 0 {
+		return false
+	}
+	// a candidate with more votes than the rest of the tail together is
+	// excluded last, their ballots only move on with the batch
+	early := tail
+	top := em.CandidateVoteCounts[tail[0]].NumberOfVotes
+	if top.Cmp(new(big.Rat).Sub(votesRemaining, top)) > 0 {
+		early = tail[1:]
+	}
+
+	inTail := make(map[*Candidate]bool, len(tail))
+	for _, candidate := range tail {
+		inTail[candidate] = true
+	}
+	inflow := make(map[*Candidate]*big.Rat)
+	// ballots that run out of preferences go to anyone when picked at random
+	anyone := new(big.Rat)
+	for _, candidate := range early {
+		cvc := em.CandidateVoteCounts[candidate]
+		for i, ballot := range cvc.Votes {
+			var next *Candidate
+			for _, c := range ballot.RankedCandidates {
+				if !inTail[c] && em.CandidateVoteCounts[c].IsInRace() {
+					next = c
+					break
+				}
+			}
+			switch {
+			case next != nil:
+				if inflow[next] == nil {
+					inflow[next] = new(big.Rat)
+				}
+				inflow[next].Add(inflow[next], cvc.Weights[i])
+			case em.PickRandomIfBlank:
+				anyone.Add(anyone, cvc.Weights[i])
 			}
 		}
 	}
-	return electionResults, nil
+
+	for _, candidate := range em.GetCandidatesInRace() {
+		if inTail[candidate] {
+			continue
+		}
+		votes := new(big.Rat).Add(em.CandidateVoteCounts[candidate].NumberOfVotes, anyone)
+		if inflow[candidate] != nil {
+			votes.Add(votes, inflow[candidate])
+		}
+		if quota.IsReached(votes, votesNeededToWin) {
+			return false
+		}
+	}
+	return true
 }
 
-func sumSlice(n []float64) float64 {
-	s := 0.0
+func sumSlice(n []*big.Rat) *big.Rat {
+	s := new(big.Rat)
 	for _, num := range n {
-		s += num
+		s.Add(s, num)
 	}
 	return s
 }
@@ -0,0 +1,119 @@
+package voting
+
+import (
+	"embed"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//go:embed testdata/*.blt
+var testdata embed.FS
+
+func AssertVoteWinners(tb testing.TB, results *ElectionResults, expected []string) {
+	tb.Helper()
+	winners := results.GetWinners()
+	assert.Equal(tb, len(expected), len(winners), "non-equal winner count")
+	for i, winner := range winners {
+		assert.Truef(tb, slices.Contains(expected, winner.Name), "winner %d (%q) not in expected list", i, winner.Name)
+	}
+	for i, expectedWinner := range expected {
+		assert.Truef(tb, slices.ContainsFunc(winners, func(c *Candidate) bool {
+			return c.Name == expectedWinner
+		}), "winner %d (%q) not in actual list", i, expectedWinner)
+	}
+}
+
+func TestSTVScotland2022(t *testing.T) {
+	blt := parseTestBLT(t, "testdata/Scotland2022_Ward_1_Penicuik.blt")
+
+	results, err := SingleTransferableVote(blt.Candidates, blt.Ballots, blt.NumberOfSeats, DefaultSingleTransferableVoteOptions())
+	require.NoError(t, err)
+	AssertVoteWinners(t, results, []string{
+		"Debbi MCCALL",
+		"Willie MCEWAN",
+		"Connor MCMANUS",
+	})
+}
+
+// roundEventKinds are the kinds of the events of each round and the candidates
+// they name
+func roundEventKinds(results *ElectionResults) [][]string {
+	rounds := make([][]string, 0, len(results.Rounds))
+	for _, round := range results.Rounds {
+		events := make([]string, 0, len(round.Events))
+		for _, event := range round.Events {
+			events = append(events, string(event.Kind)+": "+strings.Join(candidateNames(event.Candidates), " > "))
+		}
+		rounds = append(rounds, events)
+	}
+	return rounds
+}
+
+func TestSTVRoundEvents(t *testing.T) {
+	// B and C tie on 2 votes, the 3 ballots ranking B second keep B in
+	secondPreferences := testCandidates("A", "B", "C")
+	secondPreferenceBallots := testBallots(t, secondPreferences, 3, 0, 1)
+	secondPreferenceBallots = append(secondPreferenceBallots, testBallots(t, secondPreferences, 2, 1, 0)...)
+	secondPreferenceBallots = append(secondPreferenceBallots, testBallots(t, secondPreferences, 2, 2)...)
+
+	countback, countbackBallots := countbackElection(t)
+	tests := []struct {
+		name       string
+		candidates []*Candidate
+		ballots    []*Ballot
+		seats      uint64
+		events     [][]string
+	}{
+		{
+			// A reaches the quota of 6, D is the lowest and once B is excluded C
+			// is the only hopeful left for the last seat
+			name:       "quota, lowest and remaining seats",
+			candidates: countback,
+			ballots:    countbackBallots,
+			seats:      2,
+			events: [][]string{
+				{"ElectedQuota: A"},
+				{"ExcludedLowest: D"},
+				{"ExcludedLowest: B", "ElectedRemainingSeats: C"},
+			},
+		},
+		{
+			name:       "tie resolved by second preferences",
+			candidates: secondPreferences,
+			ballots:    secondPreferenceBallots,
+			seats:      1,
+			events: [][]string{
+				{"TieResolvedBySecondPreferences: B > C", "ExcludedLowest: C"},
+				{"ExcludedLowest: B", "ElectedRemainingSeats: A"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := SingleTransferableVote(test.candidates, test.ballots, test.seats, DefaultSingleTransferableVoteOptions())
+			require.NoError(t, err)
+			assert.Equal(t, test.events, roundEventKinds(results))
+		})
+	}
+
+	t.Run("tie resolved by lot", func(t *testing.T) {
+		candidates, ballots := tiedElection(t)
+		options := DefaultSingleTransferableVoteOptions()
+		options.TieBreakSeed = "a public dice roll"
+		results, err := SingleTransferableVote(candidates, ballots, 1, options)
+		require.NoError(t, err)
+
+		// the tied candidates are named in lot order and the last drawn goes
+		lot := candidateNames(results.LotOrder)
+		lot = slices.DeleteFunc(lot, func(name string) bool { return name == "D" })
+		assert.Equal(t, [][]string{
+			{"ExcludedLowest: D"},
+			{"TieResolvedByLot: " + strings.Join(lot, " > "), "ExcludedLowest: " + lot[2]},
+			{"TieResolvedByLot: " + strings.Join(lot[:2], " > "), "ExcludedLowest: " + lot[1], "ElectedRemainingSeats: " + lot[0]},
+		}, roundEventKinds(results))
+	})
+}
@@ -0,0 +1,85 @@
+package voting
+
+import "fmt"
+
+type BallotType string
+
+const (
+	// BallotTypeRanked is a ballot ranking candidates in order of preference
+	BallotTypeRanked = "Ranked"
+	// BallotTypeApproval is a ballot ticking any number of candidates
+	BallotTypeApproval = "Approval"
+	// BallotTypePlurality is a ballot ticking a single candidate
+	BallotTypePlurality = "Plurality"
+	// BallotTypeMotion is a ballot choosing one of the MotionCandidates
+	BallotTypeMotion = "Motion"
+)
+
+// Approval counts approval ballots, where a ballot's RankedCandidates are every
+// candidate it ticked in no particular order. Each tick is a vote and the
+// candidates with the most votes fill the seats.
+func Approval(candidates []*Candidate, ballots []*Ballot, numberOfSeats uint64, options SingleTransferableVoteOptions) (*ElectionResults, error) {
+	return tally(candidates, ballots, numberOfSeats, options, len(candidates))
+}
+
+// Plurality counts first-past-the-post ballots, each ticking a single candidate,
+// the candidates with the most votes fill the seats
+func Plurality(candidates []*Candidate, ballots []*Ballot, numberOfSeats uint64, options SingleTransferableVoteOptions) (*ElectionResults, error) {
+	return tally(candidates, ballots, numberOfSeats, options, 1)
+}
+
+// tally gives every candidate on a ballot a vote and elects the candidates with
+// the most, ties are ordered by the lot order drawn from the tie-break seed
+func tally(candidates []*Candidate, ballots []*Ballot, numberOfSeats uint64, options SingleTransferableVoteOptions, maxChoices int) (*ElectionResults, error) {
+	if numberOfSeats > uint64(len(candidates)-len(options.Withdrawn)) {
+		return nil, fmt.Errorf("not enough candidates to fill %d seats", numberOfSeats)
+	}
+	if err := ValidateBallots(candidates, ballots); err != nil {
+		return nil, err
+	}
+	manager, err := NewElectionManager(candidates, []*Ballot{}, ElectionManagerOptions{
+		NumberOfVotesPerVoter: 1,
+		CompareMethodIfEqual:  CompareMethodRandom,
+		Precision:             Precision{Arithmetic: ArithmeticExact},
+		TieBreakSeed:          options.TieBreakSeed,
+		Withdrawn:             options.Withdrawn,
+	})
+	if err != nil {
+		return nil, err
+	}
+	manager.Ballots = ballots
+	electionResults := NewElectionResults(manager.Precision, manager)
+
+	for _, ballot := range ballots {
+		if len(ballot.RankedCandidates) > maxChoices {
+			return nil, fmt.Errorf("ballot has %d choices, at most %d allowed", len(ballot.RankedCandidates), maxChoices)
+		}
+		_, standing := withoutWithdrawn(nil, []*Ballot{ballot}, options.Withdrawn)
+		if len(standing[0].RankedCandidates) == 0 {
+			manager.ExhaustedBallots = append(manager.ExhaustedBallots, ballot)
+			manager.NumberOfBlankVotes.Add(manager.NumberOfBlankVotes, ballot.value())
+			continue
+		}
+		for _, candidate := range standing[0].RankedCandidates {
+			manager.CandidateVoteCounts[candidate].addVote(ballot, ballot.value(), 0)
+		}
+	}
+	manager.roundTieBreaks = make([]*TieBreak, 0)
+	manager.SortCandidatesInRace()
+
+	for place, candidate := range manager.GetCandidatesInRace() {
+		if uint64(place) < numberOfSeats {
+			manager.recordEvent(RoundEventElectedMostVotes, candidate)
+			if err := manager.ElectCandidate(candidate); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := manager.RejectCandidate(candidate); err != nil {
+			return nil, err
+		}
+	}
+
+	electionResults.RegisterResults(manager.GetResults())
+	return electionResults, nil
+}
@@ -0,0 +1,94 @@
+package voting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// candidateVotes are the votes of each candidate in the round
+func candidateVotes(round *RoundResult) map[string]string {
+	votes := make(map[string]string, len(round.CandidateResults))
+	for _, result := range round.CandidateResults {
+		votes[result.Candidate.Name] = result.NumberOfVotes.RatString()
+	}
+	return votes
+}
+
+func TestApproval(t *testing.T) {
+	candidates := testCandidates("A", "B", "C", "D")
+	ballots := testBallots(t, candidates, 3, 0, 1)
+	ballots = append(ballots, testBallots(t, candidates, 2, 1, 2)...)
+	ballots = append(ballots, testBallots(t, candidates, 1, 1, 3)...)
+	ballots = append(ballots, testBallots(t, candidates, 1, 3)...)
+	ballots = append(ballots, testBallots(t, candidates, 1)...)
+
+	results, err := Approval(candidates, ballots, 2, DefaultSingleTransferableVoteOptions())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"B", "A"}, winnerNames(results))
+	require.Len(t, results.Rounds, 1)
+	round := results.Rounds[0]
+	// every tick is a vote, the blank ballot is counted as blank
+	assert.Equal(t, map[string]string{"A": "3", "B": "6", "C": "2", "D": "2"}, candidateVotes(round))
+	assert.Equal(t, "1", round.NumberOfBlankVotes.RatString())
+	for _, event := range round.Events {
+		if event.Kind == RoundEventElectedMostVotes {
+			assert.Contains(t, []string{"A", "B"}, event.Candidates[0].Name)
+		}
+	}
+}
+
+func TestPlurality(t *testing.T) {
+	candidates := testCandidates("A", "B", "C")
+	ballots := testBallots(t, candidates, 4, 1)
+	ballots = append(ballots, testBallots(t, candidates, 2, 0)...)
+	ballots = append(ballots, testBallots(t, candidates, 1, 2)...)
+
+	results, err := Plurality(candidates, ballots, 1, DefaultSingleTransferableVoteOptions())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"B"}, winnerNames(results))
+	assert.Equal(t, map[string]string{"A": "2", "B": "4", "C": "1"}, candidateVotes(results.Rounds[0]))
+
+	// a plurality ballot ticks a single candidate
+	ballots = append(ballots, testBallots(t, candidates, 1, 0, 1)...)
+	_, err = Plurality(candidates, ballots, 1, DefaultSingleTransferableVoteOptions())
+	assert.Error(t, err)
+}
+
+func TestTallyTieDecidedByLot(t *testing.T) {
+	candidates := testCandidates("A", "B", "C")
+	ballots := testBallots(t, candidates, 3, 0, 2)
+	ballots = append(ballots, testBallots(t, candidates, 3, 1)...)
+
+	options := DefaultSingleTransferableVoteOptions()
+	options.TieBreakSeed = "a public dice roll"
+	results, err := Approval(candidates, ballots, 1, options)
+	require.NoError(t, err)
+
+	// A and B are tied on 3 votes, the seat goes to whichever was drawn first
+	lot := lotPlaces(results.LotOrder)
+	want := "A"
+	if lot[candidates[1]] < lot[candidates[0]] {
+		want = "B"
+	}
+	assert.Equal(t, []string{want}, winnerNames(results))
+
+	tieBreaks := results.Rounds[0].TieBreaks
+	require.NotEmpty(t, tieBreaks)
+	assert.True(t, tieBreaks[0].Method == CompareMethodRandom, "tie decided by %s, want lot", tieBreaks[0].Method)
+	assert.Equal(t, want, tieBreaks[0].Candidates[0].Name)
+
+	again, err := Approval(candidates, ballots, 1, options)
+	require.NoError(t, err)
+	assert.Equal(t, winnerNames(results), winnerNames(again))
+}
+
+// lotPlaces maps each candidate to their place in the lot order
+func lotPlaces(lotOrder []*Candidate) map[*Candidate]int {
+	places := make(map[*Candidate]int, len(lotOrder))
+	for i, candidate := range lotOrder {
+		places[candidate] = i
+	}
+	return places
+}
@@ -0,0 +1,137 @@
+package voting
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+type TieBreakReason string
+
+const (
+	// TieBreakReasonEqualVotes is a tie between hopeful candidates on equal votes
+	TieBreakReasonEqualVotes = "EqualVotes"
+	// TieBreakReasonBlankBallot is a random candidate picked for a blank ballot
+	TieBreakReasonBlankBallot = "BlankBallot"
+)
+
+// TieBreak records a single decision made by the tie-break rules so the count
+// can be replayed by scrutineers
+type TieBreak struct {
+	// Candidates are the tied candidates in the order decided, highest first,
+	// or the single candidate picked for a blank ballot
+	Candidates []*Candidate
+	Method     CompareMethod
+	Reason     TieBreakReason
+}
+
+func (tb *TieBreak) String() string {
+	names := make([]string, 0, len(tb.Candidates))
+	for _, c := range tb.Candidates {
+		names = append(names, c.Name)
+	}
+	return fmt.Sprintf("TieBreak(%s, %s: %s)", tb.Reason, tb.Method, strings.Join(names, " > "))
+}
+
+func (tb *TieBreak) equals(other *TieBreak) bool {
+	return tb.Method == other.Method && tb.Reason == other.Reason &&
+		slices.EqualFunc(tb.Candidates, other.Candidates, func(a, b *Candidate) bool { return a.Equals(b) })
+}
+
+// NewTieBreakSeed generates a seed for counts where the returning officer
+// didn't commit to one, it is recorded with the results so the count can
+// still be replayed
+func NewTieBreakSeed() string {
+	return cryptorand.Text()
+}
+
+// newTieBreakRand returns the generator every random decision in a count is
+// drawn from, the same seed always produces the same decisions
+func newTieBreakRand(seed string) *rand.Rand {
+	//nolint:gosec // a reproducible generator is the point, the seed is published
+	return rand.New(rand.NewChaCha8(sha256.Sum256([]byte(seed))))
+}
+
+// drawLotOrder shuffles the candidates once at the start of the count, a tie
+// decided by lot always goes to the candidate drawn earlier
+func drawLotOrder(candidates []*Candidate, rng *rand.Rand) []*Candidate {
+	lotOrder := slices.Clone(candidates)
+	rng.Shuffle(len(lotOrder), func(i, j int) {
+		lotOrder[i], lotOrder[j] = lotOrder[j], lotOrder[i]
+	})
+	return lotOrder
+}
+
+// recordTieBreak adds the decision to the current round unless the same
+// decision has already been recorded, ties are re-sorted after every transfer
+func (em *ElectionManager) recordTieBreak(tieBreak *TieBreak) {
+	for _, tb := range em.roundTieBreaks {
+		if tb.equals(tieBreak) {
+			return
+		}
+	}
+	em.roundTieBreaks = append(em.roundTieBreaks, tieBreak)
+}
+
+// breakTie orders candidates on equal votes, by later preferences when
+// configured and otherwise by the lot order drawn from the seed
+func (em *ElectionManager) breakTie(tied []*CandidateVoteCount) {
+	method := CompareMethod(CompareMethodRandom)
+	if em.CompareMethodIfEqual == CompareMethodMostSecondChoice {
+		slices.SortStableFunc(tied, func(c1vc, c2vc *CandidateVoteCount) int {
+			if cmp := em.compareLaterChoices(c1vc, c2vc, 1); cmp != 0 {
+				return -cmp
+			}
+			return em.compareLot(c1vc, c2vc)
+		})
+		method = CompareMethodMostSecondChoice
+		for i := 1; i < len(tied); i++ {
+			if em.compareLaterChoices(tied[i-1], tied[i], 1) == 0 {
+				method = CompareMethodRandom
+			}
+		}
+	} else {
+		slices.SortStableFunc(tied, em.compareLot)
+	}
+
+	candidates := make([]*Candidate, 0, len(tied))
+	for _, cvc := range tied {
+		candidates = append(candidates, cvc.Candidate)
+	}
+	em.recordTieBreak(&TieBreak{
+		Candidates: candidates,
+		Method:     method,
+		Reason:     TieBreakReasonEqualVotes,
+	})
+}
+
+func (em *ElectionManager) compareLot(c1vc, c2vc *CandidateVoteCount) int {
+	return em.lotRank[c1vc.Candidate] - em.lotRank[c2vc.Candidate]
+}
+
+// compareLaterChoices compares how often each candidate is the xth choice
+// among those in the race, moving on to later choices while they are equal
+func (em *ElectionManager) compareLaterChoices(c1vc, c2vc *CandidateVoteCount, x int) int {
+	for ; x < em.NumberOfCandidates; x++ {
+		votesCandidate1 := new(big.Rat)
+		votesCandidate2 := new(big.Rat)
+
+		for _, ballot := range em.Ballots {
+			switch em.GetBallotCandidateNrXInRaceOrNone(ballot, x) {
+			case c1vc.Candidate:
+				votesCandidate1.Add(votesCandidate1, ballot.value())
+			case c2vc.Candidate:
+				votesCandidate2.Add(votesCandidate2, ballot.value())
+			}
+		}
+
+		if cmp := votesCandidate1.Cmp(votesCandidate2); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
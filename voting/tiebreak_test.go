@@ -0,0 +1,89 @@
+package voting
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tiedElection has A, B and C on 2 votes each and D on 1 with no later
+// preferences, so once D is excluded every exclusion is a tie decided by lot
+func tiedElection(tb testing.TB) ([]*Candidate, []*Ballot) {
+	tb.Helper()
+	candidates := testCandidates("A", "B", "C", "D")
+	ballots := testBallots(tb, candidates, 2, 0)
+	ballots = append(ballots, testBallots(tb, candidates, 2, 1)...)
+	ballots = append(ballots, testBallots(tb, candidates, 2, 2)...)
+	ballots = append(ballots, testBallots(tb, candidates, 1, 3)...)
+	return candidates, ballots
+}
+
+// roundTieBreaks are the tie-breaks of each round written out
+func roundTieBreaks(results *ElectionResults) [][]string {
+	rounds := make([][]string, 0, len(results.Rounds))
+	for _, round := range results.Rounds {
+		tieBreaks := make([]string, 0, len(round.TieBreaks))
+		for _, tieBreak := range round.TieBreaks {
+			tieBreaks = append(tieBreaks, tieBreak.String())
+		}
+		rounds = append(rounds, tieBreaks)
+	}
+	return rounds
+}
+
+func TestTieBreakSeedReplays(t *testing.T) {
+	candidates, ballots := tiedElection(t)
+	options := DefaultSingleTransferableVoteOptions()
+	options.CompareMethodIfEquals = CompareMethodRandom
+
+	// a count without a seed draws one and records it
+	drawn, err := SingleTransferableVote(candidates, ballots, 1, options)
+	require.NoError(t, err)
+	require.NotEmpty(t, drawn.TieBreakSeed)
+	require.NotEmpty(t, slices.Concat(roundTieBreaks(drawn)...), "no tie-breaks to replay")
+
+	options.TieBreakSeed = drawn.TieBreakSeed
+	for range 2 {
+		replayed, err := SingleTransferableVote(candidates, ballots, 1, options)
+		require.NoError(t, err)
+		assert.Equal(t, drawn.TieBreakSeed, replayed.TieBreakSeed)
+		assert.Equal(t, candidateNames(drawn.LotOrder), candidateNames(replayed.LotOrder))
+		assert.Equal(t, winnerNames(drawn), winnerNames(replayed))
+		assert.Equal(t, roundTieBreaks(drawn), roundTieBreaks(replayed))
+	}
+}
+
+func TestTieDecidedByLot(t *testing.T) {
+	candidates, ballots := tiedElection(t)
+	options := DefaultSingleTransferableVoteOptions()
+	options.TieBreakSeed = "a public dice roll"
+
+	results, err := SingleTransferableVote(candidates, ballots, 1, options)
+	require.NoError(t, err)
+
+	// nobody has later preferences so the most second choices can't separate
+	// them, the tie goes by lot and the candidate drawn last is excluded
+	lotRank := make(map[*Candidate]int)
+	for i, candidate := range results.LotOrder {
+		lotRank[candidate] = i
+	}
+	var decided *TieBreak
+	for _, round := range results.Rounds {
+		for _, tieBreak := range round.TieBreaks {
+			if tieBreak.Reason == TieBreakReasonEqualVotes && len(tieBreak.Candidates) == 3 {
+				decided = tieBreak
+			}
+		}
+	}
+	require.NotNil(t, decided, "tie of A, B and C not recorded")
+	assert.True(t, decided.Method == CompareMethodRandom, "tie decided by %s, want lot", decided.Method)
+	for i := 1; i < len(decided.Candidates); i++ {
+		assert.Less(t, lotRank[decided.Candidates[i-1]], lotRank[decided.Candidates[i]], "tie not in lot order")
+	}
+
+	// the lot ranks the winner above the other two on 2 votes
+	require.Len(t, results.GetWinners(), 1)
+	assert.Equal(t, decided.Candidates[0], results.GetWinners()[0])
+}
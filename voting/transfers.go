@@ -0,0 +1,184 @@
+package voting
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+type SurplusRule string
+
+const (
+	// SurplusRuleInclusiveGregory moves a surplus across every ballot the
+	// candidate holds, each carrying an equal share of it
+	SurplusRuleInclusiveGregory = "InclusiveGregory"
+	// SurplusRuleLastParcel moves a surplus across only the parcel of ballots
+	// that took the candidate over the quota, each carrying an equal share of it
+	SurplusRuleLastParcel = "LastParcel"
+	// SurplusRuleWeightedInclusiveGregory moves a surplus across every ballot the
+	// candidate holds, each carrying the same fraction of the value it holds
+	SurplusRuleWeightedInclusiveGregory = "WeightedInclusiveGregory"
+)
+
+func (sr SurplusRule) Validate() error {
+	switch sr {
+	case "", SurplusRuleInclusiveGregory, SurplusRuleLastParcel, SurplusRuleWeightedInclusiveGregory:
+		return nil
+	default:
+		return fmt.Errorf("unknown surplus rule: %s", sr)
+	}
+}
+
+type TransferKind string
+
+const (
+	// TransferKindSurplus is the surplus of an elected candidate moving on
+	TransferKindSurplus = "Surplus"
+	// TransferKindExclusion is every vote of an excluded candidate moving on
+	TransferKindExclusion = "Exclusion"
+)
+
+// TransferAmount is the part of a transfer that went to one candidate
+type TransferAmount struct {
+	Candidate *Candidate
+	Ballots   int
+	Votes     *big.Rat
+}
+
+// Transfer is one line of a transfer sheet, where the votes of a single
+// candidate went when they were transferred
+type Transfer struct {
+	From *Candidate
+	Kind TransferKind
+	// Value is the value each ballot carried on to its next preference, or when
+	// Weighted the fraction of the value it held that each ballot carried on
+	Value            *big.Rat
+	Weighted         bool
+	To               []*TransferAmount
+	ExhaustedBallots int
+	ExhaustedVotes   *big.Rat
+	LossByFractions  *big.Rat
+}
+
+func (t *Transfer) String() string {
+	return fmt.Sprintf("Transfer(%s of %s, %d destinations, exhausted=%s)", t.Kind, t.From, len(t.To), t.ExhaustedVotes.FloatString(2))
+}
+
+func newTransfer(from *CandidateVoteCount, value *big.Rat) *Transfer {
+	kind := TransferKind(TransferKindExclusion)
+	if from.Status == Elected {
+		kind = TransferKindSurplus
+	}
+	return &Transfer{
+		From:            from.Candidate,
+		Kind:            kind,
+		Value:           copyRat(value),
+		To:              make([]*TransferAmount, 0),
+		ExhaustedVotes:  new(big.Rat),
+		LossByFractions: new(big.Rat),
+	}
+}
+
+// add records a ballot worth votes moving to the candidate, nil for exhausted
+func (t *Transfer) add(candidate *Candidate, votes *big.Rat) {
+	if candidate == nil {
+		t.ExhaustedBallots++
+		t.ExhaustedVotes.Add(t.ExhaustedVotes, votes)
+		return
+	}
+	for _, amount := range t.To {
+		if amount.Candidate == candidate {
+			amount.Ballots++
+			amount.Votes.Add(amount.Votes, votes)
+			return
+		}
+	}
+	t.To = append(t.To, &TransferAmount{
+		Candidate: candidate,
+		Ballots:   1,
+		Votes:     copyRat(votes),
+	})
+}
+
+// transferBallots picks the ballots a transfer moves and the value each carries
+// on, along with the transfer sheet line to record them in
+func (em *ElectionManager) transferBallots(cvc *CandidateVoteCount, numberOfTransferVotes *big.Rat) ([]*Ballot, []*big.Rat, *Transfer) {
+	rule := em.SurplusRule
+	if cvc.Status != Elected && rule != "" && rule != SurplusRuleInclusiveGregory {
+		// exclusions under the stricter rules move every ballot at the value it
+		// holds, so nothing is averaged between parcels
+		values := make([]*big.Rat, len(cvc.Votes))
+		for i, weight := range cvc.Weights {
+			values[i] = copyRat(weight)
+		}
+		transfer := newTransfer(cvc, newRat(1))
+		transfer.Weighted = true
+		return cvc.Votes, values, transfer
+	}
+
+	switch rule {
+	case SurplusRuleLastParcel:
+		ballots, weights := cvc.lastParcel(numberOfTransferVotes)
+		parcelVotes := sumSlice(weights)
+		values := make([]*big.Rat, len(ballots))
+		if slices.ContainsFunc(weights, func(w *big.Rat) bool { return w.Cmp(weights[0]) != 0 }) {
+			// a parcel from several transfers holds ballots at different values, each
+			// carries on the same fraction of its value
+			ratio := new(big.Rat).Quo(numberOfTransferVotes, parcelVotes)
+			for i, weight := range weights {
+				values[i] = em.Precision.Truncate(new(big.Rat).Mul(weight, ratio))
+			}
+			transfer := newTransfer(cvc, ratio)
+			transfer.Weighted = true
+			return ballots, values, transfer
+		}
+		value := new(big.Rat).Quo(numberOfTransferVotes, newRat(int64(len(ballots))))
+		em.Precision.Truncate(value)
+		for i := range values {
+			values[i] = value
+		}
+		return ballots, values, newTransfer(cvc, value)
+	case SurplusRuleWeightedInclusiveGregory:
+		ratio := new(big.Rat).Quo(numberOfTransferVotes, cvc.NumberOfVotes)
+		values := make([]*big.Rat, len(cvc.Votes))
+		for i, weight := range cvc.Weights {
+			values[i] = em.Precision.Truncate(new(big.Rat).Mul(weight, ratio))
+		}
+		transfer := newTransfer(cvc, ratio)
+		transfer.Weighted = true
+		return cvc.Votes, values, transfer
+	default:
+		// a weighted ballot carries on the value times its weight
+		value := new(big.Rat).Quo(numberOfTransferVotes, totalValue(cvc.Votes))
+		em.Precision.Truncate(value)
+		values := make([]*big.Rat, len(cvc.Votes))
+		for i, ballot := range cvc.Votes {
+			values[i] = value
+			if ballot.Weight != nil {
+				values[i] = em.Precision.Truncate(new(big.Rat).Mul(value, ballot.Weight))
+			}
+		}
+		return cvc.Votes, values, newTransfer(cvc, value)
+	}
+}
+
+// lastParcel returns the ballots and their values in the parcel that took the
+// candidate over the quota, the surplus is at most the value of this parcel
+func (cvc *CandidateVoteCount) lastParcel(surplus *big.Rat) ([]*Ballot, []*big.Rat) {
+	quota := new(big.Rat).Sub(cvc.NumberOfVotes, surplus)
+	total := new(big.Rat)
+	for p, start := range cvc.Parcels {
+		end := len(cvc.Votes)
+		if p+1 < len(cvc.Parcels) {
+			end = cvc.Parcels[p+1]
+		}
+		for _, weight := range cvc.Weights[start:end] {
+			total.Add(total, weight)
+		}
+		if total.Cmp(quota) > 0 {
+			return cvc.Votes[start:end], cvc.Weights[start:end]
+		}
+	}
+	start := cvc.Parcels[len(cvc.Parcels)-1]
+	return cvc.Votes[start:], cvc.Weights[start:]
+}
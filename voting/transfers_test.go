@@ -0,0 +1,123 @@
+package voting
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transferRows writes out a transfer sheet line as the rows of where its
+// votes went, the candidates with the ballots and votes each received then the
+// exhausted ballots
+func transferRows(transfer *Transfer) []string {
+	rows := make([]string, 0, len(transfer.To)+1)
+	for _, to := range transfer.To {
+		rows = append(rows, fmt.Sprintf("%d to %s: %s", to.Ballots, to.Candidate.Name, to.Votes.RatString()))
+	}
+	return append(rows, fmt.Sprintf("%d exhausted: %s", transfer.ExhaustedBallots, transfer.ExhaustedVotes.RatString()))
+}
+
+// transferTotal is every vote the transfer moved on, exhausted or lost
+func transferTotal(transfer *Transfer) *big.Rat {
+	total := new(big.Rat).Add(transfer.ExhaustedVotes, transfer.LossByFractions)
+	for _, to := range transfer.To {
+		total.Add(total, to.Votes)
+	}
+	return total
+}
+
+func TestSTVSurplusRules(t *testing.T) {
+	// D is excluded first and A is elected on D's 3 ballots, taking A to 10 over
+	// the quota of 7. Every one of A's ballots carries the surplus of 3 on at
+	// 3/10 and elects C, only the last parcel of D's ballots carries it on at 1
+	// and elects B. One of D's ballots has no preference after A and exhausts.
+	tests := []struct {
+		rule    SurplusRule
+		winners []string
+		votesB  string
+		value   string
+		surplus []string
+	}{
+		{
+			rule:    SurplusRuleInclusiveGregory,
+			winners: []string{"A", "C"},
+			votesB:  "33/5",
+			value:   "3/10",
+			surplus: []string{"7 to C: 21/10", "2 to B: 3/5", "1 exhausted: 3/10"},
+		},
+		{
+			rule:    SurplusRuleWeightedInclusiveGregory,
+			winners: []string{"A", "C"},
+			votesB:  "33/5",
+			value:   "3/10",
+			surplus: []string{"7 to C: 21/10", "2 to B: 3/5", "1 exhausted: 3/10"},
+		},
+		{
+			rule:    SurplusRuleLastParcel,
+			winners: []string{"A", "B"},
+			votesB:  "8",
+			value:   "1",
+			surplus: []string{"2 to B: 2", "1 exhausted: 1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(string(test.rule), func(t *testing.T) {
+			candidates := testCandidates("A", "B", "C", "D")
+			ballots := testBallots(t, candidates, 7, 0, 2)
+			ballots = append(ballots, testBallots(t, candidates, 6, 1)...)
+			ballots = append(ballots, testBallots(t, candidates, 5, 2)...)
+			ballots = append(ballots, testBallots(t, candidates, 2, 3, 0, 1)...)
+			ballots = append(ballots, testBallots(t, candidates, 1, 3, 0)...)
+
+			options := DefaultSingleTransferableVoteOptions()
+			options.SurplusRule = test.rule
+			results, err := SingleTransferableVote(candidates, ballots, 2, options)
+			require.NoError(t, err)
+			AssertVoteWinners(t, results, test.winners)
+			assert.Equal(t, test.rule, results.SurplusRule)
+
+			require.Len(t, results.Rounds, 3)
+			for _, result := range results.Rounds[2].CandidateResults {
+				if result.Candidate == candidates[1] {
+					assert.Equal(t, test.votesB, results.Precision.FormatVotes(result.NumberOfVotes))
+				}
+			}
+
+			// D's 3 votes all go to A, then A's surplus of 3 moves on
+			require.Len(t, results.Rounds[1].Transfers, 1)
+			exclusion := results.Rounds[1].Transfers[0]
+			assert.True(t, exclusion.Kind == TransferKindExclusion && exclusion.From == candidates[3], "%s transferred in round 2, want D excluded", exclusion)
+			assert.Equal(t, []string{"3 to A: 3", "0 exhausted: 0"}, transferRows(exclusion))
+			assert.Equal(t, "3", transferTotal(exclusion).RatString())
+
+			require.Len(t, results.Rounds[2].Transfers, 1)
+			surplus := results.Rounds[2].Transfers[0]
+			assert.True(t, surplus.Kind == TransferKindSurplus && surplus.From == candidates[0], "%s transferred in round 3, want A's surplus", surplus)
+			assert.Equal(t, test.value, surplus.Value.RatString())
+			assert.Equal(t, test.surplus, transferRows(surplus))
+			assert.Equal(t, "3", transferTotal(surplus).RatString())
+		})
+	}
+}
+
+func TestLastParcelOfSeveralTransfers(t *testing.T) {
+	// the first preferences took the candidate to 4 and a parcel of 2 ballots at
+	// 1/2 took them to 5, a surplus of 1/2 comes from that parcel alone
+	candidate := NewCandidate("A")
+	cvc := NewCandidateVoteCount(candidate)
+	first := testBallots(t, []*Candidate{candidate}, 4, 0)
+	for _, ballot := range first {
+		cvc.addVote(ballot, newRat(1), 0)
+	}
+	second := testBallots(t, []*Candidate{candidate}, 2, 0)
+	for _, ballot := range second {
+		cvc.addVote(ballot, new(big.Rat).SetFrac64(1, 2), 1)
+	}
+
+	ballots, values := cvc.lastParcel(new(big.Rat).SetFrac64(1, 2))
+	assert.Equal(t, second, ballots)
+	assert.Len(t, values, 2)
+}
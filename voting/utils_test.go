@@ -0,0 +1,58 @@
+package voting
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// parseTestBLT reads a BLT file from testdata
+func parseTestBLT(tb testing.TB, file string) *BLT {
+	tb.Helper()
+
+	fd, err := testdata.Open(file)
+	require.NoError(tb, err)
+	defer func(fd fs.File) {
+		_ = fd.Close()
+	}(fd)
+
+	blt, err := ParseBLT(fd)
+	require.NoError(tb, err)
+	return blt
+}
+
+// testCandidates makes a candidate for each name
+func testCandidates(names ...string) []*Candidate {
+	candidates := make([]*Candidate, 0, len(names))
+	for _, name := range names {
+		candidates = append(candidates, NewCandidate(name))
+	}
+	return candidates
+}
+
+// testBallots makes count copies of a ballot ranking the candidates at the
+// indexes given
+func testBallots(tb testing.TB, candidates []*Candidate, count int, ranking ...int) []*Ballot {
+	tb.Helper()
+	ranked := make([]*Candidate, 0, len(ranking))
+	for _, i := range ranking {
+		ranked = append(ranked, candidates[i])
+	}
+	ballots := make([]*Ballot, 0, count)
+	for range count {
+		ballot, err := NewBallot(ranked)
+		require.NoError(tb, err)
+		ballots = append(ballots, ballot)
+	}
+	return ballots
+}
+
+// winnerNames are the names of the winners in the order they were elected
+func winnerNames(results *ElectionResults) []string {
+	names := make([]string, 0)
+	for _, winner := range results.GetWinners() {
+		names = append(names, winner.Name)
+	}
+	return names
+}
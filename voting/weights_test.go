@@ -0,0 +1,74 @@
+package voting
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// weightedElection has a single ballot of weight 4 for A then B, unweighted it
+// would elect C
+func weightedElection(tb testing.TB) ([]*Candidate, []*Ballot) {
+	tb.Helper()
+	candidates := testCandidates("A", "B", "C")
+	ballots := testBallots(tb, candidates, 1, 0, 1)
+	ballots[0].Weight = big.NewRat(4, 1)
+	ballots = append(ballots, testBallots(tb, candidates, 1, 1)...)
+	ballots = append(ballots, testBallots(tb, candidates, 2, 2)...)
+	return candidates, ballots
+}
+
+func TestSTVWeightedBallots(t *testing.T) {
+	// A's surplus of 4 less the quota of 7/3 carries the weighted ballot on to B
+	// at 5/3, taking B to 8/3
+	tests := []struct {
+		rule   SurplusRule
+		votesB string
+	}{
+		{rule: SurplusRuleInclusiveGregory, votesB: "8/3"},
+		{rule: SurplusRuleWeightedInclusiveGregory, votesB: "8/3"},
+		{rule: SurplusRuleLastParcel, votesB: "8/3"},
+	}
+	for _, test := range tests {
+		t.Run(string(test.rule), func(t *testing.T) {
+			candidates, ballots := weightedElection(t)
+			options := DefaultSingleTransferableVoteOptions()
+			options.SurplusRule = test.rule
+			results, err := SingleTransferableVote(candidates, ballots, 2, options)
+			require.NoError(t, err)
+			AssertVoteWinners(t, results, []string{"A", "B"})
+			assert.Equal(t, 4, results.NumberOfBallots)
+			assert.Equal(t, "7", results.WeightOfBallots.RatString())
+			assert.Equal(t, "7/3", results.QuotaValue.RatString())
+
+			for _, result := range results.Rounds[len(results.Rounds)-1].CandidateResults {
+				if result.Candidate == candidates[1] {
+					assert.Equal(t, test.votesB, result.NumberOfVotes.RatString())
+					assert.Equal(t, 2, result.NumberOfBallots)
+				}
+			}
+		})
+	}
+}
+
+func TestMeekWeightedBallots(t *testing.T) {
+	candidates, ballots := weightedElection(t)
+
+	results, err := MeekSingleTransferableVote(candidates, ballots, 2, meekOptions())
+	require.NoError(t, err)
+	AssertVoteWinners(t, results, []string{"A", "B"})
+	assert.Equal(t, "7", results.WeightOfBallots.RatString())
+}
+
+func TestBallotValue(t *testing.T) {
+	ballots := testBallots(t, testCandidates("A"), 3, 0)
+	ballots[1].Weight = big.NewRat(1, 2)
+	ballots[2].Weight = big.NewRat(3, 1)
+
+	assert.Equal(t, "1", ballots[0].value().RatString())
+	assert.Equal(t, "9/2", totalValue(ballots).RatString())
+	assert.True(t, isWeighted(ballots))
+	assert.False(t, isWeighted(ballots[:1]))
+}
@@ -0,0 +1,39 @@
+package voting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithdrawnCandidate(t *testing.T) {
+	// B withdrew after the 4 ballots ranking B then C were cast, they count for
+	// C from the first round and with D's ballots elect C
+	candidates := testCandidates("A", "B", "C", "D")
+	ballots := testBallots(t, candidates, 4, 1, 2)
+	ballots = append(ballots, testBallots(t, candidates, 3, 0)...)
+	ballots = append(ballots, testBallots(t, candidates, 1, 2)...)
+	ballots = append(ballots, testBallots(t, candidates, 2, 3, 2)...)
+
+	options := DefaultSingleTransferableVoteOptions()
+	options.Withdrawn = []*Candidate{candidates[1]}
+	results, err := SingleTransferableVote(candidates, ballots, 1, options)
+	require.NoError(t, err)
+	AssertVoteWinners(t, results, []string{"C"})
+	assert.Equal(t, []*Candidate{candidates[1]}, results.Withdrawn)
+
+	first := results.Rounds[0]
+	require.NotEmpty(t, first.Events)
+	assert.True(t, first.Events[0].Kind == RoundEventWithdrawn, "first event %s, want B withdrawn", first.Events[0])
+	assert.Equal(t, []*Candidate{candidates[1]}, first.Events[0].Candidates)
+	for _, result := range first.CandidateResults {
+		switch result.Candidate {
+		case candidates[1]:
+			assert.Equal(t, Withdrawn, string(result.Status))
+			assert.Equal(t, "0", result.NumberOfVotes.RatString())
+		case candidates[2]:
+			assert.Equal(t, "5", result.NumberOfVotes.RatString())
+		}
+	}
+}